@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fxfn/x/auth"
+	"github.com/fxfn/x/inject"
+	"github.com/fxfn/x/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// Hook is a lifecycle callback run by App.Run
+type Hook func(ctx context.Context) error
+
+type Opts struct {
+	Addr            string        `default:":8080"`
+	ShutdownTimeout time.Duration `default:"10s"`
+	Container       *inject.Container
+	Engine          *gin.Engine
+	Auth            *auth.Auth
+}
+
+// App ties an inject container, a schema router and an auth client together
+type App struct {
+	Container *inject.Container
+	Router    *schema.RouterHelper
+	Auth      *auth.Auth
+
+	addr            string
+	shutdownTimeout time.Duration
+	onStart         []Hook
+	onStop          []Hook
+}
+
+// New creates an App. Missing options fall back to inject.NewContainer(),
+// gin.Default() and auth.Default(). The container is populated with the
+// *App, *schema.RouterHelper and *auth.Auth so factories can resolve them.
+func New(opts Opts) *App {
+	if opts.Addr == "" {
+		opts.Addr = ":8080"
+	}
+
+	if opts.ShutdownTimeout == 0 {
+		opts.ShutdownTimeout = 10 * time.Second
+	}
+
+	if opts.Container == nil {
+		opts.Container = inject.NewContainer()
+	}
+
+	if opts.Engine == nil {
+		opts.Engine = gin.Default()
+	}
+
+	if opts.Auth == nil {
+		opts.Auth = auth.Default()
+	}
+
+	a := &App{
+		Container:       opts.Container,
+		Router:          schema.WrapRouter(opts.Engine),
+		Auth:            opts.Auth,
+		addr:            opts.Addr,
+		shutdownTimeout: opts.ShutdownTimeout,
+	}
+
+	inject.RegisterSingleton[*App](a.Container, a)
+	inject.RegisterSingleton[*schema.RouterHelper](a.Container, a.Router)
+	inject.RegisterSingleton[*auth.Auth](a.Container, a.Auth)
+
+	return a
+}
+
+// OnStart registers a hook that runs before the server starts listening.
+// Hooks run in registration order and an error aborts Run.
+func (a *App) OnStart(hook Hook) {
+	a.onStart = append(a.onStart, hook)
+}
+
+// OnStop registers a hook that runs after the server has shut down.
+// Hooks run in reverse registration order.
+func (a *App) OnStop(hook Hook) {
+	a.onStop = append(a.onStop, hook)
+}
+
+// Transactor returns a schema.Transactor that resolves the schema.Transactor
+// registered in the container on every request, so handlers marked with
+// WithTransaction(a.Transactor()) pick up transient or singleton registrations.
+func (a *App) Transactor() schema.Transactor {
+	return schema.TransactorFunc(func(c *gin.Context) (schema.Transaction, error) {
+		transactor, err := inject.Resolve[schema.Transactor](a.Container)
+		if err != nil {
+			return nil, err
+		}
+		return transactor.Begin(c)
+	})
+}
+
+type IntrospectionSecurityOpts struct {
+	Name         string `default:"BearerAuth"`
+	Description  string
+	ClientID     string
+	ClientSecret string
+}
+
+// IntrospectionSecurity returns a bearer security scheme that validates
+// tokens with the auth server's introspection endpoint. The introspection
+// response is stored on the context under "introspection".
+func (a *App) IntrospectionSecurity(opts IntrospectionSecurityOpts) *schema.BearerSecurity {
+	if opts.Name == "" {
+		opts.Name = "BearerAuth"
+	}
+
+	return schema.NewBearerSecurity(schema.BearerConfig{
+		Name:        opts.Name,
+		Description: opts.Description,
+		ValidateToken: func(c *gin.Context, token string) bool {
+			res, err := a.Auth.Introspect(auth.IntrospectOpts{
+				Token:        token,
+				ClientId:     opts.ClientID,
+				ClientSecret: opts.ClientSecret,
+			})
+			if err != nil || !res.Active {
+				return false
+			}
+
+			c.Set("introspection", res)
+			return true
+		},
+	})
+}
+
+// Run runs the start hooks, serves HTTP until SIGINT/SIGTERM, then shuts the
+// server down gracefully and runs the stop hooks.
+func (a *App) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return a.RunContext(ctx)
+}
+
+// RunContext is like Run but stops when ctx is cancelled
+func (a *App) RunContext(ctx context.Context) error {
+	for _, hook := range a.onStart {
+		if err := hook(ctx); err != nil {
+			return err
+		}
+	}
+
+	server := &http.Server{
+		Addr:    a.addr,
+		Handler: a.Router.Engine,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+		err = server.Shutdown(shutdownCtx)
+		cancel()
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	for i := len(a.onStop) - 1; i >= 0; i-- {
+		if hookErr := a.onStop[i](stopCtx); hookErr != nil && err == nil {
+			err = hookErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fxfn/x/inject"
+	"github.com/fxfn/x/schema"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNew(t *testing.T) {
+	a := New(Opts{})
+
+	if inject.Get[*App](a.Container) != a {
+		t.Errorf("app should be registered in the container")
+	}
+
+	if inject.Get[*schema.RouterHelper](a.Container) != a.Router {
+		t.Errorf("router should be registered in the container")
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	a := New(Opts{Addr: "127.0.0.1:0"})
+
+	var calls []string
+	a.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "start")
+		return nil
+	})
+	a.OnStop(func(ctx context.Context) error {
+		calls = append(calls, "stop 1")
+		return nil
+	})
+	a.OnStop(func(ctx context.Context) error {
+		calls = append(calls, "stop 2")
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := a.RunContext(ctx); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	expected := []string{"start", "stop 2", "stop 1"}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, calls)
+		}
+	}
+}
+
+type testTransaction struct {
+	calls *[]string
+}
+
+func (tx testTransaction) Commit() error {
+	*tx.calls = append(*tx.calls, "commit")
+	return nil
+}
+
+func (tx testTransaction) Rollback() error {
+	*tx.calls = append(*tx.calls, "rollback")
+	return nil
+}
+
+type transactionSchema struct {
+	Query struct {
+		Fail bool `query:"fail"`
+	}
+}
+
+func TestTransactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := New(Opts{Engine: gin.New()})
+
+	var calls []string
+	inject.Register[schema.Transactor](a.Container, func(c *inject.Container) schema.Transactor {
+		return schema.TransactorFunc(func(c *gin.Context) (schema.Transaction, error) {
+			calls = append(calls, "begin")
+			return testTransaction{calls: &calls}, nil
+		})
+	})
+
+	handler := schema.ValidateAndHandle(func(c *gin.Context, req transactionSchema) (*string, error) {
+		if _, ok := schema.GetTransaction[testTransaction](c); !ok {
+			t.Errorf("transaction should be on the context")
+		}
+		if req.Query.Fail {
+			return nil, errors.New("failed")
+		}
+		result := "ok"
+		return &result, nil
+	}).WithTransaction(a.Transactor())
+	a.Router.GET("/tx", handler)
+
+	for _, path := range []string{"/tx", "/tx?fail=true"} {
+		w := httptest.NewRecorder()
+		a.Router.Engine.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	}
+
+	expected := []string{"begin", "commit", "begin", "rollback"}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, calls)
+		}
+	}
+}
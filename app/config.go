@@ -0,0 +1,323 @@
+package app
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fxfn/x/crypt"
+	"github.com/fxfn/x/inject"
+	"github.com/fxfn/x/schema"
+	"gopkg.in/yaml.v3"
+)
+
+type ConfigOpts struct {
+	Path       string          // Config file, YAML when named *.yaml or *.yml, JSON otherwise (optional)
+	Optional   bool            // A missing file is not an error
+	EnvPrefix  string          // Prefix of derived environment variable names, e.g. "APP"
+	Encryption *crypt.FileOpts // Decrypts a file written by crypt.SaveEncryptedConfig
+}
+
+// LoadConfig loads a T in layers, each overriding the one before:
+//
+//  1. `default` tags
+//  2. the file at opts.Path, whose keys are the fields' json names
+//  3. environment variables, named by an `env` tag or, with an EnvPrefix,
+//     derived from the json names: APP_DATABASE_MAX_CONNS for
+//     Database.MaxConns
+//
+// The result is sanitized and validated like a request, by its `mod` and
+// `validate` tags. Defaults and environment variables of time.Duration
+// fields are duration strings such as "10s"; slices are comma separated.
+//
+//	type Config struct {
+//		Addr     string `json:"addr" default:":8080" validate:"required"`
+//		Database struct {
+//			URL      string `json:"url" env:"DATABASE_URL" validate:"required,url"`
+//			MaxConns int    `json:"max_conns" default:"10" validate:"min=1"`
+//		} `json:"database"`
+//	}
+//
+//	config, err := app.LoadConfig[Config](app.ConfigOpts{Path: "config.yaml", EnvPrefix: "APP"})
+func LoadConfig[T any](opts ConfigOpts) (*T, error) {
+	config := new(T)
+	value := reflect.ValueOf(config).Elem()
+
+	if err := applyConfigDefaults(value); err != nil {
+		return nil, err
+	}
+
+	if opts.Path != "" {
+		if err := loadConfigFile(opts, config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyConfigEnv(value, opts.EnvPrefix); err != nil {
+		return nil, err
+	}
+
+	if err := schema.ValidateStruct(config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return config, nil
+}
+
+// RegisterConfig loads a T with LoadConfig and registers it in the
+// container as a *T singleton
+func RegisterConfig[T any](c *inject.Container, opts ConfigOpts) (*T, error) {
+	config, err := LoadConfig[T](opts)
+	if err != nil {
+		return nil, err
+	}
+
+	inject.RegisterSingleton[*T](c, config)
+	return config, nil
+}
+
+// ConfigJSONSchema returns the JSON Schema of a T config file, for editors
+// and CI to check files against
+func ConfigJSONSchema[T any]() *schema.JSONSchemaDocument {
+	return schema.JSONSchemaFor(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// WriteConfigJSONSchema writes the JSON Schema of a T config file to path
+func WriteConfigJSONSchema[T any](path string) error {
+	data, err := json.MarshalIndent(ConfigJSONSchema[T](), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// loadConfigFile overlays the file onto config. The file is read into a
+// generic value first, so YAML keys match the json names like JSON ones do.
+func loadConfigFile(opts ConfigOpts, config any) error {
+	var raw any
+	var err error
+	if opts.Encryption != nil {
+		err = crypt.LoadEncryptedConfig(opts.Path, &raw, *opts.Encryption)
+	} else {
+		err = readConfigFile(opts.Path, &raw)
+	}
+	if errors.Is(err, fs.ErrNotExist) && opts.Optional {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", opts.Path, err)
+	}
+	if raw == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", opts.Path, err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", opts.Path, err)
+	}
+	return nil
+}
+
+func readConfigFile(path string, raw *any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, raw)
+	default:
+		return json.Unmarshal(data, raw)
+	}
+}
+
+// applyConfigDefaults sets fields with a `default` tag
+func applyConfigDefaults(value reflect.Value) error {
+	return walkConfig(value, nil, func(field reflect.Value, structField reflect.StructField, path []string) error {
+		defaultVal, ok := structField.Tag.Lookup("default")
+		if !ok {
+			return nil
+		}
+		if err := setConfigValue(field, defaultVal); err != nil {
+			return fmt.Errorf("invalid default for '%s': %w", strings.Join(path, "."), err)
+		}
+		return nil
+	})
+}
+
+// applyConfigEnv sets fields from the environment variables named by their
+// `env` tag, or derived from prefix and their json names
+func applyConfigEnv(value reflect.Value, prefix string) error {
+	return walkConfig(value, nil, func(field reflect.Value, structField reflect.StructField, path []string) error {
+		name := structField.Tag.Get("env")
+		if name == "" && prefix != "" {
+			name = configEnvName(prefix, path)
+		}
+		if name == "" || name == "-" {
+			return nil
+		}
+
+		envVal, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		if err := setConfigValue(field, envVal); err != nil {
+			return fmt.Errorf("invalid environment variable %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// configEnvName derives an environment variable name from a field's json
+// path: APP and [database maxConns] give APP_DATABASE_MAX_CONNS
+func configEnvName(prefix string, path []string) string {
+	parts := []string{strings.ToUpper(prefix)}
+	for _, name := range path {
+		parts = append(parts, screamingSnake(name))
+	}
+	return strings.Join(parts, "_")
+}
+
+func screamingSnake(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if r == '-' || r == '.' || r == ' ' {
+			r = '_'
+		}
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// walkConfig calls fn for every exported leaf field of a config struct with
+// the json names leading to it. Nested structs are walked, except ones that
+// unmarshal themselves from text such as time.Time.
+func walkConfig(value reflect.Value, path []string, fn func(field reflect.Value, structField reflect.StructField, path []string) error) error {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name := configFieldName(structField)
+		if name == "-" {
+			continue
+		}
+		fieldPath := append(append([]string(nil), path...), name)
+
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct && !isTextUnmarshaler(field) {
+			if err := walkConfig(field, fieldPath, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(field, structField, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configFieldName returns the json name of a field, the key it has in the file
+func configFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+func isTextUnmarshaler(value reflect.Value) bool {
+	_, ok := value.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// setConfigValue parses a default or environment variable into field
+func setConfigValue(field reflect.Value, value string) error {
+	if field.Kind() != reflect.Ptr && field.CanAddr() {
+		if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(value))
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(duration))
+			return nil
+		}
+		intVal, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(intVal)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintVal, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(uintVal)
+	case reflect.Float32, reflect.Float64:
+		floatVal, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(floatVal)
+	case reflect.Bool:
+		boolVal, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolVal)
+	case reflect.Ptr:
+		target := reflect.New(field.Type().Elem())
+		if err := setConfigValue(target.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(target)
+	case reflect.Slice:
+		var parts []string
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setConfigValue(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type: %s", field.Kind())
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fxfn/x/crypt"
+	"github.com/fxfn/x/inject"
+)
+
+type testConfig struct {
+	Addr     string        `json:"addr" default:":8080" validate:"required"`
+	Timeout  time.Duration `json:"timeout" default:"5s"`
+	Tags     []string      `json:"tags"`
+	Database struct {
+		URL      string `json:"url" env:"TEST_DATABASE_URL" validate:"required"`
+		MaxConns int    `json:"maxConns" default:"10" validate:"min=1"`
+	} `json:"database"`
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("layers defaults, file and environment", func(t *testing.T) {
+		path := writeConfigFile(t, "config.yaml", "addr: \":9000\"\ndatabase:\n  url: postgres://file\n  maxConns: 20\n")
+		t.Setenv("APP_DATABASE_MAX_CONNS", "30")
+		t.Setenv("APP_TAGS", "a, b")
+
+		config, err := LoadConfig[testConfig](ConfigOpts{Path: path, EnvPrefix: "APP"})
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+
+		if config.Addr != ":9000" || config.Timeout != 5*time.Second {
+			t.Errorf("unexpected addr %q or timeout %s", config.Addr, config.Timeout)
+		}
+		if config.Database.URL != "postgres://file" || config.Database.MaxConns != 30 {
+			t.Errorf("unexpected database %+v", config.Database)
+		}
+		if strings.Join(config.Tags, ",") != "a,b" {
+			t.Errorf("unexpected tags %v", config.Tags)
+		}
+	})
+
+	t.Run("env tag without prefix", func(t *testing.T) {
+		t.Setenv("TEST_DATABASE_URL", "postgres://env")
+
+		config, err := LoadConfig[testConfig](ConfigOpts{Path: filepath.Join(t.TempDir(), "missing.json"), Optional: true})
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if config.Database.URL != "postgres://env" || config.Database.MaxConns != 10 {
+			t.Errorf("unexpected database %+v", config.Database)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := LoadConfig[testConfig](ConfigOpts{Path: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+			t.Error("expected an error for a missing file")
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		path := writeConfigFile(t, "config.json", `{"database": {"url": "postgres://file", "maxConns": 0}}`)
+
+		_, err := LoadConfig[testConfig](ConfigOpts{Path: path})
+		if err == nil || !strings.Contains(err.Error(), "config validation failed") {
+			t.Errorf("expected a validation error, got %v", err)
+		}
+	})
+
+	t.Run("invalid environment variable", func(t *testing.T) {
+		t.Setenv("TEST_DATABASE_URL", "postgres://env")
+		t.Setenv("APP_TIMEOUT", "soon")
+
+		_, err := LoadConfig[testConfig](ConfigOpts{EnvPrefix: "APP"})
+		if err == nil || !strings.Contains(err.Error(), "APP_TIMEOUT") {
+			t.Errorf("expected an error naming APP_TIMEOUT, got %v", err)
+		}
+	})
+
+	t.Run("encrypted file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml.enc")
+		opts := crypt.FileOpts{Passphrase: "secret"}
+		plain := map[string]any{"database": map[string]any{"url": "postgres://secret"}}
+		if err := crypt.SaveEncryptedConfig(path, plain, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		config, err := LoadConfig[testConfig](ConfigOpts{Path: path, Encryption: &opts})
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		if config.Database.URL != "postgres://secret" {
+			t.Errorf("unexpected database url %q", config.Database.URL)
+		}
+	})
+}
+
+func TestRegisterConfig(t *testing.T) {
+	t.Setenv("TEST_DATABASE_URL", "postgres://env")
+	a := New(Opts{})
+
+	config, err := RegisterConfig[testConfig](a.Container, ConfigOpts{})
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	if inject.Get[*testConfig](a.Container) != config {
+		t.Errorf("config should be registered in the container")
+	}
+}
+
+func TestConfigJSONSchema(t *testing.T) {
+	data, err := json.Marshal(ConfigJSONSchema[testConfig]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"addr", "timeout", "tags", "database"} {
+		if _, ok := doc.Properties[name]; !ok {
+			t.Errorf("expected property %q in %s", name, data)
+		}
+	}
+	if strings.Join(doc.Required, ",") != "addr" {
+		t.Errorf("expected addr to be required, got %v", doc.Required)
+	}
+}
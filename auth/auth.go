@@ -1,13 +1,5 @@
 package auth
 
-import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"strings"
-)
-
 func Default() *Auth {
 	return &Auth{}
 }
@@ -57,9 +49,7 @@ func (a *Auth) SetEndpoint(opts *SetEndpointOpts) {
 }
 
 func Discovery(endpoint string) (*Auth, error) {
-	if !strings.HasSuffix(endpoint, ".well-known/openid-configuration") {
-		endpoint = fmt.Sprintf("%s/.well-known/openid-configuration", endpoint)
-	}
+	endpoint = discoveryEndpoint(endpoint)
 
 	serverMetadata, err := fetchServerMetadata(endpoint)
 	if err != nil {
@@ -73,25 +63,19 @@ func Discovery(endpoint string) (*Auth, error) {
 }
 
 func fetchServerMetadata(endpoint string) (*Server, error) {
+	return Default().fetchServerMetadata(endpoint)
+}
 
-	res, err := http.Get(endpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	defer res.Body.Close()
-
-	body, err := io.ReadAll(res.Body)
+func (a *Auth) fetchServerMetadata(endpoint string) (*Server, error) {
+	res, err := a.get(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	// unmarshal json
-	var serverMetadata Server
-	err = json.Unmarshal(body, &serverMetadata)
+	body, err := readBody(res)
 	if err != nil {
 		return nil, err
 	}
 
-	return &serverMetadata, nil
+	return parseServerMetadata(body)
 }
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidLogoutToken = errors.New("invalid logout token")
+
+// backChannelLogoutEvent is the event of logout tokens
+const backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+
+// LogoutEvent is a verified back-channel logout of the authorization server.
+// It names the user, the session, or both.
+type LogoutEvent struct {
+	Issuer    string
+	Subject   string // sub claim, empty when only the session is named
+	SessionID string // sid claim, empty when only the user is named
+	TokenID   string // jti claim
+	IssuedAt  time.Time
+}
+
+// LogoutFunc ends the sessions a logout event names, e.g. by deleting them
+// from the session store
+type LogoutFunc func(ctx context.Context, event LogoutEvent) error
+
+type BackChannelLogoutOpts struct {
+	ClientID string // Audience logout tokens must be issued for
+
+	// MaxAge is how old a logout token may be. Token IDs are remembered this
+	// long to reject replays.
+	MaxAge time.Duration `default:"5m"`
+}
+
+// BackChannelLogout receives OpenID Connect back-channel logout requests
+// (OpenID Connect Back-Channel Logout 1.0) the authorization server sends
+// when a user logs out elsewhere. Logout tokens are verified with the
+// server's jwks_uri before the registered LogoutFuncs run.
+type BackChannelLogout struct {
+	auth *Auth
+	opts BackChannelLogoutOpts
+
+	mu        sync.Mutex
+	callbacks []LogoutFunc
+	seen      map[string]time.Time // When remembering each token ID can stop
+}
+
+// BackChannelLogout creates a handler for the back-channel logout URI
+// registered for the client:
+//
+//	logout := client.BackChannelLogout(auth.BackChannelLogoutOpts{ClientID: "web-app"})
+//	logout.OnLogout(func(ctx context.Context, event auth.LogoutEvent) error {
+//		return store.DeleteSessions(ctx, event.Subject, event.SessionID)
+//	})
+//	mux.Handle("POST /backchannel-logout", logout)
+func (a *Auth) BackChannelLogout(opts BackChannelLogoutOpts) *BackChannelLogout {
+	if opts.MaxAge == 0 {
+		opts.MaxAge = 5 * time.Minute
+	}
+
+	return &BackChannelLogout{
+		auth: a,
+		opts: opts,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// OnLogout registers a callback run for every verified logout, in order of
+// registration
+func (b *BackChannelLogout) OnLogout(callback LogoutFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.callbacks = append(b.callbacks, callback)
+}
+
+// logoutClaims are the claims of a logout token
+type logoutClaims struct {
+	Issuer    string                     `json:"iss"`
+	Audience  Audience                   `json:"aud"`
+	IssuedAt  int64                      `json:"iat"`
+	ExpiresAt int64                      `json:"exp"`
+	TokenID   string                     `json:"jti"`
+	Subject   string                     `json:"sub"`
+	SessionID string                     `json:"sid"`
+	Events    map[string]json.RawMessage `json:"events"`
+	Nonce     *string                    `json:"nonce"`
+}
+
+// Verify checks a logout token and returns the logout it describes. Errors
+// wrap ErrInvalidLogoutToken.
+func (b *BackChannelLogout) Verify(logoutToken string) (*LogoutEvent, error) {
+	payload, header, err := b.auth.verifyJWT(logoutToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLogoutToken, err)
+	}
+	if header.Typ != "" && !strings.EqualFold(header.Typ, "logout+jwt") && !strings.EqualFold(header.Typ, "JWT") {
+		return nil, fmt.Errorf("%w: unexpected typ %q", ErrInvalidLogoutToken, header.Typ)
+	}
+
+	var claims logoutClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid claims", ErrInvalidLogoutToken)
+	}
+
+	if claims.Issuer != b.auth.server.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrInvalidLogoutToken, claims.Issuer, b.auth.server.Issuer)
+	}
+	if !claims.Audience.Contains(b.opts.ClientID) {
+		return nil, fmt.Errorf("%w: not issued for %q", ErrInvalidLogoutToken, b.opts.ClientID)
+	}
+	if _, exists := claims.Events[backChannelLogoutEvent]; !exists {
+		return nil, fmt.Errorf("%w: missing the back-channel logout event", ErrInvalidLogoutToken)
+	}
+	if claims.Subject == "" && claims.SessionID == "" {
+		return nil, fmt.Errorf("%w: names neither a subject nor a session", ErrInvalidLogoutToken)
+	}
+	if claims.Nonce != nil {
+		return nil, fmt.Errorf("%w: logout tokens must not carry a nonce", ErrInvalidLogoutToken)
+	}
+
+	now := time.Now()
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	if claims.IssuedAt == 0 || issuedAt.Before(now.Add(-b.opts.MaxAge)) || issuedAt.After(now.Add(b.opts.MaxAge)) {
+		return nil, fmt.Errorf("%w: iat is outside the accepted window", ErrInvalidLogoutToken)
+	}
+	if claims.ExpiresAt != 0 && now.After(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, fmt.Errorf("%w: token has expired", ErrInvalidLogoutToken)
+	}
+	if claims.TokenID == "" || !b.remember(claims.TokenID, issuedAt.Add(b.opts.MaxAge), now) {
+		return nil, fmt.Errorf("%w: token was used before", ErrInvalidLogoutToken)
+	}
+
+	return &LogoutEvent{
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		SessionID: claims.SessionID,
+		TokenID:   claims.TokenID,
+		IssuedAt:  issuedAt,
+	}, nil
+}
+
+// remember records a token ID until expires, reporting false when it was
+// recorded already
+func (b *BackChannelLogout) remember(jti string, expires, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, until := range b.seen {
+		if now.After(until) {
+			delete(b.seen, id)
+		}
+	}
+
+	if _, exists := b.seen[jti]; exists {
+		return false
+	}
+	b.seen[jti] = expires
+	return true
+}
+
+// Logout runs the registered callbacks for an event, stopping at the first
+// error
+func (b *BackChannelLogout) Logout(ctx context.Context, event LogoutEvent) error {
+	b.mu.Lock()
+	callbacks := append([]LogoutFunc(nil), b.callbacks...)
+	b.mu.Unlock()
+
+	for _, callback := range callbacks {
+		if err := callback(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP handles a back-channel logout request: a form POST carrying the
+// logout_token parameter. It answers 200 once the callbacks succeeded, and
+// 400 with an OAuth error when the token is invalid or a callback failed, as
+// the specification requires.
+func (b *BackChannelLogout) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	logoutToken := r.PostFormValue("logout_token")
+	if logoutToken == "" {
+		logoutError(w, "invalid_request", "logout_token is required")
+		return
+	}
+
+	event, err := b.Verify(logoutToken)
+	if err != nil {
+		logoutError(w, "invalid_request", err.Error())
+		return
+	}
+
+	if err := b.Logout(r.Context(), *event); err != nil {
+		// Let the server retry with the same token
+		b.mu.Lock()
+		delete(b.seen, event.TokenID)
+		b.mu.Unlock()
+
+		logoutError(w, "logout_failed", "sessions could not be ended")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func logoutError(w http.ResponseWriter, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: code, ErrorDescription: description})
+}
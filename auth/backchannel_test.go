@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fxfn/x/crypt"
+)
+
+func TestBackChannelLogout(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	jwksRequests := 0
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwksRequests++
+		fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":"key-1","use":"sig","n":%q,"e":%q}]}`,
+			b64.EncodeToString(key.N.Bytes()),
+			b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		)
+	}))
+	defer jwksServer.Close()
+
+	client := Default()
+	client.SetServer(&Server{Issuer: "https://auth.example.com", JwksUri: jwksServer.URL})
+
+	claims := func() map[string]any {
+		return map[string]any{
+			"iss":    "https://auth.example.com",
+			"aud":    "web-app",
+			"iat":    time.Now().Unix(),
+			"jti":    fmt.Sprintf("logout-%d", time.Now().UnixNano()),
+			"sub":    "alice",
+			"sid":    "session-1",
+			"events": map[string]any{backChannelLogoutEvent: map[string]any{}},
+		}
+	}
+
+	sign := func(claims map[string]any, key *rsa.PrivateKey) string {
+		payload, _ := json.Marshal(claims)
+		token, err := crypt.SignJWS(payload, key, crypt.SignJWSOpts{Algorithm: crypt.RS256, KeyID: "key-1", Type: "logout+jwt"})
+		if err != nil {
+			t.Fatalf("failed to sign logout token: %v", err)
+		}
+		return token
+	}
+
+	var events []LogoutEvent
+	logout := client.BackChannelLogout(BackChannelLogoutOpts{ClientID: "web-app"})
+	logout.OnLogout(func(ctx context.Context, event LogoutEvent) error {
+		if event.Subject == "fail" {
+			return errors.New("session store unavailable")
+		}
+		events = append(events, event)
+		return nil
+	})
+
+	post := func(token string) (int, ErrorResponse) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/backchannel-logout", strings.NewReader(url.Values{"logout_token": {token}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		logout.ServeHTTP(w, r)
+
+		var errorResponse ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &errorResponse)
+		return w.Code, errorResponse
+	}
+
+	t.Run("should run the callbacks for a valid logout token", func(t *testing.T) {
+		if status, res := post(sign(claims(), key)); status != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %+v", status, res)
+		}
+
+		if len(events) != 1 || events[0].Subject != "alice" || events[0].SessionID != "session-1" {
+			t.Errorf("unexpected events %+v", events)
+		}
+	})
+
+	t.Run("should reject replayed logout tokens", func(t *testing.T) {
+		token := sign(claims(), key)
+		post(token)
+
+		if status, res := post(token); status != http.StatusBadRequest || !strings.Contains(res.ErrorDescription, "used before") {
+			t.Errorf("expected the replay to be rejected, got %d: %+v", status, res)
+		}
+	})
+
+	t.Run("should reject invalid logout tokens", func(t *testing.T) {
+		other, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+		tests := map[string]func(claims map[string]any) string{
+			"other key":      func(c map[string]any) string { return sign(c, other) },
+			"other audience": func(c map[string]any) string { c["aud"] = "other-app"; return sign(c, key) },
+			"other issuer":   func(c map[string]any) string { c["iss"] = "https://evil.example.com"; return sign(c, key) },
+			"missing event":  func(c map[string]any) string { delete(c, "events"); return sign(c, key) },
+			"nonce":          func(c map[string]any) string { c["nonce"] = "n"; return sign(c, key) },
+			"no subject":     func(c map[string]any) string { delete(c, "sub"); delete(c, "sid"); return sign(c, key) },
+			"old":            func(c map[string]any) string { c["iat"] = time.Now().Add(-time.Hour).Unix(); return sign(c, key) },
+		}
+
+		for name, token := range tests {
+			if status, _ := post(token(claims())); status != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", name, status)
+			}
+		}
+
+		if _, err := logout.Verify("not-a-token"); !errors.Is(err, ErrInvalidLogoutToken) {
+			t.Errorf("expected ErrInvalidLogoutToken, got %v", err)
+		}
+	})
+
+	t.Run("should allow a retry after a callback failed", func(t *testing.T) {
+		c := claims()
+		c["sub"] = "fail"
+		token := sign(c, key)
+
+		for range 2 {
+			if status, res := post(token); status != http.StatusBadRequest || res.Error != "logout_failed" {
+				t.Errorf("expected logout_failed, got %d: %+v", status, res)
+			}
+		}
+	})
+
+	if jwksRequests != 1 {
+		t.Errorf("expected the key set to be fetched once, got %d", jwksRequests)
+	}
+}
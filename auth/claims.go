@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Audience is the aud claim, which servers send as a single string or an
+// array of strings
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// MarshalJSON writes a single audience as a string, like most servers do
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// Contains reports whether audience is one of the audiences
+func (a Audience) Contains(audience string) bool {
+	return slices.Contains(a, audience)
+}
+
+// Claims holds claims a response has beyond the ones it declares
+type Claims map[string]any
+
+// String returns a string claim
+func (c Claims) String(name string) (string, bool) {
+	value, ok := c[name].(string)
+	return value, ok
+}
+
+// Strings returns a claim holding an array of strings, or a single string
+func (c Claims) Strings(name string) ([]string, bool) {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}, true
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, s)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// Bool returns a boolean claim
+func (c Claims) Bool(name string) (bool, bool) {
+	value, ok := c[name].(bool)
+	return value, ok
+}
+
+// Int64 returns a numeric claim, such as a timestamp, without its fraction
+func (c Claims) Int64(name string) (int64, bool) {
+	value, ok := c[name].(float64)
+	return int64(value), ok
+}
+
+// Decode decodes a claim into v, e.g. a struct for an object claim. v is
+// left unchanged when the claim is missing.
+func (c Claims) Decode(name string, v any) error {
+	data, err := json.Marshal(c[name])
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
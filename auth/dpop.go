@@ -0,0 +1,525 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fxfn/x/crypt"
+)
+
+var ErrInvalidDPoPProof = errors.New("invalid DPoP proof")
+
+// dpopType is the typ header of DPoP proofs
+const dpopType = "dpop+jwt"
+
+var b64 = base64.RawURLEncoding
+
+// DPoPKey is a client's key pair for DPoP (RFC 9449). Access tokens requested
+// with it are bound to the key, so they are useless to anyone who steals them
+// without it. Keys are ECDSA P-256 and sign with ES256.
+type DPoPKey struct {
+	private    *ecdsa.PrivateKey
+	jwk        json.RawMessage
+	thumbprint string
+
+	mu     sync.Mutex
+	nonces map[string]string // Latest DPoP-Nonce by server origin
+}
+
+// NewDPoPKey generates a DPoP key pair. Tokens bound to it stop working when
+// it is lost, so long-running clients generate one per process or keep one
+// with DPoPKeyFromECDSA.
+func NewDPoPKey() (*DPoPKey, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return DPoPKeyFromECDSA(private)
+}
+
+// DPoPKeyFromECDSA creates a DPoP key from a P-256 private key
+func DPoPKeyFromECDSA(private *ecdsa.PrivateKey) (*DPoPKey, error) {
+	if private.Curve != elliptic.P256() {
+		return nil, &InvalidRequest{message: "DPoP keys must be on the P-256 curve"}
+	}
+
+	public, err := private.PublicKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	// Uncompressed point: 0x04 || X || Y
+	point := public.Bytes()
+	jwk := ecJWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   b64.EncodeToString(point[1:33]),
+		Y:   b64.EncodeToString(point[33:]),
+	}
+	data, err := json.Marshal(jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DPoPKey{
+		private:    private,
+		jwk:        data,
+		thumbprint: jwk.thumbprint(),
+		nonces:     make(map[string]string),
+	}, nil
+}
+
+// Thumbprint returns the JWK thumbprint (RFC 7638) of the public key, which
+// servers put in the cnf.jkt claim of tokens bound to it
+func (k *DPoPKey) Thumbprint() string {
+	return k.thumbprint
+}
+
+// Proof returns a DPoP proof for a request. accessToken is the token sent
+// with the request, or empty for requests to the token endpoint.
+func (k *DPoPKey) Proof(method, target, accessToken string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	claims := dpopClaims{
+		JTI:   hex.EncodeToString(jti),
+		HTM:   method,
+		HTU:   htu(u),
+		IAT:   time.Now().Unix(),
+		Nonce: k.nonce(u),
+	}
+	if accessToken != "" {
+		claims.ATH = accessTokenHash(accessToken)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	return crypt.SignJWS(payload, k.private, crypt.SignJWSOpts{
+		Algorithm: crypt.ES256,
+		Type:      dpopType,
+		JWK:       k.jwk,
+	})
+}
+
+// SignRequest sets the DPoP authorization header and proof of a request to
+// a resource server
+func (k *DPoPKey) SignRequest(req *http.Request, accessToken string) error {
+	proof, err := k.Proof(req.Method, req.URL.String(), accessToken)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "DPoP "+accessToken)
+	req.Header.Set("DPoP", proof)
+	return nil
+}
+
+// rememberNonce keeps the DPoP-Nonce a server sent for the next proofs to it
+func (k *DPoPKey) rememberNonce(res *http.Response) {
+	nonce := res.Header.Get("DPoP-Nonce")
+	if nonce == "" || res.Request == nil {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.nonces[origin(res.Request.URL)] = nonce
+}
+
+func (k *DPoPKey) nonce(u *url.URL) string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.nonces[origin(u)]
+}
+
+// SetDPoP makes grants request tokens bound to key
+func (a *Auth) SetDPoP(key *DPoPKey) {
+	a.dpop = key
+}
+
+// WithDPoP makes grants of an Auth created by FromIssuer request tokens bound
+// to key
+func WithDPoP(key *DPoPKey) Option {
+	return func(o *options) {
+		o.dpop = key
+	}
+}
+
+// DPoPTransport is an http.RoundTripper sending requests with a DPoP-bound
+// token from Source and a proof signed by Key. Requests the resource server
+// answers with a use_dpop_nonce error are retried once with its nonce.
+type DPoPTransport struct {
+	Key    *DPoPKey
+	Source *TokenSource
+	Base   http.RoundTripper // http.DefaultTransport when nil
+}
+
+func (t *DPoPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	send := func(body io.ReadCloser) (*http.Response, error) {
+		signed := req.Clone(req.Context())
+		signed.Body = body
+		if err := t.Key.SignRequest(signed, token.AccessToken); err != nil {
+			return nil, err
+		}
+
+		res, err := base.RoundTrip(signed)
+		if err != nil {
+			return nil, err
+		}
+		t.Key.rememberNonce(res)
+		return res, nil
+	}
+
+	res, err := send(req.Body)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || !strings.Contains(res.Header.Get("WWW-Authenticate"), "use_dpop_nonce") {
+		return res, err
+	}
+
+	// The body was sent already and can only be sent again with GetBody
+	var body io.ReadCloser = http.NoBody
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return res, nil
+		}
+		if body, err = req.GetBody(); err != nil {
+			return res, nil
+		}
+	}
+
+	res.Body.Close()
+	return send(body)
+}
+
+// dpopNonceRequired reports whether the token endpoint rejected a proof for
+// missing its nonce, leaving the body of the response readable
+func dpopNonceRequired(res *http.Response) bool {
+	if res.StatusCode != http.StatusBadRequest || res.Header.Get("DPoP-Nonce") == "" {
+		return false
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	var errorResponse ErrorResponse
+	return json.Unmarshal(body, &errorResponse) == nil && errorResponse.Error == "use_dpop_nonce"
+}
+
+type DPoPVerifierOpts struct {
+	// MaxAge is how old a proof may be, and how far in the future its iat may
+	// be for clients with skewed clocks
+	MaxAge time.Duration `default:"1m"`
+
+	// URL is the public URL of the server, e.g. "https://api.example.com",
+	// for checking the htu claim behind a proxy. Defaults to the scheme and
+	// host of the request.
+	URL string
+}
+
+// DPoPVerifier verifies the DPoP proofs resource servers receive, rejecting
+// proofs used before
+type DPoPVerifier struct {
+	opts DPoPVerifierOpts
+
+	mu        sync.Mutex
+	seen      map[string]time.Time // When remembering each proof ID can stop
+	lastPrune time.Time
+}
+
+// DPoPProof is a verified DPoP proof
+type DPoPProof struct {
+	ID         string
+	IssuedAt   time.Time
+	Thumbprint string // JWK thumbprint of the client's key, to match cnf.jkt
+}
+
+func NewDPoPVerifier(opts DPoPVerifierOpts) *DPoPVerifier {
+	if opts.MaxAge == 0 {
+		opts.MaxAge = time.Minute
+	}
+
+	opts.URL = strings.TrimSuffix(opts.URL, "/")
+
+	return &DPoPVerifier{opts: opts, seen: make(map[string]time.Time)}
+}
+
+// Verify checks the DPoP proof of a request for accessToken. Errors wrap
+// ErrInvalidDPoPProof.
+func (v *DPoPVerifier) Verify(r *http.Request, accessToken string) (*DPoPProof, error) {
+	proofs := r.Header.Values("DPoP")
+	if len(proofs) != 1 {
+		return nil, fmt.Errorf("%w: expected one DPoP header, got %d", ErrInvalidDPoPProof, len(proofs))
+	}
+
+	header, err := crypt.ParseJWSHeader(proofs[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+	if header.Typ != dpopType || header.Alg != crypt.ES256 {
+		return nil, fmt.Errorf("%w: expected typ %s and alg ES256", ErrInvalidDPoPProof, dpopType)
+	}
+
+	var jwk ecJWK
+	if err := json.Unmarshal(header.JWK, &jwk); err != nil {
+		return nil, fmt.Errorf("%w: invalid jwk", ErrInvalidDPoPProof)
+	}
+	public, err := jwk.publicKey()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+
+	payload, _, err := crypt.VerifyJWS(proofs[0], public)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDPoPProof, err)
+	}
+
+	var claims dpopClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid claims", ErrInvalidDPoPProof)
+	}
+
+	if claims.HTM != r.Method {
+		return nil, fmt.Errorf("%w: htm %q does not match %s", ErrInvalidDPoPProof, claims.HTM, r.Method)
+	}
+	if expected := v.requestURL(r); claims.HTU != expected {
+		return nil, fmt.Errorf("%w: htu %q does not match %s", ErrInvalidDPoPProof, claims.HTU, expected)
+	}
+	if claims.ATH != accessTokenHash(accessToken) {
+		return nil, fmt.Errorf("%w: ath does not match the access token", ErrInvalidDPoPProof)
+	}
+
+	now := time.Now()
+	issuedAt := time.Unix(claims.IAT, 0)
+	if issuedAt.Before(now.Add(-v.opts.MaxAge)) || issuedAt.After(now.Add(v.opts.MaxAge)) {
+		return nil, fmt.Errorf("%w: iat is outside the accepted window", ErrInvalidDPoPProof)
+	}
+	if claims.JTI == "" || !v.remember(claims.JTI, issuedAt.Add(v.opts.MaxAge), now) {
+		return nil, fmt.Errorf("%w: proof was used before", ErrInvalidDPoPProof)
+	}
+
+	return &DPoPProof{
+		ID:         claims.JTI,
+		IssuedAt:   issuedAt,
+		Thumbprint: jwk.thumbprint(),
+	}, nil
+}
+
+// remember records a proof ID until expires, reporting false when it was
+// recorded already
+func (v *DPoPVerifier) remember(jti string, expires, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if now.Sub(v.lastPrune) > v.opts.MaxAge {
+		for id, until := range v.seen {
+			if now.After(until) {
+				delete(v.seen, id)
+			}
+		}
+		v.lastPrune = now
+	}
+
+	if until, exists := v.seen[jti]; exists && !now.After(until) {
+		return false
+	}
+	v.seen[jti] = expires
+	return true
+}
+
+func (v *DPoPVerifier) requestURL(r *http.Request) string {
+	if v.opts.URL != "" {
+		return v.opts.URL + (&url.URL{Path: r.URL.Path}).String()
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return htu(&url.URL{Scheme: scheme, Host: r.Host, Path: r.URL.Path})
+}
+
+type DPoPMiddlewareOpts struct {
+	// Credentials for introspecting access tokens
+	ClientID     string
+	ClientSecret string
+
+	Verifier *DPoPVerifier // Defaults to a verifier with default options
+
+	// AllowBearer also accepts Bearer tokens for clients without DPoP. Tokens
+	// bound to a key are rejected as Bearer tokens either way.
+	AllowBearer bool
+}
+
+// DPoPMiddleware only lets requests with an active access token bound to the
+// key of their DPoP proof through, storing the introspected token in their
+// context, see IntrospectionFromContext
+func (a *Auth) DPoPMiddleware(opts DPoPMiddlewareOpts) func(http.Handler) http.Handler {
+	verifier := opts.Verifier
+	if verifier == nil {
+		verifier = NewDPoPVerifier(DPoPVerifierOpts{})
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			scheme = strings.ToLower(scheme)
+			if token == "" || !(scheme == "dpop" || scheme == "bearer" && opts.AllowBearer) {
+				dpopUnauthorized(w, "", "missing access token")
+				return
+			}
+
+			var proof *DPoPProof
+			if scheme == "dpop" {
+				var err error
+				if proof, err = verifier.Verify(r, token); err != nil {
+					dpopUnauthorized(w, "invalid_dpop_proof", err.Error())
+					return
+				}
+			}
+
+			introspection, err := a.Introspect(IntrospectOpts{
+				Token:        token,
+				ClientId:     opts.ClientID,
+				ClientSecret: opts.ClientSecret,
+			})
+			if err != nil {
+				http.Error(w, "failed to introspect access token", http.StatusServiceUnavailable)
+				return
+			}
+			if !introspection.Active {
+				dpopUnauthorized(w, "invalid_token", "access token is not active")
+				return
+			}
+
+			var jkt string
+			if introspection.Confirmation != nil {
+				jkt = introspection.Confirmation.JKT
+			}
+			if proof == nil && jkt != "" {
+				dpopUnauthorized(w, "invalid_token", "access token is bound to a DPoP key")
+				return
+			}
+			if proof != nil && jkt != proof.Thumbprint {
+				dpopUnauthorized(w, "invalid_token", "access token is not bound to the DPoP proof key")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithIntrospection(r.Context(), introspection)))
+		})
+	}
+}
+
+func dpopUnauthorized(w http.ResponseWriter, code, description string) {
+	challenge := `DPoP algs="ES256"`
+	if code != "" {
+		challenge = fmt.Sprintf(`DPoP error=%q, error_description=%q, algs="ES256"`, code, description)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, description, http.StatusUnauthorized)
+}
+
+// dpopClaims are the claims of a DPoP proof
+type dpopClaims struct {
+	JTI   string `json:"jti"`
+	HTM   string `json:"htm"`
+	HTU   string `json:"htu"`
+	IAT   int64  `json:"iat"`
+	ATH   string `json:"ath,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// ecJWK is a P-256 public key as a JWK
+type ecJWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	D   string `json:"d,omitempty"` // Private keys must never be sent
+}
+
+// thumbprint hashes the required members in lexicographic order (RFC 7638)
+func (j ecJWK) thumbprint() string {
+	canonical := fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, j.Crv, j.Kty, j.X, j.Y)
+	sum := sha256.Sum256([]byte(canonical))
+	return b64.EncodeToString(sum[:])
+}
+
+func (j ecJWK) publicKey() (*ecdsa.PublicKey, error) {
+	if j.Kty != "EC" || j.Crv != "P-256" || j.D != "" {
+		return nil, errors.New("jwk must be a P-256 public key")
+	}
+
+	x, errX := b64.DecodeString(j.X)
+	y, errY := b64.DecodeString(j.Y)
+	if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid jwk coordinates")
+	}
+
+	// Checks that the point is on the curve
+	if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+		return nil, errors.New("invalid jwk point")
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+// accessTokenHash is the ath claim binding a proof to an access token
+func accessTokenHash(accessToken string) string {
+	if accessToken == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(accessToken))
+	return b64.EncodeToString(sum[:])
+}
+
+// htu is a URL without its query and fragment, as DPoP proofs name it
+func htu(u *url.URL) string {
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+}
+
+func origin(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
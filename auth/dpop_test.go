@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fxfn/x/crypt"
+)
+
+func TestDPoP(t *testing.T) {
+	key, err := NewDPoPKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	var tokenRequests int
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			header, err := crypt.ParseJWSHeader(r.Header.Get("DPoP"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_dpop_proof"}`))
+				return
+			}
+
+			var claims dpopClaims
+			payload, _ := b64.DecodeString(strings.Split(r.Header.Get("DPoP"), ".")[1])
+			json.Unmarshal(payload, &claims)
+			if claims.Nonce != "server-nonce" {
+				w.Header().Set("DPoP-Nonce", "server-nonce")
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"use_dpop_nonce"}`))
+				return
+			}
+
+			var jwk ecJWK
+			json.Unmarshal(header.JWK, &jwk)
+			fmt.Fprintf(w, `{"access_token":%q,"token_type":"DPoP","expires_in":3600}`, jwk.thumbprint())
+		case "/introspect":
+			r.ParseForm()
+			switch token := r.Form.Get("token"); token {
+			case "plain":
+				w.Write([]byte(`{"active":true,"sub":"plain"}`))
+			default:
+				// Bound tokens are the thumbprint of their key
+				fmt.Fprintf(w, `{"active":true,"sub":"bound","cnf":{"jkt":%q}}`, token)
+			}
+		}
+	}))
+	defer authServer.Close()
+
+	auth := Default()
+	auth.SetServer(&Server{
+		TokenEndpoint:         authServer.URL + "/token",
+		IntrospectionEndpoint: authServer.URL + "/introspect",
+	})
+
+	resourceServer := httptest.NewServer(auth.DPoPMiddleware(DPoPMiddlewareOpts{AllowBearer: true})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			introspection, _ := IntrospectionFromContext(r.Context())
+			w.Write([]byte(introspection.Subject))
+		}),
+	))
+	defer resourceServer.Close()
+
+	send := func(req *http.Request) (int, string) {
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		res.Body.Close()
+		return res.StatusCode, res.Header.Get("WWW-Authenticate")
+	}
+
+	t.Run("should request bound tokens and send proofs to resource servers", func(t *testing.T) {
+		client := Default()
+		client.SetServer(&Server{TokenEndpoint: authServer.URL + "/token"})
+		client.SetDPoP(key)
+
+		httpClient := &http.Client{Transport: &DPoPTransport{
+			Key:    key,
+			Source: client.ClientCredentialsTokenSource(GrantClientCredentialsOpts{ClientID: "client"}, TokenSourceOpts{}),
+		}}
+
+		res, err := httpClient.Get(resourceServer.URL + "/orders?page=2")
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", res.StatusCode, res.Header.Get("WWW-Authenticate"))
+		}
+		if tokenRequests != 2 {
+			t.Errorf("expected the token request to be retried with the nonce, got %d requests", tokenRequests)
+		}
+	})
+
+	t.Run("should reject replayed proofs", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", resourceServer.URL+"/orders", nil)
+		key.SignRequest(req, key.Thumbprint())
+
+		if status, _ := send(req); status != http.StatusOK {
+			t.Fatalf("expected 200, got %d", status)
+		}
+		if status, challenge := send(req); status != http.StatusUnauthorized || !strings.Contains(challenge, "invalid_dpop_proof") {
+			t.Errorf("expected the replayed proof to be rejected, got %d %s", status, challenge)
+		}
+	})
+
+	t.Run("should reject proofs for another method", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", resourceServer.URL+"/orders", nil)
+		proof, _ := key.Proof("POST", resourceServer.URL+"/orders", key.Thumbprint())
+		req.Header.Set("Authorization", "DPoP "+key.Thumbprint())
+		req.Header.Set("DPoP", proof)
+
+		if status, challenge := send(req); status != http.StatusUnauthorized || !strings.Contains(challenge, "htm") {
+			t.Errorf("expected the proof to be rejected, got %d %s", status, challenge)
+		}
+	})
+
+	t.Run("should reject tokens bound to another key", func(t *testing.T) {
+		other, _ := NewDPoPKey()
+		req, _ := http.NewRequest("GET", resourceServer.URL+"/orders", nil)
+		other.SignRequest(req, key.Thumbprint())
+
+		if status, challenge := send(req); status != http.StatusUnauthorized || !strings.Contains(challenge, "invalid_token") {
+			t.Errorf("expected the token to be rejected, got %d %s", status, challenge)
+		}
+	})
+
+	t.Run("should reject bound tokens sent as bearer tokens", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", resourceServer.URL+"/orders", nil)
+		req.Header.Set("Authorization", "Bearer "+key.Thumbprint())
+		if status, _ := send(req); status != http.StatusUnauthorized {
+			t.Errorf("expected 401, got %d", status)
+		}
+
+		req.Header.Set("Authorization", "Bearer plain")
+		if status, _ := send(req); status != http.StatusOK {
+			t.Errorf("expected unbound bearer tokens to be allowed, got %d", status)
+		}
+	})
+
+	t.Run("should retry resource requests with the server's nonce", func(t *testing.T) {
+		var nonces []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var claims dpopClaims
+			payload, _ := b64.DecodeString(strings.Split(r.Header.Get("DPoP"), ".")[1])
+			json.Unmarshal(payload, &claims)
+			nonces = append(nonces, claims.Nonce)
+
+			if claims.Nonce != "resource-nonce" {
+				w.Header().Set("DPoP-Nonce", "resource-nonce")
+				w.Header().Set("WWW-Authenticate", `DPoP error="use_dpop_nonce"`)
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer server.Close()
+
+		httpClient := &http.Client{Transport: &DPoPTransport{
+			Key: key,
+			Source: NewTokenSource(func() (*Token, error) {
+				return &Token{AccessToken: "token"}, nil
+			}, TokenSourceOpts{}),
+		}}
+
+		res, err := httpClient.Post(server.URL, "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatalf("failed to send request: %v", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusOK || fmt.Sprint(nonces) != "[ resource-nonce]" {
+			t.Errorf("expected a retry with the nonce, got %d %v", res.StatusCode, nonces)
+		}
+	})
+}
@@ -1,5 +1,10 @@
 package auth
 
+import (
+	"fmt"
+	"strings"
+)
+
 type InvalidClientError struct {
 	error
 
@@ -27,3 +32,14 @@ func (e *InvalidRequest) Error() string {
 func (e *InvalidRequest) Unwrap() error {
 	return e.error
 }
+
+// UnsupportedGrantTypeError is returned before contacting the server when its
+// discovery metadata does not list the grant type
+type UnsupportedGrantTypeError struct {
+	GrantType string
+	Supported []string
+}
+
+func (e *UnsupportedGrantTypeError) Error() string {
+	return fmt.Sprintf("grant type %q is not supported by the server, supported: %s", e.GrantType, strings.Join(e.Supported, ", "))
+}
@@ -1,3 +1,12 @@
 module github.com/fxfn/x/auth
 
 go 1.24.4
+
+require github.com/fxfn/x/crypt v0.0.0-00010101000000-000000000000
+
+require (
+	golang.org/x/crypto v0.23.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+)
+
+replace github.com/fxfn/x/crypt => ../crypt
@@ -3,9 +3,8 @@ package auth
 import (
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
+	"time"
 )
 
 type ClientCredentials struct {
@@ -18,13 +17,19 @@ type GrantClientCredentialsOpts struct {
 	ClientID     string
 	ClientSecret string
 	Scope        string
+	Require      TokenRequirements // Checked on the returned token
 }
 
 func (a *Auth) GrantClientCredentials(opts GrantClientCredentialsOpts) (*Token, error) {
-	if a.server == nil {
-		return nil, &InvalidRequest{
-			message: "use auth.SetServer() or auth.Discovery() to set the server",
-		}
+	start := time.Now()
+	token, err := a.grantClientCredentials(opts)
+	a.recordTokenRequest("client_credentials", start, err)
+	return token, err
+}
+
+func (a *Auth) grantClientCredentials(opts GrantClientCredentialsOpts) (*Token, error) {
+	if err := a.checkGrant("client_credentials"); err != nil {
+		return nil, err
 	}
 
 	tokenEndpoint := a.server.TokenEndpoint
@@ -36,16 +41,14 @@ func (a *Auth) GrantClientCredentials(opts GrantClientCredentialsOpts) (*Token,
 		"client_secret": {opts.ClientSecret},
 	}
 
-	res, err := http.PostForm(tokenEndpoint, form)
+	res, err := a.postForm(tokenEndpoint, form)
 	if err != nil {
 		return nil, err
 	}
 
-	defer res.Body.Close()
-
-	body, err := io.ReadAll(res.Body)
+	body, err := readBody(res)
 	if err != nil {
-		return nil, err
+		return nil, grantError(err)
 	}
 
 	var token Token
@@ -64,5 +67,9 @@ func (a *Auth) GrantClientCredentials(opts GrantClientCredentialsOpts) (*Token,
 		return nil, fmt.Errorf("failed to grant client credentials: %v", token.Error)
 	}
 
+	if err := opts.Require.check(&token, opts.Scope); err != nil {
+		return nil, err
+	}
+
 	return &token, nil
 }
@@ -2,9 +2,8 @@ package auth
 
 import (
 	"encoding/json"
-	"io"
-	"net/http"
 	"net/url"
+	"time"
 )
 
 type GrantPasswordOpts struct {
@@ -13,12 +12,24 @@ type GrantPasswordOpts struct {
 	Scope        string
 	ClientID     string
 	ClientSecret string
+	Require      TokenRequirements // Checked on the returned token
 }
 
 func (a *Auth) GrantPassword(opts GrantPasswordOpts) (*Token, error) {
-	if a.server == nil {
+	start := time.Now()
+	token, err := a.grantPassword(opts)
+	a.recordTokenRequest("password", start, err)
+	return token, err
+}
+
+func (a *Auth) grantPassword(opts GrantPasswordOpts) (*Token, error) {
+	if err := a.checkGrant("password"); err != nil {
+		return nil, err
+	}
+
+	if opts.Username == "" || opts.Password == "" {
 		return nil, &InvalidRequest{
-			message: "use auth.SetServer() or auth.Discovery() to set the server",
+			message: "username and password are required",
 		}
 	}
 
@@ -26,25 +37,27 @@ func (a *Auth) GrantPassword(opts GrantPasswordOpts) (*Token, error) {
 
 	form := url.Values{
 		"grant_type":    {"password"},
+		"username":      {opts.Username},
+		"password":      {opts.Password},
 		"scope":         {opts.Scope},
 		"client_id":     {opts.ClientID},
 		"client_secret": {opts.ClientSecret},
 	}
 
-	res, err := http.PostForm(tokenEndpoint, form)
+	res, err := a.postForm(tokenEndpoint, form)
 	if err != nil {
 		return nil, err
 	}
 
-	defer res.Body.Close()
-
-	body, err := io.ReadAll(res.Body)
+	body, err := readBody(res)
 	if err != nil {
-		return nil, err
+		return nil, grantError(err)
 	}
 
 	var token Token
-	json.Unmarshal(body, &token)
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, err
+	}
 
 	if token.Error == "unsupported_grant_type" {
 		return nil, &InvalidRequest{
@@ -52,5 +65,9 @@ func (a *Auth) GrantPassword(opts GrantPasswordOpts) (*Token, error) {
 		}
 	}
 
+	if err := opts.Require.check(&token, opts.Scope); err != nil {
+		return nil, err
+	}
+
 	return &token, nil
 }
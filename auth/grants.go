@@ -0,0 +1,70 @@
+package auth
+
+import "slices"
+
+// SupportsGrant reports whether the server supports a grant type, such as
+// "password" or "client_credentials". Servers without grant_types_supported
+// in their metadata, e.g. ones configured with SetServer, are assumed to
+// support every grant type.
+func (a *Auth) SupportsGrant(grantType string) bool {
+	if a.server == nil {
+		return false
+	}
+
+	if len(a.server.GrantTypesSupported) == 0 {
+		return true
+	}
+
+	return slices.Contains(a.server.GrantTypesSupported, grantType)
+}
+
+// SelectGrant returns the first of the preferred grant types the server supports
+func (a *Auth) SelectGrant(preferred ...string) (string, error) {
+	if len(preferred) == 0 {
+		return "", &InvalidRequest{
+			message: "no grant types to select from",
+		}
+	}
+
+	for _, grantType := range preferred {
+		if a.SupportsGrant(grantType) {
+			return grantType, nil
+		}
+	}
+
+	return "", &UnsupportedGrantTypeError{
+		GrantType: preferred[0],
+		Supported: a.supportedGrants(),
+	}
+}
+
+// checkGrant validates that a grant can be requested from the server
+func (a *Auth) checkGrant(grantType string) error {
+	if a.server == nil {
+		return &InvalidRequest{
+			message: "use auth.SetServer() or auth.Discovery() to set the server",
+		}
+	}
+
+	if a.server.TokenEndpoint == "" {
+		return &InvalidRequest{
+			message: "the server has no token endpoint",
+		}
+	}
+
+	if !a.SupportsGrant(grantType) {
+		return &UnsupportedGrantTypeError{
+			GrantType: grantType,
+			Supported: a.supportedGrants(),
+		}
+	}
+
+	return nil
+}
+
+func (a *Auth) supportedGrants() []string {
+	if a.server == nil {
+		return nil
+	}
+	return a.server.GrantTypesSupported
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSupportsGrant(t *testing.T) {
+	t.Run("should check the discovered grant types", func(t *testing.T) {
+		auth := Default()
+		auth.SetServer(&Server{GrantTypesSupported: []string{"client_credentials"}})
+
+		if !auth.SupportsGrant("client_credentials") {
+			t.Errorf("client_credentials should be supported")
+		}
+		if auth.SupportsGrant("password") {
+			t.Errorf("password should not be supported")
+		}
+	})
+
+	t.Run("should assume every grant type without metadata", func(t *testing.T) {
+		auth := Default()
+		auth.SetServer(&Server{TokenEndpoint: "https://auth.example.com/token"})
+
+		if !auth.SupportsGrant("password") {
+			t.Errorf("password should be supported")
+		}
+	})
+
+	t.Run("should not support grants without a server", func(t *testing.T) {
+		if Default().SupportsGrant("password") {
+			t.Errorf("password should not be supported")
+		}
+	})
+}
+
+func TestSelectGrant(t *testing.T) {
+	auth := Default()
+	auth.SetServer(&Server{GrantTypesSupported: []string{"client_credentials", "refresh_token"}})
+
+	grantType, err := auth.SelectGrant("password", "client_credentials")
+	if err != nil || grantType != "client_credentials" {
+		t.Errorf("expected client_credentials, got %q, %v", grantType, err)
+	}
+
+	var unsupported *UnsupportedGrantTypeError
+	if _, err := auth.SelectGrant("password"); !errors.As(err, &unsupported) {
+		t.Errorf("expected UnsupportedGrantTypeError, got %v", err)
+	}
+}
+
+func TestGrantPreflight(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"error":"unsupported_grant_type"}`))
+	}))
+	defer server.Close()
+
+	auth := Default()
+	auth.SetServer(&Server{
+		TokenEndpoint:       server.URL,
+		GrantTypesSupported: []string{"client_credentials"},
+	})
+
+	_, err := auth.GrantPassword(GrantPasswordOpts{Username: "user", Password: "password"})
+
+	var unsupported *UnsupportedGrantTypeError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected UnsupportedGrantTypeError, got %v", err)
+	}
+	if unsupported.GrantType != "password" || len(unsupported.Supported) != 1 {
+		t.Errorf("unexpected error %+v", unsupported)
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests, got %d", requests)
+	}
+
+	auth.SetServer(&Server{TokenEndpoint: server.URL})
+	if _, err := auth.GrantPassword(GrantPasswordOpts{}); err == nil {
+		t.Errorf("expected an error without username and password")
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests, got %d", requests)
+	}
+}
+
+func TestTokenRequirements(t *testing.T) {
+	// JWT access token with "aud":["orders","billing"]; the signature isn't checked
+	accessToken := "eyJhbGciOiJSUzI1NiJ9." + b64.EncodeToString([]byte(`{"aud":["orders","billing"]}`)) + ".c2ln"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":300,"scope":"orders:read"}`, accessToken)
+	}))
+	defer server.Close()
+
+	auth := Default()
+	auth.SetServer(&Server{TokenEndpoint: server.URL})
+
+	grant := func(require TokenRequirements) error {
+		_, err := auth.GrantClientCredentials(GrantClientCredentialsOpts{ClientID: "client", Require: require})
+		return err
+	}
+
+	t.Run("should accept tokens meeting the requirements", func(t *testing.T) {
+		err := grant(TokenRequirements{Scopes: []string{"orders:read"}, Audience: "billing", MinLifetime: time.Minute})
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should report missing scopes", func(t *testing.T) {
+		var missing *MissingScopeError
+		if err := grant(TokenRequirements{Scopes: []string{"orders:read", "orders:write"}}); !errors.As(err, &missing) {
+			t.Fatalf("expected MissingScopeError, got %v", err)
+		}
+		if len(missing.Missing) != 1 || missing.Missing[0] != "orders:write" {
+			t.Errorf("unexpected missing scopes %v", missing.Missing)
+		}
+	})
+
+	t.Run("should report another audience", func(t *testing.T) {
+		var mismatch *AudienceMismatchError
+		if err := grant(TokenRequirements{Audience: "payments"}); !errors.As(err, &mismatch) {
+			t.Fatalf("expected AudienceMismatchError, got %v", err)
+		}
+		if len(mismatch.Actual) != 2 {
+			t.Errorf("unexpected audience %v", mismatch.Actual)
+		}
+	})
+
+	t.Run("should report short lifetimes", func(t *testing.T) {
+		var lifetime *TokenLifetimeError
+		if err := grant(TokenRequirements{MinLifetime: time.Hour}); !errors.As(err, &lifetime) {
+			t.Fatalf("expected TokenLifetimeError, got %v", err)
+		}
+		if lifetime.Lifetime != 5*time.Minute {
+			t.Errorf("unexpected lifetime %s", lifetime.Lifetime)
+		}
+	})
+
+	t.Run("should reject opaque tokens when an audience is required", func(t *testing.T) {
+		accessToken = "opaque"
+		var mismatch *AudienceMismatchError
+		if err := grant(TokenRequirements{Audience: "orders"}); !errors.As(err, &mismatch) || len(mismatch.Actual) != 0 {
+			t.Errorf("expected AudienceMismatchError without audience, got %v", err)
+		}
+	})
+}
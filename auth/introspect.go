@@ -1,14 +1,15 @@
 package auth
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type IntrospectOpts struct {
@@ -18,18 +19,95 @@ type IntrospectOpts struct {
 }
 
 type IntrospectResponse struct {
-	Active    bool   `json:"active"`
-	ClientID  string `json:"client_id"`
-	Username  string `json:"username"`
-	Scope     string `json:"scope"`
-	Subject   string `json:"sub"`
-	Audience  string `json:"aud"`
-	Issuer    string `json:"iss"`
-	ExpiresAt int    `json:"exp"`
-	IssuedAt  int    `json:"iat"`
-	TokenType string `json:"token_type"`
-	NotBefore int    `json:"nbf"`
-	TokenID   string `json:"jti"`
+	Active    bool     `json:"active"`
+	ClientID  string   `json:"client_id"`
+	Username  string   `json:"username"`
+	Scope     string   `json:"scope"`
+	Subject   string   `json:"sub"`
+	Audience  Audience `json:"aud"`
+	Issuer    string   `json:"iss"`
+	ExpiresAt int      `json:"exp"`
+	IssuedAt  int      `json:"iat"`
+	TokenType string   `json:"token_type"`
+	NotBefore int      `json:"nbf"`
+	TokenID   string   `json:"jti"`
+
+	// Confirmation names the key a token is bound to, e.g. with DPoP
+	Confirmation *Confirmation `json:"cnf,omitempty"`
+
+	// Extra holds the claims not declared above, e.g. custom claims of the server
+	Extra Claims `json:"-"`
+}
+
+// UnmarshalJSON decodes the declared claims and keeps the others in Extra
+func (r *IntrospectResponse) UnmarshalJSON(data []byte) error {
+	type introspectResponse IntrospectResponse
+	var declared introspectResponse
+	if err := json.Unmarshal(data, &declared); err != nil {
+		return err
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return err
+	}
+	for _, name := range introspectClaims {
+		delete(claims, name)
+	}
+	if len(claims) > 0 {
+		declared.Extra = claims
+	}
+
+	*r = IntrospectResponse(declared)
+	return nil
+}
+
+// MarshalJSON writes the Extra claims next to the declared ones
+func (r IntrospectResponse) MarshalJSON() ([]byte, error) {
+	type introspectResponse IntrospectResponse
+	data, err := json.Marshal(introspectResponse(r))
+	if err != nil || len(r.Extra) == 0 {
+		return data, err
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, err
+	}
+	for name, value := range r.Extra {
+		if _, declared := claims[name]; !declared {
+			claims[name] = value
+		}
+	}
+	return json.Marshal(claims)
+}
+
+// introspectClaims are the claims IntrospectResponse declares
+var introspectClaims = []string{"active", "client_id", "username", "scope", "sub", "aud", "iss", "exp", "iat", "token_type", "nbf", "jti", "cnf"}
+
+// Confirmation is the cnf claim of a token bound to a key (RFC 7800)
+type Confirmation struct {
+	JKT string `json:"jkt,omitempty"` // JWK thumbprint of a DPoP key
+}
+
+// Scopes returns the space-separated scope claim as a list
+func (r *IntrospectResponse) Scopes() []string {
+	return strings.Fields(r.Scope)
+}
+
+type introspectionContextKey struct{}
+
+// IntrospectionFromContext returns the introspected token a middleware such
+// as DPoPMiddleware stored in a request context
+func IntrospectionFromContext(ctx context.Context) (*IntrospectResponse, bool) {
+	introspection, ok := ctx.Value(introspectionContextKey{}).(*IntrospectResponse)
+	return introspection, ok
+}
+
+// ContextWithIntrospection returns a copy of ctx carrying an introspected
+// token, for middleware of other routers to store it
+func ContextWithIntrospection(ctx context.Context, introspection *IntrospectResponse) context.Context {
+	return context.WithValue(ctx, introspectionContextKey{}, introspection)
 }
 
 func (a *Auth) Introspect(opts IntrospectOpts) (*IntrospectResponse, error) {
@@ -37,7 +115,13 @@ func (a *Auth) Introspect(opts IntrospectOpts) (*IntrospectResponse, error) {
 }
 
 func IntrospectGeneric[T any](a *Auth, opts IntrospectOpts) (*T, error) {
+	start := time.Now()
+	response, err := introspect[T](a, opts)
+	a.recordIntrospection(start, err)
+	return response, err
+}
 
+func introspect[T any](a *Auth, opts IntrospectOpts) (*T, error) {
 	if a.server == nil {
 		return nil, errors.New("no server set")
 	}
@@ -63,19 +147,20 @@ func IntrospectGeneric[T any](a *Auth, opts IntrospectOpts) (*T, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", opts.ClientId, opts.ClientSecret)))))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := a.do(req, values)
 	if err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
+	body, err := readBody(res)
 	if err != nil {
 		return nil, err
 	}
 
 	var introspectResponse T
-	json.Unmarshal(body, &introspectResponse)
+	if err := json.Unmarshal(body, &introspectResponse); err != nil {
+		return nil, err
+	}
 
 	return &introspectResponse, nil
 }
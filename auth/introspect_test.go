@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
@@ -56,3 +59,86 @@ func TestIntrospect(t *testing.T) {
 		}
 	})
 }
+
+func TestIntrospectClaims(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"active": true,
+			"sub": "user-1",
+			"scope": "read write",
+			"aud": ["api", "billing"],
+			"tenant": "acme",
+			"roles": ["admin", "editor"],
+			"mfa": true,
+			"auth_time": 1700000000,
+			"org": {"id": "org-1"}
+		}`))
+	}))
+	defer server.Close()
+
+	auth := Default()
+	auth.SetServer(&Server{IntrospectionEndpoint: server.URL})
+
+	response, err := auth.Introspect(IntrospectOpts{Token: "test"})
+	if err != nil {
+		t.Fatalf("failed to introspect: %v", err)
+	}
+
+	if !response.Audience.Contains("billing") || len(response.Audience) != 2 {
+		t.Errorf("unexpected audience %v", response.Audience)
+	}
+	if scopes := response.Scopes(); len(scopes) != 2 || scopes[1] != "write" {
+		t.Errorf("unexpected scopes %v", scopes)
+	}
+	if _, declared := response.Extra["sub"]; declared {
+		t.Errorf("declared claims should not be in Extra")
+	}
+
+	if tenant, ok := response.Extra.String("tenant"); !ok || tenant != "acme" {
+		t.Errorf("expected tenant acme, got %q", tenant)
+	}
+	if roles, ok := response.Extra.Strings("roles"); !ok || len(roles) != 2 {
+		t.Errorf("unexpected roles %v", roles)
+	}
+	if mfa, ok := response.Extra.Bool("mfa"); !ok || !mfa {
+		t.Errorf("expected mfa")
+	}
+	if authTime, ok := response.Extra.Int64("auth_time"); !ok || authTime != 1700000000 {
+		t.Errorf("unexpected auth_time %d", authTime)
+	}
+
+	var org struct {
+		ID string `json:"id"`
+	}
+	if err := response.Extra.Decode("org", &org); err != nil || org.ID != "org-1" {
+		t.Errorf("unexpected org %+v, %v", org, err)
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	var roundTrip IntrospectResponse
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if tenant, _ := roundTrip.Extra.String("tenant"); tenant != "acme" || len(roundTrip.Audience) != 2 {
+		t.Errorf("claims lost in round trip: %s", data)
+	}
+}
+
+func TestAudience(t *testing.T) {
+	var response IntrospectResponse
+	if err := json.Unmarshal([]byte(`{"aud":"api"}`), &response); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if !response.Audience.Contains("api") {
+		t.Errorf("expected audience api, got %v", response.Audience)
+	}
+
+	data, _ := json.Marshal(response.Audience)
+	if string(data) != `"api"` {
+		t.Errorf("expected a single audience as a string, got %s", data)
+	}
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/fxfn/x/crypt"
+)
+
+// jwksRefreshInterval is how often the key set is fetched again at most, when
+// a token names a key it doesn't contain, e.g. after the server rotated keys
+const jwksRefreshInterval = time.Minute
+
+// keySet caches the public keys of the server's jwks_uri by key ID
+type keySet struct {
+	mu      sync.Mutex
+	keys    map[string]jsonWebKey
+	fetched time.Time
+}
+
+// jsonWebKey is a public key of a JWKS
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// verifyJWT checks the signature of a JWT with the server's key it names and
+// returns its payload and header
+func (a *Auth) verifyJWT(token string) ([]byte, *crypt.JWSHeader, error) {
+	header, err := crypt.ParseJWSHeader(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := a.signingKey(header.Kid, header.Alg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return crypt.VerifyJWS(token, key)
+}
+
+// signingKey returns the public key with a key ID, fetching the key set when
+// it isn't known yet. Tokens without a key ID use the only key for alg.
+func (a *Auth) signingKey(kid string, alg crypt.JWSAlgorithm) (any, error) {
+	if a.server == nil || a.server.JwksUri == "" {
+		return nil, errors.New("no jwks_uri set")
+	}
+
+	a.jwks.mu.Lock()
+	defer a.jwks.mu.Unlock()
+
+	jwk, found := a.jwks.find(kid, alg)
+	if !found && time.Since(a.jwks.fetched) > jwksRefreshInterval {
+		if err := a.fetchKeys(); err != nil {
+			return nil, err
+		}
+		jwk, found = a.jwks.find(kid, alg)
+	}
+	if !found {
+		return nil, fmt.Errorf("no key %q for %s in the server's key set", kid, alg)
+	}
+
+	return jwk.publicKey(alg)
+}
+
+// fetchKeys replaces the key set with the one at jwks_uri. It must be called
+// with a.jwks.mu held.
+func (a *Auth) fetchKeys() error {
+	res, err := a.get(a.server.JwksUri)
+	if err != nil {
+		return err
+	}
+
+	body, err := readBody(res)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &set); err != nil {
+		return err
+	}
+
+	a.jwks.keys = make(map[string]jsonWebKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use == "" || key.Use == "sig" {
+			a.jwks.keys[key.Kid] = key
+		}
+	}
+	a.jwks.fetched = time.Now()
+	return nil
+}
+
+func (ks *keySet) find(kid string, alg crypt.JWSAlgorithm) (jsonWebKey, bool) {
+	if kid != "" {
+		jwk, exists := ks.keys[kid]
+		return jwk, exists
+	}
+
+	var match jsonWebKey
+	matches := 0
+	for _, jwk := range ks.keys {
+		if jwk.fits(alg) {
+			match = jwk
+			matches++
+		}
+	}
+	return match, matches == 1
+}
+
+// fits reports whether the key can verify signatures with alg
+func (k jsonWebKey) fits(alg crypt.JWSAlgorithm) bool {
+	if k.Alg != "" && k.Alg != string(alg) {
+		return false
+	}
+
+	switch alg {
+	case crypt.RS256, crypt.RS384, crypt.RS512:
+		return k.Kty == "RSA"
+	case crypt.ES256:
+		return k.Kty == "EC" && k.Crv == "P-256"
+	}
+	return false
+}
+
+func (k jsonWebKey) publicKey(alg crypt.JWSAlgorithm) (any, error) {
+	if !k.fits(alg) {
+		return nil, fmt.Errorf("key %q can't verify %s signatures", k.Kid, alg)
+	}
+
+	if k.Kty == "EC" {
+		return ecJWK{Kty: k.Kty, Crv: k.Crv, X: k.X, Y: k.Y}.publicKey()
+	}
+
+	n, errN := b64.DecodeString(k.N)
+	e, errE := b64.DecodeString(k.E)
+	if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("invalid RSA key %q", k.Kid)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type options struct {
+	metadata []byte
+	observer Observer
+	metrics  Metrics
+	dpop     *DPoPKey
+}
+
+// Option configures FromIssuer
+type Option func(*options)
+
+// WithStaticMetadata makes FromIssuer use an OpenID Connect discovery document
+// instead of fetching it, e.g. one embedded with go:embed
+func WithStaticMetadata(metadata []byte) Option {
+	return func(o *options) {
+		o.metadata = metadata
+	}
+}
+
+// FromMetadataJSON creates an Auth from an OpenID Connect discovery document
+// without a network call. Refresh fetches the document again from the issuer
+// it names.
+func FromMetadataJSON(metadata []byte) (*Auth, error) {
+	server, err := parseServerMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &Auth{server: server}
+	if server.Issuer != "" {
+		auth.endpoint = discoveryEndpoint(server.Issuer)
+	}
+
+	return auth, nil
+}
+
+// FromIssuer creates an Auth for an issuer, fetching its discovery document
+// like Discovery unless WithStaticMetadata provides it. Static metadata must
+// name the same issuer when it names one. Refresh fetches the document from
+// the issuer either way.
+func FromIssuer(issuer string, opts ...Option) (*Auth, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.metadata == nil {
+		auth := &Auth{
+			endpoint: discoveryEndpoint(issuer),
+			observer: o.observer,
+			metrics:  o.metrics,
+			dpop:     o.dpop,
+		}
+		if err := auth.Refresh(); err != nil {
+			return nil, err
+		}
+		return auth, nil
+	}
+
+	server, err := parseServerMetadata(o.metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if server.Issuer != "" && strings.TrimSuffix(server.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, &InvalidRequest{
+			message: fmt.Sprintf("metadata issuer %q does not match %q", server.Issuer, issuer),
+		}
+	}
+
+	return &Auth{
+		endpoint: discoveryEndpoint(issuer),
+		server:   server,
+		observer: o.observer,
+		metrics:  o.metrics,
+		dpop:     o.dpop,
+	}, nil
+}
+
+// Refresh fetches the discovery document again, e.g. after the provider added
+// endpoints or rotated its configuration
+func (a *Auth) Refresh() error {
+	if a.endpoint == "" {
+		return &InvalidRequest{
+			message: "use auth.Discovery(), auth.FromIssuer() or metadata with an issuer to refresh",
+		}
+	}
+
+	server, err := a.fetchServerMetadata(a.endpoint)
+	if err != nil {
+		return err
+	}
+
+	a.server = server
+	return nil
+}
+
+func discoveryEndpoint(issuer string) string {
+	if strings.HasSuffix(issuer, ".well-known/openid-configuration") {
+		return issuer
+	}
+	return fmt.Sprintf("%s/.well-known/openid-configuration", strings.TrimSuffix(issuer, "/"))
+}
+
+func parseServerMetadata(metadata []byte) (*Server, error) {
+	var server Server
+	if err := json.Unmarshal(metadata, &server); err != nil {
+		return nil, err
+	}
+
+	return &server, nil
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromMetadataJSON(t *testing.T) {
+	t.Run("should create auth without a network call", func(t *testing.T) {
+		auth, err := FromMetadataJSON([]byte(`{"issuer":"https://auth.example.com","token_endpoint":"https://auth.example.com/token"}`))
+		if err != nil {
+			t.Fatalf("failed to create auth: %v", err)
+		}
+
+		if auth.server.TokenEndpoint != "https://auth.example.com/token" {
+			t.Fatalf("token endpoint is not set")
+		}
+
+		if auth.endpoint != "https://auth.example.com/.well-known/openid-configuration" {
+			t.Fatalf("discovery endpoint is %q", auth.endpoint)
+		}
+	})
+
+	t.Run("should return an error for invalid json", func(t *testing.T) {
+		if _, err := FromMetadataJSON([]byte(`{`)); err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+	})
+}
+
+func TestFromIssuer(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"issuer":"http://%s","token_endpoint":"http://%s/oauth/token"}`, r.Host, r.Host)
+	}))
+	defer server.Close()
+
+	t.Run("should use static metadata and refresh from the issuer", func(t *testing.T) {
+		metadata := fmt.Sprintf(`{"issuer":"%s","token_endpoint":"%s/token"}`, server.URL, server.URL)
+		auth, err := FromIssuer(server.URL, WithStaticMetadata([]byte(metadata)))
+		if err != nil {
+			t.Fatalf("failed to create auth: %v", err)
+		}
+
+		if requests != 0 {
+			t.Fatalf("expected no requests, got %d", requests)
+		}
+
+		if auth.server.TokenEndpoint != server.URL+"/token" {
+			t.Fatalf("token endpoint is not set")
+		}
+
+		if err := auth.Refresh(); err != nil {
+			t.Fatalf("failed to refresh: %v", err)
+		}
+
+		if auth.server.TokenEndpoint != server.URL+"/oauth/token" {
+			t.Fatalf("token endpoint is not refreshed: %s", auth.server.TokenEndpoint)
+		}
+	})
+
+	t.Run("should fetch metadata without static metadata", func(t *testing.T) {
+		auth, err := FromIssuer(server.URL + "/")
+		if err != nil {
+			t.Fatalf("failed to create auth: %v", err)
+		}
+
+		if auth.server.TokenEndpoint != server.URL+"/oauth/token" {
+			t.Fatalf("token endpoint is not set")
+		}
+	})
+
+	t.Run("should reject metadata of another issuer", func(t *testing.T) {
+		_, err := FromIssuer(server.URL, WithStaticMetadata([]byte(`{"issuer":"https://other.example.com"}`)))
+		if _, ok := err.(*InvalidRequest); !ok {
+			t.Fatalf("expected InvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("should not refresh without an issuer", func(t *testing.T) {
+		if err := Default().Refresh(); err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+	})
+}
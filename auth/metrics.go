@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// Outcomes of token requests and introspections reported to Metrics. Failed
+// requests the server answered with an OAuth error report its error code
+// instead, e.g. "invalid_client".
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Metrics receives counters and timings of token operations, e.g. to alert
+// on a slow authorization server or a storm of token requests. Methods are
+// called synchronously and must be safe for concurrent use.
+type Metrics interface {
+	// TokenRequest is called after every grant with its grant type, e.g.
+	// "client_credentials"
+	TokenRequest(grantType string, outcome string, duration time.Duration)
+
+	// Introspection is called after every token introspection
+	Introspection(outcome string, duration time.Duration)
+
+	// TokenCache is called whenever a TokenSource is asked for a token, with
+	// hit true when a cached token was returned without waiting for a fetch
+	TokenCache(hit bool)
+}
+
+// SetMetrics sets the metrics receiving counters and timings of token
+// operations
+func (a *Auth) SetMetrics(metrics Metrics) {
+	a.metrics = metrics
+}
+
+// WithMetrics sets the metrics of an Auth created by FromIssuer
+func WithMetrics(metrics Metrics) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
+
+// recordTokenRequest reports a grant that started at start
+func (a *Auth) recordTokenRequest(grantType string, start time.Time, err error) {
+	if a.metrics != nil {
+		a.metrics.TokenRequest(grantType, outcome(err), time.Since(start))
+	}
+}
+
+// recordIntrospection reports an introspection that started at start
+func (a *Auth) recordIntrospection(start time.Time, err error) {
+	if a.metrics != nil {
+		a.metrics.Introspection(outcome(err), time.Since(start))
+	}
+}
+
+// outcome names the result of a request for Metrics
+func outcome(err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.OAuthError != "" {
+		return httpErr.OAuthError
+	}
+	return OutcomeError
+}
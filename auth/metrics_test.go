@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mu             sync.Mutex
+	tokenRequests  []string
+	introspections []string
+	hits, misses   int
+}
+
+func (r *recordingMetrics) TokenRequest(grantType string, outcome string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokenRequests = append(r.tokenRequests, grantType+" "+outcome)
+}
+
+func (r *recordingMetrics) Introspection(outcome string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.introspections = append(r.introspections, outcome)
+}
+
+func (r *recordingMetrics) TokenCache(hit bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hit {
+		r.hits++
+	} else {
+		r.misses++
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/introspect":
+			w.Write([]byte(`{"active":true}`))
+		case r.FormValue("client_secret") == "wrong":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client"}`))
+		default:
+			w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+		}
+	}))
+	defer server.Close()
+
+	newAuth := func(metrics Metrics) *Auth {
+		auth := Default()
+		auth.SetServer(&Server{
+			TokenEndpoint:         server.URL + "/token",
+			IntrospectionEndpoint: server.URL + "/introspect",
+		})
+		auth.SetMetrics(metrics)
+		return auth
+	}
+
+	t.Run("should count token requests by grant type and outcome", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+		auth := newAuth(metrics)
+
+		auth.GrantClientCredentials(GrantClientCredentialsOpts{ClientID: "client"})
+		auth.GrantClientCredentials(GrantClientCredentialsOpts{ClientID: "client", ClientSecret: "wrong"})
+		auth.GrantPassword(GrantPasswordOpts{Username: "user", Password: "password"})
+
+		expected := []string{"client_credentials success", "client_credentials invalid_client", "password success"}
+		if fmt.Sprint(metrics.tokenRequests) != fmt.Sprint(expected) {
+			t.Errorf("expected %v, got %v", expected, metrics.tokenRequests)
+		}
+	})
+
+	t.Run("should report introspections", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+		auth := newAuth(metrics)
+
+		if _, err := auth.Introspect(IntrospectOpts{Token: "token"}); err != nil {
+			t.Fatalf("failed to introspect: %v", err)
+		}
+
+		failing := newAuth(metrics)
+		failing.SetServer(&Server{IntrospectionEndpoint: "http://127.0.0.1:1/introspect"})
+		failing.Introspect(IntrospectOpts{Token: "token"})
+
+		expected := []string{OutcomeSuccess, OutcomeError}
+		if fmt.Sprint(metrics.introspections) != fmt.Sprint(expected) {
+			t.Errorf("expected %v, got %v", expected, metrics.introspections)
+		}
+	})
+
+	t.Run("should report token source cache hits and misses", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+		auth := newAuth(metrics)
+		source := auth.ClientCredentialsTokenSource(GrantClientCredentialsOpts{ClientID: "client"}, TokenSourceOpts{})
+
+		for range 3 {
+			if _, err := source.Token(); err != nil {
+				t.Fatalf("failed to get token: %v", err)
+			}
+		}
+
+		if metrics.hits != 2 || metrics.misses != 1 {
+			t.Errorf("expected 2 hits and 1 miss, got %d and %d", metrics.hits, metrics.misses)
+		}
+		if len(metrics.tokenRequests) != 1 {
+			t.Errorf("expected 1 token request, got %v", metrics.tokenRequests)
+		}
+	})
+}
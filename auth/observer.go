@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Redacted replaces secrets in observed requests and responses
+const Redacted = "[REDACTED]"
+
+// Observer is notified of every request to the authorization server. Events
+// carry copies with client secrets, passwords, tokens and credential headers
+// replaced by Redacted, so they are safe to log.
+type Observer interface {
+	OnRequest(event RequestEvent)
+	OnResponse(event ResponseEvent)
+}
+
+// RequestEvent describes a request about to be sent
+type RequestEvent struct {
+	Method   string
+	Endpoint string
+	Header   http.Header
+	Form     url.Values
+}
+
+// ResponseEvent describes the outcome of a request
+type ResponseEvent struct {
+	Method   string
+	Endpoint string
+	Status   int // 0 when the request failed
+	Duration time.Duration
+	Body     string
+	Err      error
+}
+
+// SetObserver sets the observer notified of requests to the server
+func (a *Auth) SetObserver(observer Observer) {
+	a.observer = observer
+}
+
+// WithObserver sets the observer of an Auth created by FromIssuer
+func WithObserver(observer Observer) Option {
+	return func(o *options) {
+		o.observer = observer
+	}
+}
+
+// LogObserver returns an Observer logging requests at debug level and
+// responses at debug level, or warn level for failures
+func LogObserver(logger *slog.Logger) Observer {
+	return &logObserver{logger: logger}
+}
+
+type logObserver struct {
+	logger *slog.Logger
+}
+
+func (l *logObserver) OnRequest(event RequestEvent) {
+	l.logger.Debug("auth request",
+		"method", event.Method,
+		"endpoint", event.Endpoint,
+		"header", event.Header,
+		"form", event.Form.Encode(),
+	)
+}
+
+func (l *logObserver) OnResponse(event ResponseEvent) {
+	level := slog.LevelDebug
+	if event.Err != nil || event.Status >= 400 {
+		level = slog.LevelWarn
+	}
+
+	attrs := []any{
+		"method", event.Method,
+		"endpoint", event.Endpoint,
+		"status", event.Status,
+		"duration", event.Duration,
+		"body", event.Body,
+	}
+	if event.Err != nil {
+		attrs = append(attrs, "error", event.Err)
+	}
+	l.logger.Log(context.Background(), level, "auth response", attrs...)
+}
+
+// do sends a request to the server, notifying the observer. form is the
+// request's form body, if any, for the request event.
+func (a *Auth) do(req *http.Request, form url.Values) (*http.Response, error) {
+	if a.observer == nil {
+		return http.DefaultClient.Do(req)
+	}
+
+	endpoint := redactURL(req.URL)
+	a.observer.OnRequest(RequestEvent{
+		Method:   req.Method,
+		Endpoint: endpoint,
+		Header:   redactHeader(req.Header),
+		Form:     redactValues(form),
+	})
+
+	start := time.Now()
+	res, err := http.DefaultClient.Do(req)
+	event := ResponseEvent{
+		Method:   req.Method,
+		Endpoint: endpoint,
+		Err:      err,
+	}
+	if err != nil {
+		event.Duration = time.Since(start)
+		a.observer.OnResponse(event)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	event.Status = res.StatusCode
+	event.Duration = time.Since(start)
+	event.Body = redactBody(body)
+	event.Err = err
+	a.observer.OnResponse(event)
+
+	return res, err
+}
+
+// postForm posts a form to the server like http.PostForm. With a DPoP key
+// the request carries a proof, and is sent again when the server asks for a
+// nonce.
+func (a *Auth) postForm(endpoint string, form url.Values) (*http.Response, error) {
+	res, err := a.sendForm(endpoint, form)
+	if err != nil || a.dpop == nil {
+		return res, err
+	}
+
+	a.dpop.rememberNonce(res)
+	if !dpopNonceRequired(res) {
+		return res, nil
+	}
+	res.Body.Close()
+
+	res, err = a.sendForm(endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	a.dpop.rememberNonce(res)
+	return res, nil
+}
+
+func (a *Auth) sendForm(endpoint string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if a.dpop != nil {
+		proof, err := a.dpop.Proof("POST", endpoint, "")
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("DPoP", proof)
+	}
+
+	return a.do(req, form)
+}
+
+// get fetches a document from the server like http.Get
+func (a *Auth) get(endpoint string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.do(req, nil)
+}
+
+var sensitiveParams = map[string]bool{
+	"client_secret":             true,
+	"client_assertion":          true,
+	"password":                  true,
+	"token":                     true,
+	"access_token":              true,
+	"refresh_token":             true,
+	"id_token":                  true,
+	"code":                      true,
+	"code_verifier":             true,
+	"assertion":                 true,
+	"subject_token":             true,
+	"actor_token":               true,
+	"device_code":               true,
+	"registration_token":        true,
+	"registration_access_token": true,
+}
+
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+func redactValues(values url.Values) url.Values {
+	if values == nil {
+		return nil
+	}
+
+	redacted := make(url.Values, len(values))
+	for key, value := range values {
+		if sensitiveParams[strings.ToLower(key)] {
+			redacted[key] = []string{Redacted}
+			continue
+		}
+		redacted[key] = append([]string(nil), value...)
+	}
+	return redacted
+}
+
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, Redacted)
+		}
+	}
+	return redacted
+}
+
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	if redacted.RawQuery != "" {
+		redacted.RawQuery = redactValues(u.Query()).Encode()
+	}
+	return redacted.String()
+}
+
+// redactBody redacts sensitive fields of a JSON object body, other bodies are
+// returned as they are
+func redactBody(body []byte) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return string(body)
+	}
+
+	changed := false
+	for key := range fields {
+		if sensitiveParams[strings.ToLower(key)] {
+			fields[key] = json.RawMessage(`"` + Redacted + `"`)
+			changed = true
+		}
+	}
+	if !changed {
+		return string(body)
+	}
+
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return Redacted
+	}
+	return string(redacted)
+}
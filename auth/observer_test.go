@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordingObserver struct {
+	requests  []RequestEvent
+	responses []ResponseEvent
+}
+
+func (r *recordingObserver) OnRequest(event RequestEvent) {
+	r.requests = append(r.requests, event)
+}
+
+func (r *recordingObserver) OnResponse(event ResponseEvent) {
+	r.responses = append(r.responses, event)
+}
+
+func TestObserver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.Write([]byte(`{"access_token":"secret-access","refresh_token":"secret-refresh","token_type":"Bearer"}`))
+		case "/introspect":
+			w.Write([]byte(`{"active":true}`))
+		}
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	auth := Default()
+	auth.SetServer(&Server{
+		TokenEndpoint:         server.URL + "/token",
+		IntrospectionEndpoint: server.URL + "/introspect",
+	})
+	auth.SetObserver(observer)
+
+	t.Run("should redact secrets in token requests and responses", func(t *testing.T) {
+		token, err := auth.GrantClientCredentials(GrantClientCredentialsOpts{
+			ClientID:     "client",
+			ClientSecret: "secret-client",
+		})
+		if err != nil {
+			t.Fatalf("failed to grant client credentials: %v", err)
+		}
+
+		if token.AccessToken != "secret-access" {
+			t.Fatalf("token should not be redacted, got %s", token.AccessToken)
+		}
+
+		request := observer.requests[0]
+		if request.Method != "POST" || request.Endpoint != server.URL+"/token" {
+			t.Errorf("unexpected request %s %s", request.Method, request.Endpoint)
+		}
+		if request.Form.Get("client_secret") != Redacted || request.Form.Get("client_id") != "client" {
+			t.Errorf("client secret should be redacted, got %v", request.Form)
+		}
+
+		response := observer.responses[0]
+		if response.Status != 200 {
+			t.Errorf("status should be 200, got %d", response.Status)
+		}
+		if strings.Contains(response.Body, "secret-") || !strings.Contains(response.Body, "Bearer") {
+			t.Errorf("tokens should be redacted, got %s", response.Body)
+		}
+	})
+
+	t.Run("should redact the authorization header and token of introspection", func(t *testing.T) {
+		_, err := auth.Introspect(IntrospectOpts{
+			Token:        "secret-token",
+			ClientId:     "client",
+			ClientSecret: "secret-client",
+		})
+		if err != nil {
+			t.Fatalf("failed to introspect: %v", err)
+		}
+
+		request := observer.requests[len(observer.requests)-1]
+		if request.Header.Get("Authorization") != Redacted {
+			t.Errorf("authorization header should be redacted, got %s", request.Header.Get("Authorization"))
+		}
+		if request.Form.Get("token") != Redacted {
+			t.Errorf("token should be redacted, got %v", request.Form)
+		}
+	})
+
+	t.Run("should report failed requests", func(t *testing.T) {
+		failing := Default()
+		failing.SetServer(&Server{TokenEndpoint: "http://127.0.0.1:1/token"})
+		failing.SetObserver(observer)
+
+		if _, err := failing.GrantClientCredentials(GrantClientCredentialsOpts{}); err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+
+		response := observer.responses[len(observer.responses)-1]
+		if response.Err == nil || response.Status != 0 {
+			t.Errorf("response should carry the error, got %+v", response)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TokenRequirements are checked on the token a grant returns, so a
+// misconfigured client or authorization server fails at startup instead of
+// with a 403 on the first call to a resource server
+type TokenRequirements struct {
+	// Scopes the token must be granted. A response without scope grants the
+	// requested scope.
+	Scopes []string
+
+	// Audience the token must be issued for. It is read from the aud claim of
+	// JWT access tokens without verifying their signature, since the token
+	// came from the server directly.
+	Audience string
+
+	// MinLifetime is the shortest expires_in accepted. Tokens not saying when
+	// they expire are accepted.
+	MinLifetime time.Duration
+}
+
+// MissingScopeError is returned by grants when the token lacks a scope of
+// TokenRequirements.Scopes
+type MissingScopeError struct {
+	Missing []string
+	Granted []string
+}
+
+func (e *MissingScopeError) Error() string {
+	return fmt.Sprintf("token is missing the scopes %s, granted: %s", strings.Join(e.Missing, ", "), strings.Join(e.Granted, ", "))
+}
+
+// AudienceMismatchError is returned by grants when the token is not issued
+// for TokenRequirements.Audience
+type AudienceMismatchError struct {
+	Audience string
+	Actual   Audience // Empty when the token is opaque
+}
+
+func (e *AudienceMismatchError) Error() string {
+	if len(e.Actual) == 0 {
+		return fmt.Sprintf("token audience can't be checked for %q: access token is not a JWT with an aud claim", e.Audience)
+	}
+	return fmt.Sprintf("token is issued for %s, not %q", strings.Join(e.Actual, ", "), e.Audience)
+}
+
+// TokenLifetimeError is returned by grants when the token expires sooner than
+// TokenRequirements.MinLifetime
+type TokenLifetimeError struct {
+	Lifetime    time.Duration
+	MinLifetime time.Duration
+}
+
+func (e *TokenLifetimeError) Error() string {
+	return fmt.Sprintf("token expires in %s, expected at least %s", e.Lifetime, e.MinLifetime)
+}
+
+// check verifies a granted token. requestedScope is the scope parameter of
+// the grant.
+func (r TokenRequirements) check(token *Token, requestedScope string) error {
+	if len(r.Scopes) > 0 {
+		scope := token.Scope
+		if scope == "" {
+			scope = requestedScope
+		}
+
+		granted := strings.Fields(scope)
+		var missing []string
+		for _, required := range r.Scopes {
+			if !slices.Contains(granted, required) {
+				missing = append(missing, required)
+			}
+		}
+		if len(missing) > 0 {
+			return &MissingScopeError{Missing: missing, Granted: granted}
+		}
+	}
+
+	if r.Audience != "" {
+		audience := accessTokenAudience(token.AccessToken)
+		if !audience.Contains(r.Audience) {
+			return &AudienceMismatchError{Audience: r.Audience, Actual: audience}
+		}
+	}
+
+	if r.MinLifetime > 0 && token.ExpiresIn > 0 {
+		if lifetime := time.Duration(token.ExpiresIn) * time.Second; lifetime < r.MinLifetime {
+			return &TokenLifetimeError{Lifetime: lifetime, MinLifetime: r.MinLifetime}
+		}
+	}
+
+	return nil
+}
+
+// accessTokenAudience returns the aud claim of a JWT access token, or nil
+// for opaque tokens
+func accessTokenAudience(accessToken string) Audience {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+
+	var claims struct {
+		Audience Audience `json:"aud"`
+	}
+	if json.Unmarshal(payload, &claims) != nil {
+		return nil
+	}
+	return claims.Audience
+}
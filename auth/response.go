@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxErrorBody is how much of an error response body HTTPError keeps
+const maxErrorBody = 512
+
+// HTTPError is returned when the server answers with a status other than 2xx
+type HTTPError struct {
+	Method      string
+	Endpoint    string // Request URL, with query secrets redacted
+	StatusCode  int
+	ContentType string
+
+	// Body is the start of the response body: JSON with secrets redacted and
+	// plain text as sent, the title of HTML pages, and empty for other types
+	Body string
+
+	// OAuth error fields of a JSON error body, e.g. "invalid_client"
+	OAuthError       string
+	ErrorDescription string
+}
+
+func (e *HTTPError) Error() string {
+	message := fmt.Sprintf("%s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	switch {
+	case e.OAuthError != "" && e.ErrorDescription != "":
+		return fmt.Sprintf("%s: %s: %s", message, e.OAuthError, e.ErrorDescription)
+	case e.OAuthError != "":
+		return fmt.Sprintf("%s: %s", message, e.OAuthError)
+	case e.Body != "":
+		return fmt.Sprintf("%s: %s", message, e.Body)
+	}
+	return message
+}
+
+// readBody reads and closes the body of a response, returning an *HTTPError
+// along with the body when the status is not 2xx
+func readBody(res *http.Response) ([]byte, error) {
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return body, nil
+	}
+
+	return body, newHTTPError(res, body)
+}
+
+func newHTTPError(res *http.Response, body []byte) *HTTPError {
+	httpErr := &HTTPError{
+		StatusCode:  res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+	}
+	if res.Request != nil {
+		httpErr.Method = res.Request.Method
+		httpErr.Endpoint = redactURL(res.Request.URL)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(httpErr.ContentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var oauthErr ErrorResponse
+		if json.Unmarshal(body, &oauthErr) == nil {
+			httpErr.OAuthError = oauthErr.Error
+			httpErr.ErrorDescription = oauthErr.ErrorDescription
+		}
+		httpErr.Body = truncate(redactBody(body))
+	case mediaType == "text/html":
+		httpErr.Body = truncate(htmlTitle(body))
+	case strings.HasPrefix(mediaType, "text/"):
+		httpErr.Body = truncate(strings.TrimSpace(string(body)))
+	}
+
+	return httpErr
+}
+
+var htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// htmlTitle returns the title of an HTML error page, which names the error
+// without the markup around it
+func htmlTitle(body []byte) string {
+	match := htmlTitlePattern.FindSubmatch(body)
+	if match == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " ")
+}
+
+func truncate(s string) string {
+	if len(s) <= maxErrorBody {
+		return s
+	}
+	end := maxErrorBody
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + "..."
+}
+
+// grantError turns an OAuth error response of the token endpoint into
+// InvalidClientError or InvalidRequest, which wrap the *HTTPError
+func grantError(err error) error {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+
+	message := httpErr.ErrorDescription
+	if message == "" {
+		message = httpErr.OAuthError
+	}
+
+	switch httpErr.OAuthError {
+	case "invalid_client":
+		return &InvalidClientError{error: err, message: message}
+	case "invalid_request", "unsupported_grant_type":
+		return &InvalidRequest{error: err, message: message}
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPErrors(t *testing.T) {
+	t.Run("should fail discovery with the title of an HTML error page", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html><head><title>502 Bad\n Gateway</title></head><body><h1>nginx</h1></body></html>"))
+		}))
+		defer server.Close()
+
+		_, err := FromIssuer(server.URL)
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("expected HTTPError, got %v", err)
+		}
+		if httpErr.StatusCode != http.StatusBadGateway || httpErr.Body != "502 Bad Gateway" || httpErr.Method != "GET" {
+			t.Errorf("unexpected error %+v", httpErr)
+		}
+		if !strings.Contains(httpErr.Endpoint, "/.well-known/openid-configuration") {
+			t.Errorf("unexpected endpoint %q", httpErr.Endpoint)
+		}
+	})
+
+	t.Run("should map OAuth errors of the token endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"Client authentication failed"}`))
+		}))
+		defer server.Close()
+
+		auth := Default()
+		auth.SetServer(&Server{TokenEndpoint: server.URL})
+
+		_, err := auth.GrantClientCredentials(GrantClientCredentialsOpts{ClientID: "client", ClientSecret: "secret"})
+
+		var invalidClient *InvalidClientError
+		if !errors.As(err, &invalidClient) || err.Error() != "Client authentication failed" {
+			t.Fatalf("expected InvalidClientError, got %v", err)
+		}
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized || httpErr.OAuthError != "invalid_client" {
+			t.Errorf("expected the HTTPError to be wrapped, got %+v", httpErr)
+		}
+	})
+
+	t.Run("should not decode error pages of the introspection endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("upstream unavailable\n"))
+		}))
+		defer server.Close()
+
+		auth := Default()
+		auth.SetServer(&Server{IntrospectionEndpoint: server.URL})
+
+		response, err := auth.Introspect(IntrospectOpts{Token: "token"})
+		if response != nil {
+			t.Errorf("expected no response, got %+v", response)
+		}
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Body != "upstream unavailable" {
+			t.Fatalf("expected HTTPError with the body, got %v", err)
+		}
+		if !strings.HasSuffix(err.Error(), "503 Service Unavailable: upstream unavailable") {
+			t.Errorf("unexpected message %q", err.Error())
+		}
+	})
+
+	t.Run("should redact secrets in JSON bodies", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant","refresh_token":"secret-token"}`))
+		}))
+		defer server.Close()
+
+		auth := Default()
+		auth.SetServer(&Server{TokenEndpoint: server.URL})
+
+		_, err := auth.GrantPassword(GrantPasswordOpts{Username: "user", Password: "password"})
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("expected HTTPError, got %v", err)
+		}
+		if strings.Contains(httpErr.Body, "secret-token") || httpErr.OAuthError != "invalid_grant" {
+			t.Errorf("unexpected error %+v", httpErr)
+		}
+	})
+}
@@ -26,6 +26,8 @@ type Server struct {
 	RevocationEndpoint                                 string   `json:"revocation_endpoint"`
 	RevocationEndpointAuthMethodsSupported             []string `json:"revocation_endpoint_auth_methods_supported"`
 	RevocationEndpointAuthSigningAlgValuesSupported    []string `json:"revocation_endpoint_auth_signing_alg_values_supported"`
+	BackchannelLogoutSupported                         bool     `json:"backchannel_logout_supported"`
+	BackchannelLogoutSessionSupported                  bool     `json:"backchannel_logout_session_supported"`
 }
 
 func NewServer(metadata map[string]any) (*Server, error) {
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/fxfn/x/crypt"
+)
+
+var (
+	ErrNoSession       = errors.New("no session")
+	ErrSessionExpired  = errors.New("session expired")
+	ErrSessionTooLarge = errors.New("session does not fit in a cookie")
+)
+
+// maxCookieSize is the size browsers are guaranteed to store per cookie
+const maxCookieSize = 4096
+
+// Session is the login state stored in the session cookie
+type Session struct {
+	Principal      string         `json:"principal,omitempty"` // Who is logged in, e.g. the sub claim
+	Claims         map[string]any `json:"claims,omitempty"`
+	Token          *Token         `json:"token,omitempty"`
+	TokenExpiresAt time.Time      `json:"token_expires_at,omitzero"` // Set from Token.ExpiresIn when saved
+	IssuedAt       time.Time      `json:"issued_at"`
+	ExpiresAt      time.Time      `json:"expires_at"`
+}
+
+// TokenExpiresWithin reports whether the session's token expires within d
+func (s *Session) TokenExpiresWithin(d time.Duration) bool {
+	return !s.TokenExpiresAt.IsZero() && time.Until(s.TokenExpiresAt) < d
+}
+
+type SessionOpts struct {
+	CookieName string        `default:"session"`
+	Cipher     crypt.Cipher  // Encrypts the session, e.g. a crypt.Envelope
+	SigningKey []byte        // HMAC-SHA256 key signing the encrypted session, at least 32 bytes
+	MaxAge     time.Duration `default:"24h"`
+	Path       string        `default:"/"`
+	Domain     string
+	Insecure   bool          // Allow the cookie over plain HTTP, for local development
+	SameSite   http.SameSite `default:"lax"`
+
+	// Refresh is called by Middleware when the token expires within
+	// RefreshBefore, to replace session.Token, e.g. with a refresh token grant.
+	// The session cookie is written again when it succeeds.
+	Refresh       func(r *http.Request, session *Session) error
+	RefreshBefore time.Duration `default:"1m"`
+}
+
+// Sessions stores sessions in an encrypted, HMAC-signed cookie
+type Sessions struct {
+	opts SessionOpts
+}
+
+// NewSessions creates a cookie session store, giving web apps a complete login
+// flow: save the session after exchanging the authorization code, then read it
+// in handlers behind Middleware.
+func NewSessions(opts SessionOpts) (*Sessions, error) {
+	if opts.Cipher == nil {
+		return nil, &InvalidRequest{message: "sessions need a cipher"}
+	}
+
+	if len(opts.SigningKey) < 32 {
+		return nil, &InvalidRequest{message: "sessions need a signing key of at least 32 bytes"}
+	}
+
+	if opts.CookieName == "" {
+		opts.CookieName = "session"
+	}
+
+	if opts.MaxAge == 0 {
+		opts.MaxAge = 24 * time.Hour
+	}
+
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+
+	if opts.RefreshBefore == 0 {
+		opts.RefreshBefore = time.Minute
+	}
+
+	return &Sessions{opts: opts}, nil
+}
+
+// Save writes the session cookie. It fills IssuedAt, ExpiresAt and
+// TokenExpiresAt when they are zero.
+func (s *Sessions) Save(w http.ResponseWriter, session *Session) error {
+	now := time.Now()
+	if session.IssuedAt.IsZero() {
+		session.IssuedAt = now
+	}
+
+	if session.ExpiresAt.IsZero() {
+		session.ExpiresAt = now.Add(s.opts.MaxAge)
+	}
+
+	if session.TokenExpiresAt.IsZero() && session.Token != nil && session.Token.ExpiresIn > 0 {
+		session.TokenExpiresAt = now.Add(time.Duration(session.Token.ExpiresIn) * time.Second)
+	}
+
+	value, err := s.encode(session)
+	if err != nil {
+		return err
+	}
+
+	cookie := s.cookie(value, session.ExpiresAt)
+	if len(cookie.String()) > maxCookieSize {
+		return ErrSessionTooLarge
+	}
+
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// Load reads the session cookie of a request. It returns ErrNoSession without
+// a cookie, ErrSessionExpired for expired sessions and crypt errors for
+// cookies that were tampered with or encrypted with another key.
+func (s *Sessions) Load(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(s.opts.CookieName)
+	if err != nil {
+		return nil, ErrNoSession
+	}
+
+	session, err := s.decode(cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrSessionExpired
+	}
+
+	return session, nil
+}
+
+// Clear removes the session cookie, e.g. on logout
+func (s *Sessions) Clear(w http.ResponseWriter) {
+	cookie := s.cookie("", time.Unix(0, 0))
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+}
+
+type sessionContextKey struct{}
+
+// SessionFromContext returns the session Middleware stored in a request context
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionContextKey{}).(*Session)
+	return session, ok
+}
+
+// Middleware loads the session of every request into its context, see
+// SessionFromContext, and refreshes tokens about to expire. Requests without a
+// valid session pass through without one; invalid cookies are cleared.
+func (s *Sessions) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := s.Load(r)
+		if err != nil {
+			if !errors.Is(err, ErrNoSession) {
+				s.Clear(w)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if s.opts.Refresh != nil && session.TokenExpiresWithin(s.opts.RefreshBefore) {
+			if err := s.opts.Refresh(r, session); err == nil {
+				session.TokenExpiresAt = time.Time{}
+				s.Save(w, session)
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionContextKey{}, session)))
+	})
+}
+
+func (s *Sessions) encode(session *Session) (string, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := s.opts.Cipher.Encrypt(data)
+	if err != nil {
+		return "", err
+	}
+
+	return crypt.SignJWS(encrypted, s.opts.SigningKey, crypt.SignJWSOpts{
+		Algorithm: crypt.HS256,
+		Type:      "session",
+	})
+}
+
+func (s *Sessions) decode(value string) (*Session, error) {
+	encrypted, header, err := crypt.VerifyJWS(value, s.opts.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Alg != crypt.HS256 {
+		return nil, crypt.ErrUnsupportedAlg
+	}
+
+	data, err := s.opts.Cipher.Decrypt(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (s *Sessions) cookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.opts.CookieName,
+		Value:    value,
+		Path:     s.opts.Path,
+		Domain:   s.opts.Domain,
+		Expires:  expires,
+		Secure:   !s.opts.Insecure,
+		HttpOnly: true,
+		SameSite: s.opts.SameSite,
+	}
+}
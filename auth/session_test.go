@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fxfn/x/crypt"
+)
+
+func newTestSessions(t *testing.T, opts SessionOpts) *Sessions {
+	provider, err := crypt.NewLocalKeyProvider("test", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("failed to create key provider: %v", err)
+	}
+
+	opts.Cipher = crypt.NewEnvelope(provider)
+	opts.SigningKey = bytes.Repeat([]byte{2}, 32)
+
+	sessions, err := NewSessions(opts)
+	if err != nil {
+		t.Fatalf("failed to create sessions: %v", err)
+	}
+	return sessions
+}
+
+func requestWithCookies(res *httptest.ResponseRecorder) *http.Request {
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range res.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestSessions(t *testing.T) {
+	t.Run("should save and load a session", func(t *testing.T) {
+		sessions := newTestSessions(t, SessionOpts{})
+
+		res := httptest.NewRecorder()
+		err := sessions.Save(res, &Session{
+			Principal: "user-1",
+			Token:     &Token{AccessToken: "access", ExpiresIn: 3600},
+		})
+		if err != nil {
+			t.Fatalf("failed to save session: %v", err)
+		}
+
+		cookie := res.Result().Cookies()[0]
+		if !cookie.HttpOnly || !cookie.Secure || cookie.Name != "session" {
+			t.Errorf("unexpected cookie %v", cookie)
+		}
+		if bytes.Contains([]byte(cookie.Value), []byte("access")) {
+			t.Errorf("cookie should be encrypted")
+		}
+
+		session, err := sessions.Load(requestWithCookies(res))
+		if err != nil {
+			t.Fatalf("failed to load session: %v", err)
+		}
+		if session.Principal != "user-1" || session.Token.AccessToken != "access" {
+			t.Errorf("unexpected session %+v", session)
+		}
+		if session.TokenExpiresAt.IsZero() {
+			t.Errorf("token expiry should be set")
+		}
+	})
+
+	t.Run("should reject tampered and expired sessions", func(t *testing.T) {
+		sessions := newTestSessions(t, SessionOpts{})
+
+		res := httptest.NewRecorder()
+		sessions.Save(res, &Session{Principal: "user-1"})
+		cookie := res.Result().Cookies()[0]
+
+		req := httptest.NewRequest("GET", "/", nil)
+		tampered := []byte(cookie.Value)
+		if tampered[len(tampered)-5] == 'A' {
+			tampered[len(tampered)-5] = 'B'
+		} else {
+			tampered[len(tampered)-5] = 'A'
+		}
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: string(tampered)})
+		if _, err := sessions.Load(req); !errors.Is(err, crypt.ErrInvalidSignature) {
+			t.Errorf("expected ErrInvalidSignature, got %v", err)
+		}
+
+		res = httptest.NewRecorder()
+		sessions.Save(res, &Session{Principal: "user-1", ExpiresAt: time.Now().Add(-time.Minute)})
+		if _, err := sessions.Load(requestWithCookies(res)); !errors.Is(err, ErrSessionExpired) {
+			t.Errorf("expected ErrSessionExpired, got %v", err)
+		}
+
+		if _, err := sessions.Load(httptest.NewRequest("GET", "/", nil)); !errors.Is(err, ErrNoSession) {
+			t.Errorf("expected ErrNoSession, got %v", err)
+		}
+	})
+
+	t.Run("should require a cipher and a signing key", func(t *testing.T) {
+		if _, err := NewSessions(SessionOpts{SigningKey: make([]byte, 32)}); err == nil {
+			t.Errorf("expected an error without a cipher")
+		}
+	})
+
+	t.Run("middleware should load and refresh the session", func(t *testing.T) {
+		sessions := newTestSessions(t, SessionOpts{
+			Refresh: func(r *http.Request, session *Session) error {
+				session.Token = &Token{AccessToken: "refreshed", ExpiresIn: 3600}
+				return nil
+			},
+		})
+
+		res := httptest.NewRecorder()
+		sessions.Save(res, &Session{
+			Principal: "user-1",
+			Token:     &Token{AccessToken: "access", ExpiresIn: 10},
+		})
+
+		var principal string
+		handler := sessions.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if session, ok := SessionFromContext(r.Context()); ok {
+				principal = session.Principal
+			}
+		}))
+
+		refreshed := httptest.NewRecorder()
+		handler.ServeHTTP(refreshed, requestWithCookies(res))
+		if principal != "user-1" {
+			t.Fatalf("session should be in the context")
+		}
+
+		session, err := sessions.Load(requestWithCookies(refreshed))
+		if err != nil {
+			t.Fatalf("refreshed cookie should be written: %v", err)
+		}
+		if session.Token.AccessToken != "refreshed" || session.TokenExpiresWithin(time.Minute) {
+			t.Errorf("token should be refreshed, got %+v", session.Token)
+		}
+	})
+}
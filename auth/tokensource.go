@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+type TokenSourceOpts struct {
+	// ExpiryDelta treats tokens as expired this long before they do, so they
+	// are not sent just as they expire
+	ExpiryDelta time.Duration `default:"10s"`
+
+	// PrefetchBefore starts fetching the next token in the background when the
+	// current one expires within it, so callers keep getting the current token
+	// instead of waiting. Negative disables prefetching.
+	PrefetchBefore time.Duration `default:"1m"`
+
+	// Metrics is told whether each call to Token was served from the cache.
+	// ClientCredentialsTokenSource defaults it to the metrics of the Auth.
+	Metrics Metrics
+}
+
+// TokenSource caches a token and fetches a new one when it expires. Callers
+// asking for a token while one is being fetched wait for that fetch instead
+// of starting their own, so a burst of requests after expiry makes a single
+// token request.
+type TokenSource struct {
+	fetch func() (*Token, error)
+	opts  TokenSourceOpts
+
+	mu        sync.Mutex
+	token     *Token
+	expiresAt time.Time // Zero when the token did not say when it expires
+	inflight  *tokenFetch
+}
+
+// tokenFetch is a token request other callers can wait for
+type tokenFetch struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// NewTokenSource creates a TokenSource getting its tokens from fetch, e.g. a
+// grant. Tokens without expires_in are kept until Invalidate.
+func NewTokenSource(fetch func() (*Token, error), opts TokenSourceOpts) *TokenSource {
+	if opts.ExpiryDelta == 0 {
+		opts.ExpiryDelta = 10 * time.Second
+	}
+
+	if opts.PrefetchBefore == 0 {
+		opts.PrefetchBefore = time.Minute
+	}
+
+	return &TokenSource{fetch: fetch, opts: opts}
+}
+
+// ClientCredentialsTokenSource creates a TokenSource using the client
+// credentials grant, for service-to-service calls
+func (a *Auth) ClientCredentialsTokenSource(grant GrantClientCredentialsOpts, opts TokenSourceOpts) *TokenSource {
+	if opts.Metrics == nil {
+		opts.Metrics = a.metrics
+	}
+
+	return NewTokenSource(func() (*Token, error) {
+		return a.GrantClientCredentials(grant)
+	}, opts)
+}
+
+// Token returns the cached token while it is valid, and otherwise fetches a
+// new one, waiting for a fetch already in progress
+func (s *TokenSource) Token() (*Token, error) {
+	s.mu.Lock()
+	now := time.Now()
+
+	if s.token != nil && s.valid(now) {
+		token := s.token
+		if s.opts.PrefetchBefore > 0 && !s.expiresAt.IsZero() && s.expiresAt.Sub(now) < s.opts.PrefetchBefore {
+			s.start()
+		}
+		s.mu.Unlock()
+		s.recordCache(true)
+		return token, nil
+	}
+
+	fetch := s.start()
+	s.mu.Unlock()
+	s.recordCache(false)
+
+	<-fetch.done
+	return fetch.token, fetch.err
+}
+
+// Invalidate drops the cached token, e.g. after the resource server rejected
+// it, so the next call to Token fetches a new one
+func (s *TokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+	s.expiresAt = time.Time{}
+}
+
+func (s *TokenSource) recordCache(hit bool) {
+	if s.opts.Metrics != nil {
+		s.opts.Metrics.TokenCache(hit)
+	}
+}
+
+// valid reports whether the cached token can still be used
+func (s *TokenSource) valid(now time.Time) bool {
+	return s.expiresAt.IsZero() || now.Add(s.opts.ExpiryDelta).Before(s.expiresAt)
+}
+
+// start returns the fetch in progress, starting one when there is none. It
+// must be called with s.mu held.
+func (s *TokenSource) start() *tokenFetch {
+	if s.inflight != nil {
+		return s.inflight
+	}
+
+	fetch := &tokenFetch{done: make(chan struct{})}
+	s.inflight = fetch
+
+	go func() {
+		start := time.Now()
+		token, err := s.fetch()
+
+		s.mu.Lock()
+		if err == nil {
+			s.token = token
+			s.expiresAt = time.Time{}
+			if token.ExpiresIn > 0 {
+				s.expiresAt = start.Add(time.Duration(token.ExpiresIn) * time.Second)
+			}
+		}
+		s.inflight = nil
+		s.mu.Unlock()
+
+		fetch.token, fetch.err = token, err
+		close(fetch.done)
+	}()
+
+	return fetch
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenSource(t *testing.T) {
+	t.Run("should make one token request for concurrent callers", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+		}))
+		defer server.Close()
+
+		auth := Default()
+		auth.SetServer(&Server{TokenEndpoint: server.URL})
+		source := auth.ClientCredentialsTokenSource(GrantClientCredentialsOpts{ClientID: "client"}, TokenSourceOpts{})
+
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := source.Token()
+				if err != nil || token.AccessToken != "token" {
+					t.Errorf("unexpected token %+v, %v", token, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if _, err := source.Token(); err != nil {
+			t.Fatalf("failed to get cached token: %v", err)
+		}
+		if n := requests.Load(); n != 1 {
+			t.Errorf("expected 1 token request, got %d", n)
+		}
+	})
+
+	t.Run("should prefetch in the background before expiry", func(t *testing.T) {
+		var fetches atomic.Int32
+		prefetching := make(chan struct{})
+		release := make(chan struct{})
+		source := NewTokenSource(func() (*Token, error) {
+			if fetches.Add(1) > 1 {
+				close(prefetching)
+				<-release
+			}
+			return &Token{AccessToken: "token", ExpiresIn: 30}, nil
+		}, TokenSourceOpts{PrefetchBefore: time.Minute})
+
+		if _, err := source.Token(); err != nil {
+			t.Fatalf("failed to get token: %v", err)
+		}
+
+		// The token expires within PrefetchBefore, so this starts a fetch
+		// but returns the current token without waiting for it
+		if _, err := source.Token(); err != nil {
+			t.Fatalf("failed to get token: %v", err)
+		}
+		if _, err := source.Token(); err != nil {
+			t.Fatalf("failed to get token: %v", err)
+		}
+		<-prefetching
+		close(release)
+
+		if n := fetches.Load(); n != 2 {
+			t.Errorf("expected 2 fetches, got %d", n)
+		}
+	})
+
+	t.Run("should return errors and retry on the next call", func(t *testing.T) {
+		fail := true
+		source := NewTokenSource(func() (*Token, error) {
+			if fail {
+				return nil, errors.New("unavailable")
+			}
+			return &Token{AccessToken: "token"}, nil
+		}, TokenSourceOpts{})
+
+		if _, err := source.Token(); err == nil {
+			t.Fatalf("expected an error")
+		}
+
+		fail = false
+		token, err := source.Token()
+		if err != nil || token.AccessToken != "token" {
+			t.Errorf("unexpected token %+v, %v", token, err)
+		}
+	})
+
+	t.Run("should fetch again after Invalidate", func(t *testing.T) {
+		var fetches int
+		source := NewTokenSource(func() (*Token, error) {
+			fetches++
+			return &Token{AccessToken: "token"}, nil
+		}, TokenSourceOpts{})
+
+		source.Token()
+		source.Token()
+		source.Invalidate()
+		source.Token()
+
+		if fetches != 2 {
+			t.Errorf("expected 2 fetches, got %d", fetches)
+		}
+	})
+}
@@ -3,6 +3,10 @@ package auth
 type Auth struct {
 	endpoint string
 	server   *Server
+	observer Observer
+	metrics  Metrics
+	dpop     *DPoPKey
+	jwks     keySet // Keys of the server's jwks_uri, fetched when first needed
 }
 
 type ErrorResponse struct {
@@ -0,0 +1,172 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidArmor = errors.New("invalid armored data")
+
+// ArmorType is the label of the BEGIN and END lines of armored data
+const ArmorType = "ENCRYPTED DATA"
+
+const (
+	armorHeaderAlgorithm = "Algorithm"
+	armorHeaderKeyID     = "Key-Id"
+	armorHeaderCreatedAt = "Created-At"
+
+	envelopeAlgorithm = "ENVELOPE-A256GCM"
+)
+
+// Armored is ciphertext with the headers needed to decrypt it, encoded by
+// String as PEM-like ASCII armor that can be kept in config files and diffed
+// in git:
+//
+//	-----BEGIN ENCRYPTED DATA-----
+//	Algorithm: ENVELOPE-A256GCM
+//	Created-At: 2024-05-01T12:00:00Z
+//	Key-Id: config-2024
+//
+//	AQALY29uZmlnLTIwMjQAKM1x...
+//	-----END ENCRYPTED DATA-----
+//
+// The headers are informational and not authenticated, the key id used for
+// decryption is the one inside the ciphertext.
+type Armored struct {
+	Algorithm string
+	KeyID     string
+	CreatedAt time.Time
+	Data      []byte
+}
+
+// String encodes a as ASCII armor. Headers are sorted and the base64 body
+// wrapped at 64 columns, so the output is stable.
+func (a *Armored) String() string {
+	headers := map[string]string{}
+	if a.Algorithm != "" {
+		headers[armorHeaderAlgorithm] = a.Algorithm
+	}
+	if a.KeyID != "" {
+		headers[armorHeaderKeyID] = a.KeyID
+	}
+	if !a.CreatedAt.IsZero() {
+		headers[armorHeaderCreatedAt] = a.CreatedAt.UTC().Format(time.RFC3339)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:    ArmorType,
+		Headers: headers,
+		Bytes:   a.Data,
+	}))
+}
+
+// ParseArmored decodes the output of Armored.String. Whitespace around the
+// armor is ignored, anything else before or after it is an error.
+func ParseArmored(s string) (*Armored, error) {
+	block, rest := pem.Decode([]byte(strings.TrimSpace(s)))
+	if block == nil {
+		return nil, ErrInvalidArmor
+	}
+	if block.Type != ArmorType {
+		return nil, fmt.Errorf("%w: unexpected type %q", ErrInvalidArmor, block.Type)
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, fmt.Errorf("%w: trailing data", ErrInvalidArmor)
+	}
+
+	armored := &Armored{
+		Algorithm: block.Headers[armorHeaderAlgorithm],
+		KeyID:     block.Headers[armorHeaderKeyID],
+		Data:      block.Bytes,
+	}
+
+	if createdAt, ok := block.Headers[armorHeaderCreatedAt]; ok {
+		t, err := time.Parse(time.RFC3339, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s header", ErrInvalidArmor, armorHeaderCreatedAt)
+		}
+		armored.CreatedAt = t
+	}
+
+	return armored, nil
+}
+
+// EncryptArmored encrypts data like Encrypt and returns it as ASCII armor
+func (c *Crypt) EncryptArmored(data []byte) (string, error) {
+	encrypted, err := c.Encrypt(data)
+	if err != nil {
+		return "", err
+	}
+
+	armored := &Armored{
+		Algorithm: c.algorithm,
+		CreatedAt: time.Now(),
+		Data:      encrypted,
+	}
+	return armored.String(), nil
+}
+
+// DecryptArmored decrypts the output of EncryptArmored. It fails with
+// ErrUnsupportedAlg when the armor names a different algorithm.
+func (c *Crypt) DecryptArmored(s string) ([]byte, error) {
+	armored, err := ParseArmored(s)
+	if err != nil {
+		return nil, err
+	}
+	if armored.Algorithm != "" && c.algorithm != "" && !strings.EqualFold(armored.Algorithm, c.algorithm) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, armored.Algorithm)
+	}
+	return c.Decrypt(armored.Data)
+}
+
+// EncryptArmored encrypts data like Encrypt and returns it as ASCII armor
+// with the key id of the data key
+func (e *Envelope) EncryptArmored(data []byte) (string, error) {
+	encrypted, err := e.Encrypt(data)
+	if err != nil {
+		return "", err
+	}
+
+	keyID, err := envelopeKeyID(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	armored := &Armored{
+		Algorithm: envelopeAlgorithm,
+		KeyID:     keyID,
+		CreatedAt: time.Now(),
+		Data:      encrypted,
+	}
+	return armored.String(), nil
+}
+
+// DecryptArmored decrypts the output of EncryptArmored. It fails with
+// ErrUnsupportedAlg when the armor names a different algorithm.
+func (e *Envelope) DecryptArmored(s string) ([]byte, error) {
+	armored, err := ParseArmored(s)
+	if err != nil {
+		return nil, err
+	}
+	if armored.Algorithm != "" && armored.Algorithm != envelopeAlgorithm {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, armored.Algorithm)
+	}
+	return e.Decrypt(armored.Data)
+}
+
+// envelopeKeyID reads the key id from the header of Envelope output
+func envelopeKeyID(data []byte) (string, error) {
+	if len(data) < 3 || data[0] != envelopeVersion {
+		return "", ErrInvalidEnvelope
+	}
+	n := int(binary.BigEndian.Uint16(data[1:]))
+	if len(data) < 3+n {
+		return "", ErrInvalidEnvelope
+	}
+	return string(data[3 : 3+n]), nil
+}
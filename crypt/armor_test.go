@@ -0,0 +1,109 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArmored(t *testing.T) {
+	provider, err := NewLocalKeyProvider("config-1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	envelope := NewEnvelope(provider)
+
+	t.Run("envelope round trip", func(t *testing.T) {
+		armored, err := envelope.EncryptArmored([]byte("hello, world"))
+		if err != nil {
+			t.Fatalf("failed to encrypt: %v", err)
+		}
+
+		if !strings.HasPrefix(armored, "-----BEGIN ENCRYPTED DATA-----\n") {
+			t.Fatalf("unexpected armor: %s", armored)
+		}
+		for _, line := range strings.Split(armored, "\n") {
+			if len(line) > 64 {
+				t.Fatalf("line should be wrapped at 64 columns: %q", line)
+			}
+		}
+
+		parsed, err := ParseArmored(armored)
+		if err != nil {
+			t.Fatalf("failed to parse: %v", err)
+		}
+		if parsed.Algorithm != envelopeAlgorithm || parsed.KeyID != "config-1" {
+			t.Fatalf("unexpected headers: %+v", parsed)
+		}
+		if time.Since(parsed.CreatedAt) > time.Minute {
+			t.Fatalf("unexpected created at: %v", parsed.CreatedAt)
+		}
+
+		decrypted, err := envelope.DecryptArmored("\n  " + armored + "\n")
+		if err != nil {
+			t.Fatalf("failed to decrypt: %v", err)
+		}
+		if string(decrypted) != "hello, world" {
+			t.Fatalf("decrypted data should be the same as original")
+		}
+	})
+
+	t.Run("crypt round trip", func(t *testing.T) {
+		c := New(NodePBKDF2SHA256.Opts("password", "salt", "1234567890123456"))
+		armored, err := c.EncryptArmored([]byte("hello, world"))
+		if err != nil {
+			t.Fatalf("failed to encrypt: %v", err)
+		}
+		if !strings.Contains(armored, "Algorithm: AES-256-CBC\n") {
+			t.Fatalf("algorithm header missing: %s", armored)
+		}
+
+		decrypted, err := c.DecryptArmored(armored)
+		if err != nil {
+			t.Fatalf("failed to decrypt: %v", err)
+		}
+		if string(decrypted) != "hello, world" {
+			t.Fatalf("decrypted data should be the same as original")
+		}
+
+		if _, err := envelope.DecryptArmored(armored); !errors.Is(err, ErrUnsupportedAlg) {
+			t.Fatalf("expected ErrUnsupportedAlg, got %v", err)
+		}
+	})
+
+	t.Run("stable encoding", func(t *testing.T) {
+		armored := &Armored{
+			Algorithm: "A256GCM",
+			KeyID:     "k1",
+			CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			Data:      []byte("data"),
+		}
+		expected := "-----BEGIN ENCRYPTED DATA-----\n" +
+			"Algorithm: A256GCM\n" +
+			"Created-At: 2024-05-01T12:00:00Z\n" +
+			"Key-Id: k1\n" +
+			"\n" +
+			"ZGF0YQ==\n" +
+			"-----END ENCRYPTED DATA-----\n"
+		if armored.String() != expected {
+			t.Fatalf("expected %q, got %q", expected, armored.String())
+		}
+	})
+
+	t.Run("invalid armor", func(t *testing.T) {
+		valid := (&Armored{KeyID: "k1", Data: []byte("data")}).String()
+		for name, input := range map[string]string{
+			"empty":         "",
+			"not armored":   "ZGF0YQ==",
+			"wrong type":    strings.ReplaceAll(valid, ArmorType, "PRIVATE KEY"),
+			"trailing data": valid + "garbage",
+			"bad date":      strings.Replace(valid, "Key-Id: k1", "Created-At: yesterday", 1),
+		} {
+			if _, err := ParseArmored(input); !errors.Is(err, ErrInvalidArmor) {
+				t.Errorf("%s: expected ErrInvalidArmor, got %v", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package crypt
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// chunkFunc seals or opens one chunk of a stream
+type chunkFunc func(counter uint32, last bool, chunk []byte) ([]byte, error)
+
+// progressFrom adapts a ProgressFunc to the per-chunk byte counts of
+// processChunks, starting at offset
+func progressFrom(progress ProgressFunc, offset int64) func(n int) {
+	if progress == nil {
+		return nil
+	}
+
+	processed := offset
+	return func(n int) {
+		processed += int64(n)
+		progress(processed, -1)
+	}
+}
+
+// processChunks reads r in chunks of size bytes, transforms them and writes
+// the results to w in order. The last chunk is marked so truncation is
+// detected; when r is empty at a chunk boundary truncated is returned, or an
+// empty last chunk is transformed when truncated is nil.
+//
+// Every chunk uses its own nonce, so with workers > 1 chunks are transformed
+// concurrently while a single goroutine writes them in order. At most
+// 2*workers chunks are held in memory. Progress is reported from one
+// goroutine at a time.
+func processChunks(w io.Writer, r io.Reader, size, workers int, truncated error, transform chunkFunc, progress func(n int)) error {
+	br := bufio.NewReader(r)
+
+	// read returns the next chunk and whether it is the last one
+	read := func(buf []byte) ([]byte, bool, error) {
+		n, err := io.ReadFull(br, buf)
+		switch {
+		case err == io.EOF:
+			if truncated != nil {
+				return nil, false, truncated
+			}
+			return buf[:0], true, nil
+		case err == io.ErrUnexpectedEOF:
+			return buf[:n], true, nil
+		case err != nil:
+			return nil, false, err
+		}
+
+		if _, err := br.Peek(1); err == io.EOF {
+			return buf[:n], true, nil
+		}
+		return buf[:n], false, nil
+	}
+
+	if workers <= 1 {
+		buf := make([]byte, size)
+		for counter := uint32(0); ; counter++ {
+			chunk, last, err := read(buf)
+			if err != nil {
+				return err
+			}
+
+			out, err := transform(counter, last, chunk)
+			if err != nil {
+				return err
+			}
+
+			if _, err := w.Write(out); err != nil {
+				return err
+			}
+
+			if progress != nil {
+				progress(len(chunk))
+			}
+
+			if last {
+				return nil
+			}
+		}
+	}
+
+	type result struct {
+		out []byte
+		err error
+	}
+
+	type job struct {
+		counter uint32
+		last    bool
+		chunk   []byte
+		result  chan result
+	}
+
+	jobs := make(chan *job, workers)
+	ordered := make(chan *job, workers)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				out, err := transform(j.counter, j.last, j.chunk)
+				j.result <- result{out, err}
+			}
+		}()
+	}
+
+	// the writer drains every job, even after a failure, so no goroutine blocks
+	var failed atomic.Bool
+	writeErr := make(chan error, 1)
+	go func() {
+		var first error
+		for j := range ordered {
+			res := <-j.result
+			if first != nil {
+				continue
+			}
+
+			if res.err == nil {
+				_, res.err = w.Write(res.out)
+			}
+			if res.err != nil {
+				first = res.err
+				failed.Store(true)
+				continue
+			}
+
+			if progress != nil {
+				progress(len(j.chunk))
+			}
+		}
+		writeErr <- first
+	}()
+
+	var readErr error
+	for counter := uint32(0); !failed.Load(); counter++ {
+		chunk, last, err := read(make([]byte, size))
+		if err != nil {
+			readErr = err
+			break
+		}
+
+		j := &job{counter: counter, last: last, chunk: chunk, result: make(chan result, 1)}
+		ordered <- j
+		jobs <- j
+
+		if last {
+			break
+		}
+	}
+
+	close(jobs)
+	close(ordered)
+	wg.Wait()
+
+	if err := <-writeErr; err != nil {
+		return err
+	}
+	return readErr
+}
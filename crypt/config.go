@@ -0,0 +1,86 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadEncryptedConfig decrypts a configuration file written by
+// SaveEncryptedConfig and unmarshals it into target, so secrets such as
+// database passwords stay encrypted at rest:
+//
+//	var config AppConfig
+//	err := crypt.LoadEncryptedConfig("config.yaml.enc", &config, crypt.FileOpts{Passphrase: os.Getenv("CONFIG_KEY")})
+//
+// The file is the authenticated format of EncryptFile, so a tampered or
+// truncated file returns ErrIntegrityCheckFailed and target is left alone.
+// Files named *.yaml or *.yml, optionally followed by another extension such
+// as .enc, are YAML, all others JSON.
+func LoadEncryptedConfig(path string, target any, opts FileOpts) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var plaintext bytes.Buffer
+	if err := DecryptStream(&plaintext, in, opts); err != nil {
+		return err
+	}
+
+	if isYAMLConfig(path) {
+		return yaml.Unmarshal(plaintext.Bytes(), target)
+	}
+	return json.Unmarshal(plaintext.Bytes(), target)
+}
+
+// SaveEncryptedConfig marshals config as JSON or YAML, by the name of path
+// like LoadEncryptedConfig, and writes it encrypted. The file is replaced
+// only once it is written completely, and is only readable by its owner.
+func SaveEncryptedConfig(path string, config any, opts FileOpts) error {
+	var plaintext []byte
+	var err error
+	if isYAMLConfig(path) {
+		plaintext, err = yaml.Marshal(config)
+	} else {
+		plaintext, err = json.MarshalIndent(config, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := EncryptStream(tmp, bytes.NewReader(plaintext), opts); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// isYAMLConfig reports whether a config file name has a .yaml or .yml
+// extension, before a trailing one such as .enc
+func isYAMLConfig(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for ext := filepath.Ext(name); ext != ""; ext = filepath.Ext(name) {
+		if ext == ".yaml" || ext == ".yml" {
+			return true
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	return false
+}
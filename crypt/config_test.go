@@ -0,0 +1,79 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testConfig struct {
+	Database struct {
+		Host     string `json:"host" yaml:"host"`
+		Password string `json:"password" yaml:"password"`
+	} `json:"database" yaml:"database"`
+	Port int `json:"port" yaml:"port"`
+}
+
+func TestEncryptedConfig(t *testing.T) {
+	opts := FileOpts{Passphrase: "secret", Iterations: 1000}
+	var config testConfig
+	config.Database.Host = "db.internal"
+	config.Database.Password = "hunter2"
+	config.Port = 8080
+
+	for _, name := range []string{"config.json.enc", "config.yaml.enc", "config.yml"} {
+		t.Run(name+" should round trip", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := SaveEncryptedConfig(path, config, opts); err != nil {
+				t.Fatalf("failed to save: %v", err)
+			}
+
+			var loaded testConfig
+			if err := LoadEncryptedConfig(path, &loaded, opts); err != nil {
+				t.Fatalf("failed to load: %v", err)
+			}
+			if loaded != config {
+				t.Errorf("loaded config should be %+v, got %+v", config, loaded)
+			}
+		})
+	}
+
+	t.Run("should keep secrets encrypted at rest", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json.enc")
+		SaveEncryptedConfig(path, config, opts)
+
+		data, _ := os.ReadFile(path)
+		if string(data) == "" || bytes.Contains(data, []byte("hunter2")) {
+			t.Errorf("file should not contain the password")
+		}
+	})
+
+	t.Run("should reject tampered files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json.enc")
+		SaveEncryptedConfig(path, config, opts)
+
+		data, _ := os.ReadFile(path)
+		data[len(data)-1] ^= 1
+		os.WriteFile(path, data, 0o600)
+
+		var loaded testConfig
+		if err := LoadEncryptedConfig(path, &loaded, opts); !errors.Is(err, ErrIntegrityCheckFailed) {
+			t.Errorf("error should be ErrIntegrityCheckFailed, got %v", err)
+		}
+		if loaded.Port != 0 {
+			t.Errorf("target should be left alone")
+		}
+	})
+
+	t.Run("should reject a wrong passphrase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json.enc")
+		SaveEncryptedConfig(path, config, opts)
+
+		var loaded testConfig
+		if err := LoadEncryptedConfig(path, &loaded, FileOpts{Passphrase: "wrong"}); !errors.Is(err, ErrIntegrityCheckFailed) {
+			t.Errorf("error should be ErrIntegrityCheckFailed, got %v", err)
+		}
+	})
+}
@@ -1,18 +1,34 @@
 package crypt
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
+	"fmt"
+	"runtime"
+	"sync"
 )
 
 type CryptOpts struct {
-	IV         string
-	Passphrase string
-	Salt       string
-	Algorithm  string `default:"AES-256-CBC"`
-	Digest     string `default:"sha1"`
-	KeySize    int    `default:"256"`
-	Iterations int    `default:"1000"`
+	IV            string
+	Passphrase    string
+	Salt          string
+	Algorithm     string    `default:"AES-256-CBC"` // "AES-256-CBC", or AlgorithmAESCTR / AlgorithmAESCFB with HMAC
+	Digest        string    `default:"sha1"`
+	KeySize       int       `default:"256"`
+	Iterations    int       `default:"1000"`
+	KeyDerivation string    `default:"pbkdf2"` // "pbkdf2" or "evp_bytes_to_key"
+	KeyCache      *KeyCache // Reuses keys derived before with the same options (optional)
+
+	Secrets          SecretsProvider // Provides the passphrase and salt instead (optional)
+	PassphraseSecret string          // Name of the passphrase in Secrets
+	SaltSecret       string          // Name of the salt in Secrets
+}
+
+// Cipher is implemented by Crypt and Envelope so callers can swap one for the other
+type Cipher interface {
+	Encrypt(data []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
 }
 
 type Crypt struct {
@@ -24,31 +40,80 @@ type Crypt struct {
 	iterations int
 }
 
+// New creates a Crypt, panicking when the key can't be derived or the
+// secrets can't be read. Use NewE to handle the error instead.
 func New(opts CryptOpts) *Crypt {
+	c, err := NewE(opts)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
 
-	key, err := createKey(
-		opts.Passphrase,
-		opts.Salt,
-		opts.Iterations,
-		opts.KeySize,
-		opts.Digest,
-	)
+// NewE creates a Crypt. With opts.Secrets, the passphrase and salt are read
+// from it by the names in PassphraseSecret and SaltSecret.
+func NewE(opts CryptOpts) (*Crypt, error) {
+	opts, err := opts.withSecrets(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
+	var key, derivedIV []byte
+	if opts.KeyCache != nil {
+		key, derivedIV, err = opts.KeyCache.derive(opts)
+	} else {
+		key, derivedIV, err = deriveKey(opts)
+	}
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	// the legacy scheme derives the IV too, unless one was given explicitly
+	iv := opts.IV
+	if iv == "" && derivedIV != nil {
+		iv = string(derivedIV)
 	}
 
 	return &Crypt{
-		iv:         opts.IV,
+		iv:         iv,
 		algorithm:  opts.Algorithm,
 		digest:     opts.Digest,
 		keySize:    opts.KeySize,
 		iterations: opts.Iterations,
 		key:        key,
+	}, nil
+}
+
+// deriveKey derives the key of opts, and the IV for the legacy scheme
+func deriveKey(opts CryptOpts) ([]byte, []byte, error) {
+	if opts.KeyDerivation == KeyDerivationEVPBytesToKey {
+		return evpBytesToKey(
+			opts.Passphrase,
+			opts.Salt,
+			opts.Iterations,
+			opts.KeySize,
+			opts.Digest,
+		)
 	}
+
+	key, err := createKey(
+		opts.Passphrase,
+		opts.Salt,
+		opts.Iterations,
+		opts.KeySize,
+		opts.Digest,
+	)
+	return key, nil, err
 }
 
 func (c *Crypt) Encrypt(data []byte) ([]byte, error) {
+	if mode := etmMode(c.algorithm); mode != "" {
+		return c.encryptThenMAC(mode, data)
+	}
+
+	if len(c.iv) != aes.BlockSize {
+		return nil, fmt.Errorf("IV must be %d bytes, got %d", aes.BlockSize, len(c.iv))
+	}
 
 	block, err := aes.NewCipher(c.key)
 	if err != nil {
@@ -65,14 +130,33 @@ func (c *Crypt) Encrypt(data []byte) ([]byte, error) {
 }
 
 func (c *Crypt) Decrypt(data []byte) ([]byte, error) {
+	if mode := etmMode(c.algorithm); mode != "" {
+		return c.decryptThenMAC(mode, data)
+	}
+
+	// Checked before splitting the work: CryptBlocks panics on partial blocks,
+	// and a panic in a decryptParallel goroutine can't be recovered
+	if len(c.iv) != aes.BlockSize {
+		return nil, fmt.Errorf("IV must be %d bytes, got %d", aes.BlockSize, len(c.iv))
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("ciphertext is not a multiple of the block size")
+	}
+
 	block, err := aes.NewCipher(c.key)
 	if err != nil {
 		return nil, err
 	}
 
-	blockMode := cipher.NewCBCDecrypter(block, []byte(c.iv))
 	decrypted := make([]byte, len(data))
-	blockMode.CryptBlocks(decrypted, data)
+	if len(data) >= parallelCBCThreshold && runtime.GOMAXPROCS(0) > 1 {
+		if err := c.decryptParallel(decrypted, data); err != nil {
+			return nil, err
+		}
+	} else {
+		blockMode := cipher.NewCBCDecrypter(block, []byte(c.iv))
+		blockMode.CryptBlocks(decrypted, data)
+	}
 
 	// Remove PKCS7 padding
 	unpadded, err := pkcs7Unpad(decrypted)
@@ -82,3 +166,42 @@ func (c *Crypt) Decrypt(data []byte) ([]byte, error) {
 
 	return unpadded, nil
 }
+
+// parallelCBCThreshold is the payload size from which Decrypt splits the work
+// across CPUs. CBC encryption chains every block to the previous ciphertext
+// and can't be split; use EncryptStream with Workers for large payloads.
+const parallelCBCThreshold = 1 << 20
+
+// decryptParallel decrypts CBC segments concurrently. Each plaintext block
+// only depends on its ciphertext block and the one before it, so a segment
+// can be decrypted with the last ciphertext block of the previous segment as IV.
+func (c *Crypt) decryptParallel(dst, src []byte) error {
+	segments := runtime.GOMAXPROCS(0)
+	blocks := len(src) / aes.BlockSize
+	perSegment := (blocks + segments - 1) / segments * aes.BlockSize
+
+	var wg sync.WaitGroup
+	errs := make(chan error, segments)
+	for start := 0; start < len(src); start += perSegment {
+		end := min(start+perSegment, len(src))
+		iv := []byte(c.iv)
+		if start > 0 {
+			iv = src[start-aes.BlockSize : start]
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			block, err := aes.NewCipher(c.key)
+			if err != nil {
+				errs <- err
+				return
+			}
+			cipher.NewCBCDecrypter(block, iv).CryptBlocks(dst[start:end], src[start:end])
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
@@ -85,3 +85,44 @@ func TestEncryptDecrypt(t *testing.T) {
 		t.Fatalf("decrypted data should be the same as original")
 	}
 }
+
+func TestEncryptDeterministic(t *testing.T) {
+	crypt := New(CryptOpts{
+		Passphrase: "password",
+		Salt:       "salt",
+		IV:         "1234567890123456",
+		Algorithm:  "AES-256-CBC",
+		Digest:     "sha1",
+		KeySize:    256,
+		Iterations: 1000,
+	})
+
+	data := []byte("user@example.com")
+	first, err := crypt.EncryptDeterministic(data)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+
+	second, err := crypt.EncryptDeterministic(data)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+
+	if first.String() != second.String() {
+		t.Fatalf("deterministic ciphertexts should be equal")
+	}
+
+	decrypted, err := crypt.DecryptDeterministic(first)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %v", err)
+	}
+
+	if string(decrypted) != string(data) {
+		t.Fatalf("decrypted data should be the same as original")
+	}
+
+	first[len(first)-1] ^= 0xff
+	if _, err := crypt.DecryptDeterministic(first); err != ErrIntegrityCheckFailed {
+		t.Fatalf("expected integrity check failure, got %v", err)
+	}
+}
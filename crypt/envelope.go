@@ -0,0 +1,192 @@
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidEnvelope = errors.New("invalid envelope")
+
+// DataKey is a data encryption key issued by a KeyProvider. Plaintext is
+// used to encrypt the payload and then discarded, Encrypted is stored
+// alongside the ciphertext.
+type DataKey struct {
+	KeyID     string
+	Plaintext []byte
+	Encrypted []byte
+}
+
+// KeyProvider issues and unwraps data keys. Implementations can be backed by
+// AWS KMS, GCP KMS, Vault transit and so on; LocalKeyProvider wraps keys with
+// a local AES key. Envelope copies the data keys it is given before clearing
+// them, so providers may cache and return the same slices again.
+type KeyProvider interface {
+	GenerateDataKey(ctx context.Context) (*DataKey, error)
+	Decrypt(ctx context.Context, keyID string, encrypted []byte) ([]byte, error)
+}
+
+// LocalKeyProvider wraps data keys with a local key encryption key using
+// AES key wrap (RFC 3394)
+type LocalKeyProvider struct {
+	keyID string
+	kek   []byte
+}
+
+// NewLocalKeyProvider creates a LocalKeyProvider. kek must be 16, 24 or 32 bytes.
+func NewLocalKeyProvider(keyID string, kek []byte) (*LocalKeyProvider, error) {
+	switch len(kek) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%w: key encryption key must be 16, 24 or 32 bytes", ErrInvalidKey)
+	}
+
+	return &LocalKeyProvider{keyID: keyID, kek: kek}, nil
+}
+
+func (p *LocalKeyProvider) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	plaintext := make([]byte, 32)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := aesKeyWrap(p.kek, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataKey{
+		KeyID:     p.keyID,
+		Plaintext: plaintext,
+		Encrypted: encrypted,
+	}, nil
+}
+
+func (p *LocalKeyProvider) Decrypt(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	if keyID != p.keyID {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidKey, keyID)
+	}
+
+	return aesKeyUnwrap(p.kek, encrypted)
+}
+
+// Envelope encrypts every payload with a fresh data key from a KeyProvider
+// (AES-256-GCM) and stores the wrapped data key in the output. It has the
+// same Encrypt/Decrypt signatures as Crypt.
+//
+// Output layout:
+//
+//	version        1 byte
+//	key id length  2 bytes big endian, followed by the key id
+//	key length     2 bytes big endian, followed by the encrypted data key
+//	nonce          12 bytes
+//	ciphertext     remaining bytes, including the GCM tag
+type Envelope struct {
+	provider KeyProvider
+}
+
+const envelopeVersion = 1
+
+func NewEnvelope(provider KeyProvider) *Envelope {
+	return &Envelope{provider: provider}
+}
+
+func (e *Envelope) Encrypt(data []byte) ([]byte, error) {
+	return e.EncryptContext(context.Background(), data)
+}
+
+func (e *Envelope) Decrypt(data []byte) ([]byte, error) {
+	return e.DecryptContext(context.Background(), data)
+}
+
+func (e *Envelope) EncryptContext(ctx context.Context, data []byte) ([]byte, error) {
+	dataKey, err := e.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := bytes.Clone(dataKey.Plaintext)
+	defer clear(key)
+
+	if len(dataKey.KeyID) > 0xffff || len(dataKey.Encrypted) > 0xffff {
+		return nil, ErrInvalidEnvelope
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []byte{envelopeVersion}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(dataKey.KeyID)))
+	out = append(out, dataKey.KeyID...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(dataKey.Encrypted)))
+	out = append(out, dataKey.Encrypted...)
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+
+	// the header is authenticated so the wrapped key can't be swapped
+	return gcm.Seal(out, nonce, data, out[:len(out)-len(nonce)]), nil
+}
+
+func (e *Envelope) DecryptContext(ctx context.Context, data []byte) ([]byte, error) {
+	if len(data) < 1 || data[0] != envelopeVersion {
+		return nil, ErrInvalidEnvelope
+	}
+
+	offset := 1
+	readChunk := func() ([]byte, bool) {
+		if len(data) < offset+2 {
+			return nil, false
+		}
+		n := int(binary.BigEndian.Uint16(data[offset:]))
+		offset += 2
+		if len(data) < offset+n {
+			return nil, false
+		}
+		chunk := data[offset : offset+n]
+		offset += n
+		return chunk, true
+	}
+
+	keyID, ok := readChunk()
+	if !ok {
+		return nil, ErrInvalidEnvelope
+	}
+
+	encryptedKey, ok := readChunk()
+	if !ok {
+		return nil, ErrInvalidEnvelope
+	}
+	header := data[:offset]
+
+	dataKey, err := e.provider.Decrypt(ctx, string(keyID), encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	key := bytes.Clone(dataKey)
+	defer clear(key)
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < offset+gcm.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	nonce := data[offset : offset+gcm.NonceSize()]
+	plaintext, err := gcm.Open(nil, nonce, data[offset+gcm.NonceSize():], header)
+	if err != nil {
+		return nil, ErrIntegrityCheckFailed
+	}
+
+	return plaintext, nil
+}
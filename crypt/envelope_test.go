@@ -0,0 +1,96 @@
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// cachingKeyProvider returns the same data key slices on every call, like
+// providers caching keys to save KMS round-trips
+type cachingKeyProvider struct {
+	KeyProvider
+	dataKey   *DataKey
+	unwrapped map[string][]byte
+}
+
+func (p *cachingKeyProvider) GenerateDataKey(ctx context.Context) (*DataKey, error) {
+	if p.dataKey == nil {
+		dataKey, err := p.KeyProvider.GenerateDataKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.dataKey = dataKey
+	}
+	return &DataKey{KeyID: p.dataKey.KeyID, Plaintext: p.dataKey.Plaintext, Encrypted: p.dataKey.Encrypted}, nil
+}
+
+func (p *cachingKeyProvider) Decrypt(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	if key, ok := p.unwrapped[string(encrypted)]; ok {
+		return key, nil
+	}
+	key, err := p.KeyProvider.Decrypt(ctx, keyID, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	p.unwrapped[string(encrypted)] = key
+	return key, nil
+}
+
+func TestEnvelope(t *testing.T) {
+	provider, err := NewLocalKeyProvider("local-1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	var envelope Cipher = NewEnvelope(provider)
+
+	data := []byte("hello, world")
+	encrypted, err := envelope.Encrypt(data)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+
+	decrypted, err := envelope.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %v", err)
+	}
+
+	if string(decrypted) != string(data) {
+		t.Fatalf("decrypted data should be the same as original")
+	}
+
+	t.Run("different key id is rejected", func(t *testing.T) {
+		other, _ := NewLocalKeyProvider("local-2", bytes.Repeat([]byte{1}, 32))
+		if _, err := NewEnvelope(other).Decrypt(encrypted); err == nil {
+			t.Fatalf("expected error for unknown key id")
+		}
+	})
+
+	t.Run("cached data keys are left intact", func(t *testing.T) {
+		cached := NewEnvelope(&cachingKeyProvider{KeyProvider: provider, unwrapped: make(map[string][]byte)})
+		for i := 0; i < 3; i++ {
+			encrypted, err := cached.Encrypt(data)
+			if err != nil {
+				t.Fatalf("failed to encrypt: %v", err)
+			}
+			for j := 0; j < 2; j++ {
+				decrypted, err := cached.Decrypt(encrypted)
+				if err != nil {
+					t.Fatalf("decrypt %d of encryption %d failed: %v", j, i, err)
+				}
+				if !bytes.Equal(decrypted, data) {
+					t.Fatalf("decrypted data should be the same as original")
+				}
+			}
+		}
+	})
+
+	t.Run("tampered ciphertext is rejected", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[len(tampered)-1] ^= 0xff
+		if _, err := envelope.Decrypt(tampered); err != ErrIntegrityCheckFailed {
+			t.Fatalf("expected integrity check failure, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Stream cipher modes for interop with systems that don't use CBC. They are
+// always authenticated with encrypt-then-MAC, so tampered ciphertexts are
+// rejected before decryption.
+const (
+	AlgorithmAESCTR = "AES-256-CTR"
+	AlgorithmAESCFB = "AES-256-CFB"
+)
+
+// etmTagSize is the size of the HMAC-SHA256 tag of encrypt-then-MAC modes
+const etmTagSize = sha256.Size
+
+// etmMode returns the stream mode named by an algorithm such as AES-128-CTR,
+// or "" for CBC
+func etmMode(algorithm string) string {
+	mode := strings.ToUpper(algorithm)
+	if i := strings.LastIndex(mode, "-"); i >= 0 {
+		mode = mode[i+1:]
+	}
+	if mode == "CTR" || mode == "CFB" {
+		return mode
+	}
+	return ""
+}
+
+// encryptThenMAC encrypts data with AES in CTR or CFB mode and a random IV,
+// returning IV || ciphertext || HMAC-SHA256(IV || ciphertext). The IV option
+// is ignored: stream modes must never reuse an IV with the same key.
+func (c *Crypt) encryptThenMAC(mode string, data []byte) ([]byte, error) {
+	encKey, macKey := c.etmKeys(mode)
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, aes.BlockSize+len(data), aes.BlockSize+len(data)+etmTagSize)
+	iv := out[:aes.BlockSize]
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	if err := etmXORKeyStream(mode, encKey, block, iv, false, out[aes.BlockSize:], data); err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(out)
+	return mac.Sum(out), nil
+}
+
+// decryptThenMAC verifies the tag of encryptThenMAC output, then decrypts it.
+// It returns ErrIntegrityCheckFailed for tampered or truncated data.
+func (c *Crypt) decryptThenMAC(mode string, data []byte) ([]byte, error) {
+	if len(data) < aes.BlockSize+etmTagSize {
+		return nil, ErrIntegrityCheckFailed
+	}
+
+	encKey, macKey := c.etmKeys(mode)
+	signed, tag := data[:len(data)-etmTagSize], data[len(data)-etmTagSize:]
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(signed)
+	if !hmac.Equal(mac.Sum(nil), tag) {
+		return nil, ErrIntegrityCheckFailed
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(signed)-aes.BlockSize)
+	if err := etmXORKeyStream(mode, encKey, block, signed[:aes.BlockSize], true, plaintext, signed[aes.BlockSize:]); err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+// parallelCTRThreshold is the payload size from which CTR is split across
+// CPUs. CFB chains every block to the previous ciphertext when encrypting,
+// so it always runs on one.
+const parallelCTRThreshold = 1 << 20
+
+// etmXORKeyStream encrypts or decrypts src into dst with the stream of a mode
+func etmXORKeyStream(mode string, key []byte, block cipher.Block, iv []byte, decrypt bool, dst, src []byte) error {
+	if mode == "CTR" && len(src) >= parallelCTRThreshold && runtime.GOMAXPROCS(0) > 1 {
+		return xorCTRParallel(key, iv, dst, src)
+	}
+	etmStream(mode, block, iv, decrypt).XORKeyStream(dst, src)
+	return nil
+}
+
+// xorCTRParallel applies the CTR key stream to segments concurrently. The
+// key stream of a block only depends on its counter, so each segment starts
+// from the IV advanced by the number of blocks before it.
+func xorCTRParallel(key, iv, dst, src []byte) error {
+	segments := runtime.GOMAXPROCS(0)
+	blocks := (len(src) + aes.BlockSize - 1) / aes.BlockSize
+	perSegment := (blocks + segments - 1) / segments * aes.BlockSize
+
+	var wg sync.WaitGroup
+	errs := make(chan error, segments)
+	for start := 0; start < len(src); start += perSegment {
+		end := min(start+perSegment, len(src))
+		counter := ctrCounter(iv, start/aes.BlockSize)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			block, err := aes.NewCipher(key)
+			if err != nil {
+				errs <- err
+				return
+			}
+			cipher.NewCTR(block, counter).XORKeyStream(dst[start:end], src[start:end])
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// ctrCounter returns iv advanced by n blocks, counting like cipher.NewCTR:
+// the whole IV is a big endian integer that wraps around
+func ctrCounter(iv []byte, n int) []byte {
+	counter := append([]byte(nil), iv...)
+	for i := len(counter) - 1; i >= 0 && n > 0; i-- {
+		sum := int(counter[i]) + n&0xff
+		counter[i] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+	return counter
+}
+
+func etmStream(mode string, block cipher.Block, iv []byte, decrypt bool) cipher.Stream {
+	switch {
+	case mode == "CTR":
+		return cipher.NewCTR(block, iv)
+	case decrypt:
+		return cipher.NewCFBDecrypter(block, iv)
+	default:
+		return cipher.NewCFBEncrypter(block, iv)
+	}
+}
+
+// etmKeys splits the derived key into independent encryption and MAC keys
+// for a mode: HMAC-SHA256(key, "etm-enc-CTR") truncated to the key size, and
+// HMAC-SHA256(key, "etm-mac-CTR") for CTR. Other stacks derive them the same
+// way. Binding the mode keeps data of one mode from passing the MAC of the
+// other.
+func (c *Crypt) etmKeys(mode string) (encKey, macKey []byte) {
+	h := hmac.New(sha256.New, c.key)
+	h.Write([]byte("etm-enc-" + mode))
+	encKey = h.Sum(nil)[:len(c.key)]
+
+	h = hmac.New(sha256.New, c.key)
+	h.Write([]byte("etm-mac-" + mode))
+	macKey = h.Sum(nil)
+
+	return encKey, macKey
+}
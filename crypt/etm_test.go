@@ -0,0 +1,75 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncryptThenMAC(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmAESCTR, AlgorithmAESCFB, "aes-128-ctr"} {
+		keySize := 256
+		if algorithm == "aes-128-ctr" {
+			keySize = 128
+		}
+		crypt := New(CryptOpts{
+			Passphrase: "password",
+			Salt:       "salt",
+			Algorithm:  algorithm,
+			Digest:     "sha256",
+			KeySize:    keySize,
+			Iterations: 1000,
+		})
+
+		t.Run(algorithm+" should round trip", func(t *testing.T) {
+			data := []byte("hello, world")
+			encrypted, err := crypt.Encrypt(data)
+			if err != nil {
+				t.Fatalf("failed to encrypt: %v", err)
+			}
+			if len(encrypted) != 16+len(data)+32 {
+				t.Errorf("encrypted data should be IV, ciphertext and tag, got %d bytes", len(encrypted))
+			}
+
+			decrypted, err := crypt.Decrypt(encrypted)
+			if err != nil {
+				t.Fatalf("failed to decrypt: %v", err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Errorf("decrypted data should be %q, got %q", data, decrypted)
+			}
+		})
+
+		t.Run(algorithm+" should use a new IV per message", func(t *testing.T) {
+			first, _ := crypt.Encrypt([]byte("same"))
+			second, _ := crypt.Encrypt([]byte("same"))
+			if bytes.Equal(first, second) {
+				t.Errorf("ciphertexts of the same data should differ")
+			}
+		})
+
+		t.Run(algorithm+" should reject tampered data", func(t *testing.T) {
+			encrypted, _ := crypt.Encrypt([]byte("hello, world"))
+			for _, i := range []int{0, 16, len(encrypted) - 1} {
+				tampered := bytes.Clone(encrypted)
+				tampered[i] ^= 1
+				if _, err := crypt.Decrypt(tampered); !errors.Is(err, ErrIntegrityCheckFailed) {
+					t.Errorf("flipping byte %d should fail the integrity check, got %v", i, err)
+				}
+			}
+			if _, err := crypt.Decrypt(encrypted[:40]); !errors.Is(err, ErrIntegrityCheckFailed) {
+				t.Errorf("truncated data should fail the integrity check, got %v", err)
+			}
+		})
+	}
+
+	t.Run("should not decrypt data of another mode", func(t *testing.T) {
+		ctr := New(CryptOpts{Passphrase: "password", Salt: "salt", Algorithm: AlgorithmAESCTR, Digest: "sha1", KeySize: 256, Iterations: 1000})
+		cfb := New(CryptOpts{Passphrase: "password", Salt: "salt", Algorithm: AlgorithmAESCFB, Digest: "sha1", KeySize: 256, Iterations: 1000})
+
+		encrypted, _ := ctr.Encrypt([]byte("hello, world"))
+		if _, err := cfb.Decrypt(encrypted); !errors.Is(err, ErrIntegrityCheckFailed) {
+			t.Errorf("CFB should not decrypt CTR data, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package crypt
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var ErrInvalidFileHeader = errors.New("invalid encrypted file header")
+
+// ProgressFunc is called after each chunk with the number of source bytes
+// processed so far and the total size of the source (or -1 if unknown)
+type ProgressFunc func(processed, total int64)
+
+type FileOpts struct {
+	Passphrase string
+	Digest     string `default:"sha256"`
+	Iterations int    `default:"100000"`
+	ChunkSize  int    `default:"65536"`
+	Workers    int    `default:"1"` // Chunks sealed or opened concurrently, see processChunks
+	Progress   ProgressFunc
+}
+
+// File header layout:
+//
+//	magic       4 bytes  "FXCF"
+//	version     1 byte
+//	algorithm   1 byte   (1 = AES-256-GCM chunked stream)
+//	digest      1 byte   (1 = sha1, 2 = sha256, 3 = sha512)
+//	reserved    1 byte
+//	iterations  4 bytes  big endian
+//	chunk size  4 bytes  big endian
+//	salt        16 bytes
+//	nonce       7 bytes  prefix, followed per chunk by a 4 byte counter and a last-chunk flag
+//
+// Every chunk is sealed with the header as additional data, so tampering with
+// the header, reordering, truncating or extending chunks fails decryption.
+const (
+	fileMagic         = "FXCF"
+	fileVersion       = 1
+	fileAlgAES256GCM  = 1
+	fileHeaderSize    = 39
+	fileNoncePrefix   = 7
+	fileSaltSize      = 16
+	defaultChunkSize  = 64 * 1024
+	defaultFileDigest = "sha256"
+	defaultFileIters  = 100000
+
+	// MaxChunkSize is the largest chunk size DecryptStream accepts. The
+	// header isn't authenticated until the first chunk is opened, so a
+	// forged one could otherwise make it allocate up to 4 GiB per chunk.
+	MaxChunkSize = 16 << 20
+
+	// MaxFileIterations is the largest PBKDF2 iteration count DecryptStream
+	// accepts. The iterations come from the unauthenticated header, so a
+	// forged one could otherwise make key derivation run for hours.
+	MaxFileIterations = 10000000
+)
+
+var fileDigests = map[string]byte{"sha1": 1, "sha256": 2, "sha512": 3}
+
+func (o FileOpts) withDefaults() FileOpts {
+	if o.Digest == "" {
+		o.Digest = defaultFileDigest
+	}
+	if o.Iterations == 0 {
+		o.Iterations = defaultFileIters
+	}
+	if o.ChunkSize == 0 {
+		o.ChunkSize = defaultChunkSize
+	}
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	return o
+}
+
+// EncryptFile encrypts src into dst
+func EncryptFile(src, dst string, opts FileOpts) error {
+	return transformFile(src, dst, opts, EncryptStream)
+}
+
+// DecryptFile decrypts src into dst. dst is only created once every chunk
+// has been authenticated.
+func DecryptFile(src, dst string, opts FileOpts) error {
+	return transformFile(src, dst, opts, DecryptStream)
+}
+
+func transformFile(src, dst string, opts FileOpts, transform func(io.Writer, io.Reader, FileOpts) error) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if opts.Progress != nil {
+		if info, err := in.Stat(); err == nil {
+			opts.Progress = withTotal(opts.Progress, info.Size())
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := transform(tmp, in, opts); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+// withTotal replaces the unknown total reported by the stream functions
+func withTotal(progress ProgressFunc, total int64) ProgressFunc {
+	return func(processed, _ int64) {
+		progress(processed, total)
+	}
+}
+
+// EncryptStream encrypts everything read from r and writes it to w
+func EncryptStream(w io.Writer, r io.Reader, opts FileOpts) error {
+	opts = opts.withDefaults()
+
+	digestId, ok := fileDigests[opts.Digest]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlg, opts.Digest)
+	}
+	if opts.ChunkSize < 0 || opts.ChunkSize > MaxChunkSize {
+		return fmt.Errorf("chunk size must be between 1 and %d bytes, got %d", MaxChunkSize, opts.ChunkSize)
+	}
+	if opts.Iterations < 0 || opts.Iterations > MaxFileIterations {
+		return fmt.Errorf("iterations must be between 1 and %d, got %d", MaxFileIterations, opts.Iterations)
+	}
+
+	header := make([]byte, fileHeaderSize)
+	copy(header, fileMagic)
+	header[4] = fileVersion
+	header[5] = fileAlgAES256GCM
+	header[6] = digestId
+	binary.BigEndian.PutUint32(header[8:12], uint32(opts.Iterations))
+	binary.BigEndian.PutUint32(header[12:16], uint32(opts.ChunkSize))
+	if _, err := rand.Read(header[16:]); err != nil {
+		return err
+	}
+
+	gcm, err := fileCipher(header, opts.Passphrase, opts.Digest, opts.Iterations)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	return processChunks(w, r, opts.ChunkSize, opts.Workers, nil, func(counter uint32, last bool, chunk []byte) ([]byte, error) {
+		return gcm.Seal(nil, chunkNonce(header, counter, last), chunk, header), nil
+	}, progressFrom(opts.Progress, 0))
+}
+
+// DecryptStream decrypts everything read from r and writes it to w. Chunks
+// are written as they are authenticated, so w may receive partial output
+// before an error is returned.
+func DecryptStream(w io.Writer, r io.Reader, opts FileOpts) error {
+	header := make([]byte, fileHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return ErrInvalidFileHeader
+	}
+
+	if string(header[:4]) != fileMagic || header[4] != fileVersion || header[5] != fileAlgAES256GCM {
+		return ErrInvalidFileHeader
+	}
+
+	digest := ""
+	for name, id := range fileDigests {
+		if id == header[6] {
+			digest = name
+		}
+	}
+	if digest == "" {
+		return ErrInvalidFileHeader
+	}
+
+	iterations := int(binary.BigEndian.Uint32(header[8:12]))
+	chunkSize := int(binary.BigEndian.Uint32(header[12:16]))
+	if iterations <= 0 || chunkSize <= 0 {
+		return ErrInvalidFileHeader
+	}
+	if chunkSize > MaxChunkSize {
+		return fmt.Errorf("%w: chunk size %d exceeds %d bytes", ErrInvalidFileHeader, chunkSize, MaxChunkSize)
+	}
+	// Checked before deriving the key, which runs before any chunk is authenticated
+	if iterations > MaxFileIterations {
+		return fmt.Errorf("%w: iterations %d exceed %d", ErrInvalidFileHeader, iterations, MaxFileIterations)
+	}
+
+	gcm, err := fileCipher(header, opts.Passphrase, digest, iterations)
+	if err != nil {
+		return err
+	}
+
+	return processChunks(w, r, chunkSize+gcm.Overhead(), opts.withDefaults().Workers, ErrIntegrityCheckFailed, func(counter uint32, last bool, chunk []byte) ([]byte, error) {
+		plaintext, err := gcm.Open(nil, chunkNonce(header, counter, last), chunk, header)
+		if err != nil {
+			return nil, ErrIntegrityCheckFailed
+		}
+		return plaintext, nil
+	}, progressFrom(opts.Progress, fileHeaderSize))
+}
+
+func fileCipher(header []byte, passphrase, digest string, iterations int) (cipher.AEAD, error) {
+	salt := header[16 : 16+fileSaltSize]
+	key, err := createKey(passphrase, string(salt), iterations, 256, digest)
+	if err != nil {
+		return nil, err
+	}
+	return newGCM(key)
+}
+
+func chunkNonce(header []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, header[fileHeaderSize-fileNoncePrefix:])
+	binary.BigEndian.PutUint32(nonce[fileNoncePrefix:], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
@@ -0,0 +1,112 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEncryptDecryptFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "plain.txt")
+	enc := filepath.Join(dir, "plain.txt.enc")
+	dst := filepath.Join(dir, "decrypted.txt")
+
+	data := bytes.Repeat([]byte("hello, world\n"), 1000)
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	var lastProcessed, lastTotal int64
+	opts := FileOpts{
+		Passphrase: "password",
+		Iterations: 1000,
+		ChunkSize:  1024,
+		Progress: func(processed, total int64) {
+			lastProcessed, lastTotal = processed, total
+		},
+	}
+
+	if err := EncryptFile(src, enc, opts); err != nil {
+		t.Fatalf("failed to encrypt file: %v", err)
+	}
+
+	if lastProcessed != int64(len(data)) || lastTotal != int64(len(data)) {
+		t.Fatalf("unexpected progress %d/%d", lastProcessed, lastTotal)
+	}
+
+	if err := DecryptFile(enc, dst, FileOpts{Passphrase: "password"}); err != nil {
+		t.Fatalf("failed to decrypt file: %v", err)
+	}
+
+	decrypted, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("failed to read decrypted file: %v", err)
+	}
+
+	if !bytes.Equal(decrypted, data) {
+		t.Fatalf("decrypted data should be the same as original")
+	}
+
+	t.Run("tampered file is rejected", func(t *testing.T) {
+		encrypted, _ := os.ReadFile(enc)
+		encrypted[len(encrypted)/2] ^= 0xff
+		tampered := filepath.Join(dir, "tampered.enc")
+		os.WriteFile(tampered, encrypted, 0644)
+
+		out := filepath.Join(dir, "tampered.txt")
+		if err := DecryptFile(tampered, out, FileOpts{Passphrase: "password"}); err != ErrIntegrityCheckFailed {
+			t.Fatalf("expected integrity check failure, got %v", err)
+		}
+
+		if _, err := os.Stat(out); !os.IsNotExist(err) {
+			t.Fatalf("output should not exist for a tampered file")
+		}
+	})
+
+	t.Run("truncated file is rejected", func(t *testing.T) {
+		encrypted, _ := os.ReadFile(enc)
+		truncated := filepath.Join(dir, "truncated.enc")
+		os.WriteFile(truncated, encrypted[:fileHeaderSize+1040], 0644)
+
+		if err := DecryptFile(truncated, filepath.Join(dir, "truncated.txt"), FileOpts{Passphrase: "password"}); err != ErrIntegrityCheckFailed {
+			t.Fatalf("expected integrity check failure, got %v", err)
+		}
+	})
+
+	t.Run("oversized chunk size is rejected", func(t *testing.T) {
+		encrypted, _ := os.ReadFile(enc)
+		binary.BigEndian.PutUint32(encrypted[12:16], 0xffffffff)
+
+		var out bytes.Buffer
+		if err := DecryptStream(&out, bytes.NewReader(encrypted), FileOpts{Passphrase: "password"}); !errors.Is(err, ErrInvalidFileHeader) {
+			t.Fatalf("expected invalid header, got %v", err)
+		}
+
+		if err := EncryptStream(&out, bytes.NewReader(data), FileOpts{Passphrase: "password", ChunkSize: MaxChunkSize + 1}); err == nil {
+			t.Fatalf("expected an error encrypting with an oversized chunk size")
+		}
+	})
+
+	t.Run("forged iteration count is rejected", func(t *testing.T) {
+		encrypted, _ := os.ReadFile(enc)
+		binary.BigEndian.PutUint32(encrypted[8:12], 0xffffffff)
+
+		start := time.Now()
+		var out bytes.Buffer
+		if err := DecryptStream(&out, bytes.NewReader(encrypted), FileOpts{Passphrase: "password"}); !errors.Is(err, ErrInvalidFileHeader) {
+			t.Fatalf("expected invalid header, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("rejecting the header took %s, the key was derived", elapsed)
+		}
+
+		if err := EncryptStream(&out, bytes.NewReader(data), FileOpts{Passphrase: "password", Iterations: MaxFileIterations + 1}); err == nil {
+			t.Fatalf("expected an error encrypting with too many iterations")
+		}
+	})
+}
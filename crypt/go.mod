@@ -2,4 +2,11 @@ module github.com/fxfn/x/crypt
 
 go 1.24.4
 
-replace github.com/fxfn/x/crypt => .
\ No newline at end of file
+replace github.com/fxfn/x/crypt => .
+
+require (
+	golang.org/x/crypto v0.23.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.20.0 // indirect
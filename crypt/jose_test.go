@@ -0,0 +1,198 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAESKeyWrap(t *testing.T) {
+	// RFC 3394 section 4.6, 256-bit KEK wrapping 256-bit key data
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F")
+	keyData, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F")
+	expected, _ := hex.DecodeString("28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21")
+
+	wrapped, err := aesKeyWrap(kek, keyData)
+	if err != nil {
+		t.Fatalf("failed to wrap: %v", err)
+	}
+
+	if !bytes.Equal(wrapped, expected) {
+		t.Fatalf("wrapped key mismatch: %x", wrapped)
+	}
+
+	unwrapped, err := aesKeyUnwrap(kek, wrapped)
+	if err != nil {
+		t.Fatalf("failed to unwrap: %v", err)
+	}
+
+	if !bytes.Equal(unwrapped, keyData) {
+		t.Fatalf("unwrapped key mismatch: %x", unwrapped)
+	}
+}
+
+func TestJWS(t *testing.T) {
+	payload := []byte(`{"sub":"1234567890"}`)
+
+	t.Run("HS256 round trip", func(t *testing.T) {
+		token, err := SignJWS(payload, []byte("secret"), SignJWSOpts{Algorithm: HS256, Type: "JWT"})
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+
+		verified, header, err := VerifyJWS(token, []byte("secret"))
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		if string(verified) != string(payload) {
+			t.Fatalf("payload mismatch: %s", verified)
+		}
+
+		if header.Alg != HS256 || header.Typ != "JWT" {
+			t.Fatalf("unexpected header: %+v", header)
+		}
+
+		if _, _, err := VerifyJWS(token, []byte("other")); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected invalid signature, got %v", err)
+		}
+	})
+
+	t.Run("RS256 round trip", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+
+		token, err := SignJWS(payload, key, SignJWSOpts{Algorithm: RS256})
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+
+		verified, _, err := VerifyJWS(token, &key.PublicKey)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		if string(verified) != string(payload) {
+			t.Fatalf("payload mismatch: %s", verified)
+		}
+	})
+
+	t.Run("ES256 round trip with embedded key", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+
+		jwk := []byte(`{"kty":"EC","crv":"P-256"}`)
+		token, err := SignJWS(payload, key, SignJWSOpts{Algorithm: ES256, Type: "dpop+jwt", JWK: jwk})
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+
+		header, err := ParseJWSHeader(token)
+		if err != nil || string(header.JWK) != string(jwk) {
+			t.Fatalf("unexpected header: %+v, %v", header, err)
+		}
+
+		verified, _, err := VerifyJWS(token, &key.PublicKey)
+		if err != nil {
+			t.Fatalf("failed to verify: %v", err)
+		}
+
+		if string(verified) != string(payload) {
+			t.Fatalf("payload mismatch: %s", verified)
+		}
+
+		other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if _, _, err := VerifyJWS(token, &other.PublicKey); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("expected invalid signature, got %v", err)
+		}
+	})
+}
+
+func TestJWE(t *testing.T) {
+	plaintext := []byte("hello, world")
+
+	t.Run("PBES2 round trip", func(t *testing.T) {
+		for _, alg := range []JWEAlgorithm{PBES2HS256A128KW, PBES2HS512A256KW} {
+			token, err := EncryptJWE(plaintext, "password", EncryptJWEOpts{Algorithm: alg, Iterations: 1000})
+			if err != nil {
+				t.Fatalf("failed to encrypt: %v", err)
+			}
+
+			decrypted, header, err := DecryptJWE(token, "password")
+			if err != nil {
+				t.Fatalf("failed to decrypt: %v", err)
+			}
+
+			if string(decrypted) != string(plaintext) {
+				t.Fatalf("plaintext mismatch: %s", decrypted)
+			}
+
+			if header.Alg != alg || header.Enc != A256GCM {
+				t.Fatalf("unexpected header: %+v", header)
+			}
+
+			if _, _, err := DecryptJWE(token, "wrong"); err == nil {
+				t.Fatalf("expected error with wrong passphrase")
+			}
+		}
+	})
+
+	t.Run("RSA-OAEP round trip", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+
+		for _, alg := range []JWEAlgorithm{RSAOAEP, RSAOAEP256} {
+			token, err := EncryptJWE(plaintext, &key.PublicKey, EncryptJWEOpts{Algorithm: alg})
+			if err != nil {
+				t.Fatalf("failed to encrypt: %v", err)
+			}
+
+			decrypted, _, err := DecryptJWE(token, key)
+			if err != nil {
+				t.Fatalf("failed to decrypt: %v", err)
+			}
+
+			if string(decrypted) != string(plaintext) {
+				t.Fatalf("plaintext mismatch: %s", decrypted)
+			}
+		}
+	})
+
+	t.Run("PBES2 iteration bounds", func(t *testing.T) {
+		token, err := EncryptJWE(plaintext, "password", EncryptJWEOpts{Algorithm: PBES2HS256A128KW, Iterations: 1000})
+		if err != nil {
+			t.Fatalf("failed to encrypt: %v", err)
+		}
+		parts := strings.Split(token, ".")
+		headerJSON, _ := b64.DecodeString(parts[0])
+
+		for _, p2c := range []int{MaxPBES2Iterations + 1, 1 << 30, MinPBES2Iterations - 1, 0} {
+			var header map[string]any
+			json.Unmarshal(headerJSON, &header)
+			header["p2c"] = p2c
+			forged, _ := json.Marshal(header)
+			parts[0] = b64.EncodeToString(forged)
+
+			if _, _, err := DecryptJWE(strings.Join(parts, "."), "password"); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("p2c %d: expected ErrInvalidToken, got %v", p2c, err)
+			}
+		}
+
+		if _, err := EncryptJWE(plaintext, "password", EncryptJWEOpts{Algorithm: PBES2HS256A128KW, Iterations: 10}); err == nil {
+			t.Error("expected an error encrypting with too few iterations")
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+type JWEAlgorithm string
+
+const (
+	PBES2HS256A128KW JWEAlgorithm = "PBES2-HS256+A128KW"
+	PBES2HS512A256KW JWEAlgorithm = "PBES2-HS512+A256KW"
+	RSAOAEP          JWEAlgorithm = "RSA-OAEP"
+	RSAOAEP256       JWEAlgorithm = "RSA-OAEP-256"
+)
+
+// A256GCM is the only content encryption supported
+const A256GCM = "A256GCM"
+
+// JWEHeader is the protected header of a compact JWE
+type JWEHeader struct {
+	Alg JWEAlgorithm `json:"alg"`
+	Enc string       `json:"enc"`
+	Typ string       `json:"typ,omitempty"`
+	Cty string       `json:"cty,omitempty"`
+	Kid string       `json:"kid,omitempty"`
+	P2s string       `json:"p2s,omitempty"`
+	P2c int          `json:"p2c,omitempty"`
+}
+
+type EncryptJWEOpts struct {
+	Algorithm   JWEAlgorithm
+	KeyID       string
+	Type        string
+	ContentType string
+	Iterations  int `default:"600000"` // PBES2 only
+}
+
+const defaultPBES2Iterations = 600000
+
+// PBES2 iteration counts DecryptJWE accepts. p2c comes from the token, so
+// without a maximum a single token could make key derivation run for hours.
+const (
+	MinPBES2Iterations = 1000
+	MaxPBES2Iterations = 1000000
+)
+
+// validPBES2Iterations reports whether a PBES2 iteration count is within bounds
+func validPBES2Iterations(iterations int) bool {
+	return iterations >= MinPBES2Iterations && iterations <= MaxPBES2Iterations
+}
+
+// EncryptJWE produces a compact JWE using A256GCM content encryption.
+// key must be a string or []byte passphrase for PBES2-* and an *rsa.PublicKey for RSA-OAEP*.
+func EncryptJWE(plaintext []byte, key any, opts EncryptJWEOpts) (string, error) {
+	header := JWEHeader{
+		Alg: opts.Algorithm,
+		Enc: A256GCM,
+		Typ: opts.Type,
+		Cty: opts.ContentType,
+		Kid: opts.KeyID,
+	}
+
+	cek := make([]byte, 32)
+	if _, err := rand.Read(cek); err != nil {
+		return "", err
+	}
+
+	var encryptedKey []byte
+	switch opts.Algorithm {
+	case PBES2HS256A128KW, PBES2HS512A256KW:
+		passphrase, ok := passphraseFromKey(key)
+		if !ok {
+			return "", ErrInvalidKey
+		}
+
+		iterations := opts.Iterations
+		if iterations == 0 {
+			iterations = defaultPBES2Iterations
+		}
+		if !validPBES2Iterations(iterations) {
+			return "", fmt.Errorf("PBES2 iterations must be between %d and %d, got %d", MinPBES2Iterations, MaxPBES2Iterations, iterations)
+		}
+
+		saltInput := make([]byte, 16)
+		if _, err := rand.Read(saltInput); err != nil {
+			return "", err
+		}
+		header.P2s = b64.EncodeToString(saltInput)
+		header.P2c = iterations
+
+		kek, err := pbes2Key(opts.Algorithm, passphrase, saltInput, iterations)
+		if err != nil {
+			return "", err
+		}
+
+		encryptedKey, err = aesKeyWrap(kek, cek)
+		if err != nil {
+			return "", err
+		}
+	case RSAOAEP, RSAOAEP256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return "", ErrInvalidKey
+		}
+
+		var err error
+		encryptedKey, err = rsa.EncryptOAEP(oaepHash(opts.Algorithm)(), rand.Reader, publicKey, cek, nil)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAlg, opts.Algorithm)
+	}
+
+	headerJson, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	protected := b64.EncodeToString(headerJson)
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return strings.Join([]string{
+		protected,
+		b64.EncodeToString(encryptedKey),
+		b64.EncodeToString(iv),
+		b64.EncodeToString(ciphertext),
+		b64.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE decrypts a compact JWE and returns the plaintext and its header.
+// key must be a string or []byte passphrase for PBES2-* and an *rsa.PrivateKey for RSA-OAEP*.
+func DecryptJWE(token string, key any) ([]byte, *JWEHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, nil, ErrInvalidToken
+	}
+
+	decoded := make([][]byte, 5)
+	for i, part := range parts {
+		value, err := b64.DecodeString(part)
+		if err != nil {
+			return nil, nil, ErrInvalidToken
+		}
+		decoded[i] = value
+	}
+
+	var header JWEHeader
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	if header.Enc != A256GCM {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, header.Enc)
+	}
+
+	var cek []byte
+	switch header.Alg {
+	case PBES2HS256A128KW, PBES2HS512A256KW:
+		passphrase, ok := passphraseFromKey(key)
+		if !ok {
+			return nil, nil, ErrInvalidKey
+		}
+
+		saltInput, err := b64.DecodeString(header.P2s)
+		if err != nil {
+			return nil, nil, ErrInvalidToken
+		}
+		// Checked before deriving the key, p2c is chosen by whoever made the token
+		if !validPBES2Iterations(header.P2c) {
+			return nil, nil, fmt.Errorf("%w: p2c %d is outside %d to %d", ErrInvalidToken, header.P2c, MinPBES2Iterations, MaxPBES2Iterations)
+		}
+
+		kek, err := pbes2Key(header.Alg, passphrase, saltInput, header.P2c)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cek, err = aesKeyUnwrap(kek, decoded[1])
+		if err != nil {
+			return nil, nil, err
+		}
+	case RSAOAEP, RSAOAEP256:
+		privateKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, ErrInvalidKey
+		}
+
+		var err error
+		cek, err = rsa.DecryptOAEP(oaepHash(header.Alg)(), rand.Reader, privateKey, decoded[1], nil)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, header.Alg)
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(decoded[2]) != gcm.NonceSize() {
+		return nil, nil, ErrInvalidToken
+	}
+
+	sealed := append(decoded[3], decoded[4]...)
+	plaintext, err := gcm.Open(nil, decoded[2], sealed, []byte(parts[0]))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plaintext, &header, nil
+}
+
+func passphraseFromKey(key any) (string, bool) {
+	switch k := key.(type) {
+	case string:
+		return k, true
+	case []byte:
+		return string(k), true
+	}
+	return "", false
+}
+
+// pbes2Key derives the key encryption key as defined in RFC 7518 section 4.8
+func pbes2Key(alg JWEAlgorithm, passphrase string, saltInput []byte, iterations int) ([]byte, error) {
+	salt := append([]byte(alg), 0x00)
+	salt = append(salt, saltInput...)
+
+	if alg == PBES2HS512A256KW {
+		return pbkdf2.Key(sha512.New, passphrase, salt, iterations, 32)
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, iterations, 16)
+}
+
+func oaepHash(alg JWEAlgorithm) func() hash.Hash {
+	if alg == RSAOAEP256 {
+		return sha256.New
+	}
+	return sha1.New
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,230 @@
+package crypt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid compact token")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrUnsupportedAlg   = errors.New("unsupported algorithm")
+	ErrInvalidKey       = errors.New("invalid key for algorithm")
+)
+
+type JWSAlgorithm string
+
+const (
+	HS256 JWSAlgorithm = "HS256"
+	HS384 JWSAlgorithm = "HS384"
+	HS512 JWSAlgorithm = "HS512"
+	RS256 JWSAlgorithm = "RS256"
+	RS384 JWSAlgorithm = "RS384"
+	RS512 JWSAlgorithm = "RS512"
+	ES256 JWSAlgorithm = "ES256"
+)
+
+// JWSHeader is the protected header of a compact JWS
+type JWSHeader struct {
+	Alg JWSAlgorithm `json:"alg"`
+	Typ string       `json:"typ,omitempty"`
+	Kid string       `json:"kid,omitempty"`
+
+	// JWK is a public key embedded in the header, e.g. of a DPoP proof
+	JWK json.RawMessage `json:"jwk,omitempty"`
+}
+
+type SignJWSOpts struct {
+	Algorithm JWSAlgorithm
+	KeyID     string
+	Type      string
+	JWK       json.RawMessage
+}
+
+var b64 = base64.RawURLEncoding
+
+// SignJWS produces a compact JWS over payload.
+// key must be a []byte secret for HS*, an *rsa.PrivateKey for RS* and an
+// *ecdsa.PrivateKey on P-256 for ES256.
+func SignJWS(payload []byte, key any, opts SignJWSOpts) (string, error) {
+	header, err := json.Marshal(JWSHeader{
+		Alg: opts.Algorithm,
+		Typ: opts.Type,
+		Kid: opts.KeyID,
+		JWK: opts.JWK,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	signature, err := jwsSign(opts.Algorithm, key, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + b64.EncodeToString(signature), nil
+}
+
+// ParseJWSHeader decodes the protected header of a compact JWS without
+// verifying it, e.g. to pick the key to verify it with
+func ParseJWSHeader(token string) (*JWSHeader, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	headerJson, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var header JWSHeader
+	if err := json.Unmarshal(headerJson, &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &header, nil
+}
+
+// VerifyJWS checks the signature of a compact JWS and returns its payload.
+// key must be a []byte secret for HS*, an *rsa.PublicKey for RS* and an
+// *ecdsa.PublicKey for ES256.
+func VerifyJWS(token string, key any) ([]byte, *JWSHeader, error) {
+	header, err := ParseJWSHeader(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	parts := strings.Split(token, ".")
+
+	signature, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+	if err := jwsVerify(header.Alg, key, signingInput, signature); err != nil {
+		return nil, nil, err
+	}
+
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	return payload, header, nil
+}
+
+func jwsHash(alg JWSAlgorithm) (func() hash.Hash, crypto.Hash, error) {
+	switch alg {
+	case HS256, RS256, ES256:
+		return sha256.New, crypto.SHA256, nil
+	case HS384, RS384:
+		return sha512.New384, crypto.SHA384, nil
+	case HS512, RS512:
+		return sha512.New, crypto.SHA512, nil
+	}
+	return nil, 0, fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
+}
+
+func jwsSign(alg JWSAlgorithm, key any, input []byte) ([]byte, error) {
+	hasher, hashId, err := jwsHash(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch alg {
+	case HS256, HS384, HS512:
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, ErrInvalidKey
+		}
+		mac := hmac.New(hasher, secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case ES256:
+		privateKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok || privateKey.Curve.Params().BitSize != 256 {
+			return nil, ErrInvalidKey
+		}
+		h := hasher()
+		h.Write(input)
+		r, s, err := ecdsa.Sign(rand.Reader, privateKey, h.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+		// JWS signatures are R and S as fixed-size big-endian integers
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	default:
+		privateKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidKey
+		}
+		h := hasher()
+		h.Write(input)
+		return rsa.SignPKCS1v15(rand.Reader, privateKey, hashId, h.Sum(nil))
+	}
+}
+
+func jwsVerify(alg JWSAlgorithm, key any, input, signature []byte) error {
+	hasher, hashId, err := jwsHash(alg)
+	if err != nil {
+		return err
+	}
+
+	switch alg {
+	case HS256, HS384, HS512:
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrInvalidKey
+		}
+		mac := hmac.New(hasher, secret)
+		mac.Write(input)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case ES256:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || publicKey.Curve.Params().BitSize != 256 {
+			return ErrInvalidKey
+		}
+		if len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		h := hasher()
+		h.Write(input)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, h.Sum(nil), r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidKey
+		}
+		h := hasher()
+		h.Write(input)
+		if err := rsa.VerifyPKCS1v15(publicKey, hashId, h.Sum(nil), signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+}
@@ -1,23 +1,73 @@
 package crypt
 
 import (
+	"crypto/md5"
 	"crypto/pbkdf2"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"fmt"
 	"hash"
 )
 
-func createKey(passphrase, salt string, iterations, keySize int, digest string) ([]byte, error) {
-	var hasher func() hash.Hash
+const (
+	KeyDerivationPBKDF2        = "pbkdf2"
+	KeyDerivationEVPBytesToKey = "evp_bytes_to_key"
+)
+
+func digestHasher(digest string) (func() hash.Hash, error) {
 	switch digest {
+	case "md5":
+		return md5.New, nil
 	case "sha1":
-		hasher = sha1.New
+		return sha1.New, nil
 	case "sha256":
-		hasher = sha256.New
+		return sha256.New, nil
 	case "sha512":
-		hasher = sha512.New
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, digest)
+}
+
+func createKey(passphrase, salt string, iterations, keySize int, digest string) ([]byte, error) {
+	hasher, err := digestHasher(digest)
+	if err != nil {
+		return nil, err
 	}
 
 	return pbkdf2.Key(hasher, passphrase, []byte(salt), iterations, keySize/8)
 }
+
+// evpBytesToKey mirrors OpenSSL's EVP_BytesToKey, which is what Node's
+// deprecated crypto.createCipher and `openssl enc` without -pbkdf2 use.
+// It returns the key and a 16 byte IV.
+func evpBytesToKey(passphrase, salt string, iterations, keySize int, digest string) ([]byte, []byte, error) {
+	hasher, err := digestHasher(digest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if iterations < 1 {
+		iterations = 1
+	}
+
+	keyLen := keySize / 8
+	var derived, prev []byte
+	for len(derived) < keyLen+16 {
+		h := hasher()
+		h.Write(prev)
+		h.Write([]byte(passphrase))
+		h.Write([]byte(salt))
+		prev = h.Sum(nil)
+
+		for i := 1; i < iterations; i++ {
+			h.Reset()
+			h.Write(prev)
+			prev = h.Sum(nil)
+		}
+
+		derived = append(derived, prev...)
+	}
+
+	return derived[:keyLen], derived[keyLen : keyLen+16], nil
+}
@@ -0,0 +1,177 @@
+package crypt
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+)
+
+// KeyCache keeps derived keys so New doesn't run PBKDF2 again for options it
+// has seen, e.g. when services build a Crypt per tenant and request. Keys are
+// cached by a hash of the passphrase, salt and derivation parameters, so
+// passphrases aren't kept in memory. It is safe for concurrent use.
+//
+//	keys := crypt.NewKeyCache(1000)
+//	c := crypt.New(crypt.CryptOpts{Passphrase: tenant.Secret, Salt: tenant.ID, KeyCache: keys})
+type KeyCache struct {
+	keys *lru[derivedKey]
+}
+
+// derivedKey is the key, and IV of the legacy derivation, New derives
+type derivedKey struct {
+	key []byte
+	iv  []byte
+}
+
+// NewKeyCache creates a KeyCache holding up to size keys, evicting the least
+// recently used
+func NewKeyCache(size int) *KeyCache {
+	return &KeyCache{keys: newLRU[derivedKey](size)}
+}
+
+// Len returns the number of cached keys
+func (kc *KeyCache) Len() int {
+	return kc.keys.len()
+}
+
+// derive returns the cached key for opts, deriving it on a miss
+func (kc *KeyCache) derive(opts CryptOpts) ([]byte, []byte, error) {
+	id := optsHash(opts, false)
+	if derived, ok := kc.keys.get(id); ok {
+		return derived.key, derived.iv, nil
+	}
+
+	key, iv, err := deriveKey(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	kc.keys.put(id, derivedKey{key: key, iv: iv})
+	return key, iv, nil
+}
+
+// CryptPool keeps a Crypt per set of options, so services encrypting for
+// many tenants reuse them instead of building one per call. A Crypt is safe
+// for concurrent use, so the same one is handed to every goroutine asking
+// for its options. Crypts are cached by a hash of their options.
+//
+//	pool := crypt.NewCryptPool(1000)
+//	encrypted, err := pool.Get(tenant.CryptOpts()).Encrypt(data)
+type CryptPool struct {
+	crypts *lru[*Crypt]
+}
+
+// NewCryptPool creates a CryptPool holding up to size Crypts, evicting the
+// least recently used
+func NewCryptPool(size int) *CryptPool {
+	return &CryptPool{crypts: newLRU[*Crypt](size)}
+}
+
+// Get returns the Crypt for opts, creating it with New on a miss. Like New,
+// it panics when the key can't be derived. Secrets are read on every call,
+// so a rotated passphrase gets a Crypt of its own.
+func (p *CryptPool) Get(opts CryptOpts) *Crypt {
+	opts, err := opts.withSecrets(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	id := optsHash(opts, true)
+	if c, ok := p.crypts.get(id); ok {
+		return c
+	}
+
+	c := New(opts)
+	p.crypts.put(id, c)
+	return c
+}
+
+// Len returns the number of pooled Crypts
+func (p *CryptPool) Len() int {
+	return p.crypts.len()
+}
+
+// optsHash identifies the key opts derive, and with cipher also the IV and
+// algorithm. Fields are length-prefixed so they can't run into each other.
+func optsHash(opts CryptOpts, cipher bool) [sha256.Size]byte {
+	h := sha256.New()
+	field := func(value string) {
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(value))))
+		h.Write([]byte(value))
+	}
+
+	field(opts.Passphrase)
+	field(opts.Salt)
+	field(opts.Digest)
+	field(opts.KeyDerivation)
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(opts.KeySize)))
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(opts.Iterations)))
+	if cipher {
+		field(opts.IV)
+		field(opts.Algorithm)
+	}
+
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// lru is a fixed size cache evicting the least recently used entry
+type lru[V any] struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List // Front is the most recently used
+	entries map[[sha256.Size]byte]*list.Element
+}
+
+type lruEntry[V any] struct {
+	id    [sha256.Size]byte
+	value V
+}
+
+func newLRU[V any](size int) *lru[V] {
+	return &lru[V]{
+		size:    max(size, 1),
+		order:   list.New(),
+		entries: make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+func (l *lru[V]) get(id [sha256.Size]byte) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[id]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry[V]).value, true
+}
+
+func (l *lru[V]) put(id [sha256.Size]byte, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[id]; ok {
+		element.Value.(*lruEntry[V]).value = value
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[id] = l.order.PushFront(&lruEntry[V]{id: id, value: value})
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry[V]).id)
+	}
+}
+
+func (l *lru[V]) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
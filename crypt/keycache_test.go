@@ -0,0 +1,106 @@
+package crypt
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func tenantOpts(tenant string) CryptOpts {
+	return CryptOpts{
+		Passphrase: "password",
+		Salt:       tenant,
+		IV:         "1234567890123456",
+		Algorithm:  "AES-256-CBC",
+		Digest:     "sha256",
+		KeySize:    256,
+		Iterations: 1000,
+	}
+}
+
+func TestKeyCache(t *testing.T) {
+	t.Run("should reuse keys derived with the same options", func(t *testing.T) {
+		keys := NewKeyCache(10)
+		opts := tenantOpts("acme")
+		opts.KeyCache = keys
+
+		first, second := New(opts), New(opts)
+		if keys.Len() != 1 {
+			t.Errorf("there should be 1 cached key, got %d", keys.Len())
+		}
+		if !bytes.Equal(first.key, second.key) || !bytes.Equal(first.key, New(tenantOpts("acme")).key) {
+			t.Errorf("cached key should match the derived key")
+		}
+	})
+
+	t.Run("should evict the least recently used key", func(t *testing.T) {
+		keys := NewKeyCache(2)
+		for _, tenant := range []string{"a", "b", "a", "c"} {
+			opts := tenantOpts(tenant)
+			opts.KeyCache = keys
+			New(opts)
+		}
+
+		if keys.Len() != 2 {
+			t.Errorf("there should be 2 cached keys, got %d", keys.Len())
+		}
+		if _, ok := keys.keys.get(optsHash(tenantOpts("b"), false)); ok {
+			t.Errorf("b should be evicted")
+		}
+		if _, ok := keys.keys.get(optsHash(tenantOpts("a"), false)); !ok {
+			t.Errorf("a should be kept")
+		}
+	})
+
+	t.Run("should cache the IV of the legacy derivation", func(t *testing.T) {
+		keys := NewKeyCache(10)
+		opts := NodeLegacyCreateCipher.Opts("password", "", "")
+		opts.KeyCache = keys
+
+		New(opts)
+		if New(opts).iv != New(NodeLegacyCreateCipher.Opts("password", "", "")).iv {
+			t.Errorf("cached IV should match the derived IV")
+		}
+	})
+}
+
+func TestCryptPool(t *testing.T) {
+	t.Run("should reuse a Crypt per set of options", func(t *testing.T) {
+		pool := NewCryptPool(10)
+		if pool.Get(tenantOpts("acme")) != pool.Get(tenantOpts("acme")) {
+			t.Errorf("the same options should return the same Crypt")
+		}
+
+		other := tenantOpts("acme")
+		other.IV = "6543210987654321"
+		if pool.Get(tenantOpts("acme")) == pool.Get(other) {
+			t.Errorf("another IV should return another Crypt")
+		}
+		if pool.Len() != 2 {
+			t.Errorf("there should be 2 pooled Crypts, got %d", pool.Len())
+		}
+	})
+
+	t.Run("should be safe for concurrent use", func(t *testing.T) {
+		pool := NewCryptPool(2)
+		data := []byte("hello, world")
+
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c := pool.Get(tenantOpts([]string{"a", "b", "c"}[i%3]))
+				encrypted, err := c.Encrypt(data)
+				if err != nil {
+					t.Errorf("failed to encrypt: %v", err)
+					return
+				}
+				if decrypted, err := c.Decrypt(encrypted); err != nil || !bytes.Equal(decrypted, data) {
+					t.Errorf("decrypted data should be %q, got %q, %v", data, decrypted, err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,91 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+// default initial value from RFC 3394 section 2.2.3.1
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// aesKeyWrap wraps cek with kek as described in RFC 3394.
+func aesKeyWrap(kek, cek []byte) ([]byte, error) {
+	if len(cek)%8 != 0 || len(cek) < 16 {
+		return nil, fmt.Errorf("key to wrap must be a multiple of 8 bytes and at least 16 bytes")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(cek) / 8
+	r := make([][]byte, n)
+	for i := range r {
+		r[i] = append([]byte{}, cek[i*8:(i+1)*8]...)
+	}
+
+	a := append([]byte{}, keyWrapIV...)
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 0; i < n; i++ {
+			copy(buf[:8], a)
+			copy(buf[8:], r[i])
+			block.Encrypt(buf, buf)
+
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(buf[:8])^t)
+			copy(r[i], buf[8:])
+		}
+	}
+
+	out := append([]byte{}, a...)
+	for i := range r {
+		out = append(out, r[i]...)
+	}
+	return out, nil
+}
+
+// aesKeyUnwrap reverses aesKeyWrap and verifies the integrity check value.
+func aesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, fmt.Errorf("wrapped key must be a multiple of 8 bytes and at least 24 bytes")
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(wrapped)/8 - 1
+	r := make([][]byte, n)
+	for i := range r {
+		r[i] = append([]byte{}, wrapped[(i+1)*8:(i+2)*8]...)
+	}
+
+	a := append([]byte{}, wrapped[:8]...)
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n - 1; i >= 0; i-- {
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(buf[:8], binary.BigEndian.Uint64(a)^t)
+			copy(buf[8:], r[i])
+			block.Decrypt(buf, buf)
+
+			copy(a, buf[:8])
+			copy(r[i], buf[8:])
+		}
+	}
+
+	if subtle.ConstantTimeCompare(a, keyWrapIV) != 1 {
+		return nil, fmt.Errorf("key unwrap integrity check failed")
+	}
+
+	out := make([]byte, 0, n*8)
+	for i := range r {
+		out = append(out, r[i]...)
+	}
+	return out, nil
+}
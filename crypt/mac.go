@@ -0,0 +1,23 @@
+package crypt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// SignMAC returns the HMAC-SHA256 of data with key, for signing URLs,
+// webhook payloads and other messages both sides share a secret for
+func SignMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// VerifyMAC checks the output of SignMAC in constant time, returning
+// ErrInvalidSignature when it does not match
+func VerifyMAC(key, data, mac []byte) error {
+	if !hmac.Equal(SignMAC(key, data), mac) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
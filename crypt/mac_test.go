@@ -0,0 +1,24 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestMAC(t *testing.T) {
+	// RFC 4231 test case 2
+	mac := SignMAC([]byte("Jefe"), []byte("what do ya want for nothing?"))
+	expected := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if hex.EncodeToString(mac) != expected {
+		t.Fatalf("expected %s, got %x", expected, mac)
+	}
+
+	if err := VerifyMAC([]byte("Jefe"), []byte("what do ya want for nothing?"), mac); err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+
+	if err := VerifyMAC([]byte("Jefe"), []byte("what do ya want for something?"), mac); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPasswordMismatch    = errors.New("password does not match")
+	ErrInvalidPasswordHash = errors.New("invalid password hash")
+)
+
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordBcrypt   = "bcrypt"
+)
+
+// PasswordOpts configures password hashing. The parameters are stored in the
+// hash, so they can be strengthened later without breaking existing hashes.
+type PasswordOpts struct {
+	Algorithm   string `default:"argon2id"` // "argon2id" or "bcrypt"
+	Memory      uint32 `default:"65536"`    // argon2id memory in KiB
+	Iterations  uint32 `default:"3"`        // argon2id passes
+	Parallelism uint8  `default:"4"`        // argon2id lanes
+	SaltLength  uint32 `default:"16"`
+	KeyLength   uint32 `default:"32"`
+	BcryptCost  int    `default:"12"`
+}
+
+// DefaultPasswordOpts are used by HashPassword and VerifyPassword
+var DefaultPasswordOpts = PasswordOpts{}
+
+func (o PasswordOpts) withDefaults() PasswordOpts {
+	if o.Algorithm == "" {
+		o.Algorithm = PasswordArgon2id
+	}
+	if o.Memory == 0 {
+		o.Memory = 64 * 1024
+	}
+	if o.Iterations == 0 {
+		o.Iterations = 3
+	}
+	if o.Parallelism == 0 {
+		o.Parallelism = 4
+	}
+	if o.SaltLength == 0 {
+		o.SaltLength = 16
+	}
+	if o.KeyLength == 0 {
+		o.KeyLength = 32
+	}
+	if o.BcryptCost == 0 {
+		o.BcryptCost = 12
+	}
+	return o
+}
+
+// HashPassword hashes a password with DefaultPasswordOpts
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordOpts.Hash(password)
+}
+
+// VerifyPassword checks a password against a hash from HashPassword, see PasswordOpts.Verify
+func VerifyPassword(password, hash string) (rehash bool, err error) {
+	return DefaultPasswordOpts.Verify(password, hash)
+}
+
+// Hash hashes a password. argon2id hashes use the PHC string format:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+//
+// bcrypt hashes use the usual $2a$ format.
+func (o PasswordOpts) Hash(password string) (string, error) {
+	o = o.withDefaults()
+
+	switch o.Algorithm {
+	case PasswordArgon2id:
+		salt := make([]byte, o.SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+
+		key := argon2.IDKey([]byte(password), salt, o.Iterations, o.Memory, o.Parallelism, o.KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, o.Memory, o.Iterations, o.Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	case PasswordBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), o.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAlg, o.Algorithm)
+	}
+}
+
+// Verify checks a password against an argon2id or bcrypt hash, whichever
+// algorithm the options select. It returns ErrPasswordMismatch for wrong
+// passwords. rehash is true when the password matches but the hash is weaker
+// than the options, so the caller should store a new hash while it has the
+// password:
+//
+//	rehash, err := crypt.VerifyPassword(password, user.PasswordHash)
+//	if err != nil {
+//		return err
+//	}
+//	if rehash {
+//		user.PasswordHash, _ = crypt.HashPassword(password)
+//	}
+func (o PasswordOpts) Verify(password, hash string) (rehash bool, err error) {
+	o = o.withDefaults()
+
+	if isBcryptHash(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, ErrPasswordMismatch
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+		}
+		return o.NeedsRehash(hash), nil
+	}
+
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, ErrPasswordMismatch
+	}
+
+	return o.NeedsRehash(hash), nil
+}
+
+// NeedsRehash reports whether a hash uses another algorithm or weaker
+// parameters than the options
+func (o PasswordOpts) NeedsRehash(hash string) bool {
+	o = o.withDefaults()
+
+	if isBcryptHash(hash) {
+		if o.Algorithm != PasswordBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost < o.BcryptCost
+	}
+
+	params, _, key, err := parseArgon2id(hash)
+	if err != nil || o.Algorithm != PasswordArgon2id {
+		return true
+	}
+
+	return params.Memory < o.Memory ||
+		params.Iterations < o.Iterations ||
+		params.Parallelism < o.Parallelism ||
+		uint32(len(key)) < o.KeyLength
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func parseArgon2id(hash string) (PasswordOpts, []byte, []byte, error) {
+	var params PasswordOpts
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != PasswordArgon2id {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	params.Algorithm = PasswordArgon2id
+	return params, salt, key, nil
+}
@@ -0,0 +1,91 @@
+package crypt
+
+import (
+	"strings"
+	"testing"
+)
+
+var testPasswordOpts = PasswordOpts{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$") {
+		t.Fatalf("unexpected hash format %s", hash)
+	}
+
+	rehash, err := VerifyPassword("correct horse", hash)
+	if err != nil {
+		t.Fatalf("failed to verify password: %v", err)
+	}
+	if rehash {
+		t.Fatalf("hash with default options should not need a rehash")
+	}
+
+	if _, err := VerifyPassword("wrong horse", hash); err != ErrPasswordMismatch {
+		t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+	}
+}
+
+func TestVerifyPasswordRehash(t *testing.T) {
+	weak, err := testPasswordOpts.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	stronger := testPasswordOpts
+	stronger.Iterations = 2
+
+	rehash, err := stronger.Verify("correct horse", weak)
+	if err != nil {
+		t.Fatalf("failed to verify password: %v", err)
+	}
+	if !rehash {
+		t.Fatalf("hash with fewer iterations should need a rehash")
+	}
+
+	if _, err := stronger.Verify("wrong horse", weak); err != ErrPasswordMismatch {
+		t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+	}
+}
+
+func TestVerifyPasswordBcrypt(t *testing.T) {
+	bcryptOpts := PasswordOpts{Algorithm: PasswordBcrypt, BcryptCost: 4}
+	hash, err := bcryptOpts.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	rehash, err := bcryptOpts.Verify("correct horse", hash)
+	if err != nil || rehash {
+		t.Fatalf("expected a match without rehash, got %v, %v", rehash, err)
+	}
+
+	// bcrypt hashes still verify after switching to argon2id, and need a rehash
+	rehash, err = testPasswordOpts.Verify("correct horse", hash)
+	if err != nil || !rehash {
+		t.Fatalf("expected a match with rehash, got %v, %v", rehash, err)
+	}
+
+	if _, err := testPasswordOpts.Verify("wrong horse", hash); err != ErrPasswordMismatch {
+		t.Fatalf("expected ErrPasswordMismatch, got %v", err)
+	}
+}
+
+func TestVerifyPasswordInvalidHash(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"plaintext",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5",
+	} {
+		if _, err := testPasswordOpts.Verify("password", hash); err != ErrInvalidPasswordHash {
+			t.Errorf("expected ErrInvalidPasswordHash for %q, got %v", hash, err)
+		}
+	}
+}
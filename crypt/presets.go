@@ -0,0 +1,71 @@
+package crypt
+
+// Preset is a named set of options matching how another runtime encrypts
+// data, so values can be decrypted on either side.
+type Preset struct {
+	Algorithm     string
+	Digest        string
+	KeySize       int
+	Iterations    int
+	KeyDerivation string
+}
+
+var (
+	// NodePBKDF2SHA1 matches crypto.pbkdf2Sync(pass, salt, 1000, 32, 'sha1')
+	// followed by crypto.createCipheriv('aes-256-cbc', key, iv)
+	NodePBKDF2SHA1 = Preset{
+		Algorithm:     "AES-256-CBC",
+		Digest:        "sha1",
+		KeySize:       256,
+		Iterations:    1000,
+		KeyDerivation: KeyDerivationPBKDF2,
+	}
+
+	// NodePBKDF2SHA256 is NodePBKDF2SHA1 with a sha256 digest
+	NodePBKDF2SHA256 = Preset{
+		Algorithm:     "AES-256-CBC",
+		Digest:        "sha256",
+		KeySize:       256,
+		Iterations:    1000,
+		KeyDerivation: KeyDerivationPBKDF2,
+	}
+
+	// NodePBKDF2SHA512 is NodePBKDF2SHA1 with a sha512 digest
+	NodePBKDF2SHA512 = Preset{
+		Algorithm:     "AES-256-CBC",
+		Digest:        "sha512",
+		KeySize:       256,
+		Iterations:    1000,
+		KeyDerivation: KeyDerivationPBKDF2,
+	}
+
+	// NodeLegacyCreateCipher matches the deprecated crypto.createCipher('aes-256-cbc', pass),
+	// which derives both key and IV with EVP_BytesToKey (md5, one round, no salt)
+	NodeLegacyCreateCipher = Preset{
+		Algorithm:     "AES-256-CBC",
+		Digest:        "md5",
+		KeySize:       256,
+		Iterations:    1,
+		KeyDerivation: KeyDerivationEVPBytesToKey,
+	}
+)
+
+// Opts builds CryptOpts from the preset. iv is ignored by NodeLegacyCreateCipher
+// when empty, since the IV is derived from the passphrase.
+func (p Preset) Opts(passphrase, salt, iv string) CryptOpts {
+	return CryptOpts{
+		IV:            iv,
+		Passphrase:    passphrase,
+		Salt:          salt,
+		Algorithm:     p.Algorithm,
+		Digest:        p.Digest,
+		KeySize:       p.KeySize,
+		Iterations:    p.Iterations,
+		KeyDerivation: p.KeyDerivation,
+	}
+}
+
+// NewFromPreset creates a Crypt using a compatibility preset
+func NewFromPreset(p Preset, passphrase, salt, iv string) *Crypt {
+	return New(p.Opts(passphrase, salt, iv))
+}
@@ -0,0 +1,47 @@
+package crypt
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// ciphertexts of "hello, world" generated with Node.js v20
+func TestNodePresets(t *testing.T) {
+	tests := []struct {
+		name       string
+		preset     Preset
+		salt       string
+		iv         string
+		ciphertext string
+	}{
+		{"pbkdf2 sha1", NodePBKDF2SHA1, "salt", "1234567890123456", "e2053b6944fc9d867fd56fb714af17ac"},
+		{"pbkdf2 sha256", NodePBKDF2SHA256, "salt", "1234567890123456", "12bcc9e724b4491a77cb0b4163e743bc"},
+		{"pbkdf2 sha512", NodePBKDF2SHA512, "salt", "1234567890123456", "bda0570ce936fb239bfb316ba3ce6e2a"},
+		{"legacy createCipher", NodeLegacyCreateCipher, "", "", "232d87641555b4589e5b88ef20cb4494"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crypt := NewFromPreset(tt.preset, "password", tt.salt, tt.iv)
+			expected, _ := hex.DecodeString(tt.ciphertext)
+
+			decrypted, err := crypt.Decrypt(expected)
+			if err != nil {
+				t.Fatalf("failed to decrypt: %v", err)
+			}
+
+			if string(decrypted) != "hello, world" {
+				t.Fatalf("unexpected plaintext: %q", decrypted)
+			}
+
+			encrypted, err := crypt.Encrypt([]byte("hello, world"))
+			if err != nil {
+				t.Fatalf("failed to encrypt: %v", err)
+			}
+
+			if hex.EncodeToString(encrypted) != tt.ciphertext {
+				t.Fatalf("ciphertext mismatch: %x", encrypted)
+			}
+		})
+	}
+}
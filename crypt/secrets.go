@@ -0,0 +1,328 @@
+package crypt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretsProvider looks up secrets such as passphrases and salts by name, so
+// they don't have to be passed through application code as plain strings.
+// Set CryptOpts.Secrets with PassphraseSecret and SaltSecret to have New
+// read them from a provider.
+type SecretsProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// RotationNotifier is implemented by providers reporting rotated secrets.
+// NewKeyring subscribes to it to rotate its key.
+type RotationNotifier interface {
+	OnRotate(fn func(name string))
+}
+
+// Rotations dispatches rotation notifications. The adapters of this package
+// embed it; call Notify from a webhook or watcher when a secret changes.
+type Rotations struct {
+	mu        sync.RWMutex
+	listeners []func(name string)
+}
+
+// OnRotate registers fn to be called with the name of every rotated secret
+func (r *Rotations) OnRotate(fn func(name string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Notify reports that the secret name was rotated
+func (r *Rotations) Notify(name string) {
+	r.mu.RLock()
+	listeners := slices.Clone(r.listeners)
+	r.mu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(name)
+	}
+}
+
+// withSecrets returns opts with the passphrase and salt read from
+// opts.Secrets, when it is set
+func (opts CryptOpts) withSecrets(ctx context.Context) (CryptOpts, error) {
+	if opts.Secrets == nil {
+		return opts, nil
+	}
+
+	if opts.PassphraseSecret != "" {
+		passphrase, err := opts.Secrets.Secret(ctx, opts.PassphraseSecret)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		opts.Passphrase = passphrase
+	}
+	if opts.SaltSecret != "" {
+		salt, err := opts.Secrets.Secret(ctx, opts.SaltSecret)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read salt: %w", err)
+		}
+		opts.Salt = salt
+	}
+
+	opts.Secrets = nil
+	return opts, nil
+}
+
+// EnvSecrets reads secrets from environment variables, named by Prefix
+// followed by the upper-cased secret name: with Prefix "APP_" the secret
+// "passphrase" is APP_PASSPHRASE
+type EnvSecrets struct {
+	Rotations
+	Prefix string
+}
+
+func (s *EnvSecrets) Secret(ctx context.Context, name string) (string, error) {
+	key := s.Prefix + strings.ToUpper(name)
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, key)
+	}
+	return value, nil
+}
+
+// FileSecrets reads secrets from one file per secret in Dir, as Docker and
+// Kubernetes mount them. Surrounding whitespace is trimmed.
+type FileSecrets struct {
+	Rotations
+	Dir string
+}
+
+func (s *FileSecrets) Secret(ctx context.Context, name string) (string, error) {
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, s.path(name))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (s *FileSecrets) path(name string) string {
+	return filepath.Join(s.Dir, filepath.Base(name))
+}
+
+// Watch polls the secret files every interval until ctx is done, notifying
+// rotations of the ones whose modification time changed. Mounted secrets
+// are updated in place, so this picks up rotations without a restart.
+func (s *FileSecrets) Watch(ctx context.Context, interval time.Duration) {
+	modTimes := make(map[string]time.Time)
+	scan := func(notify bool) {
+		entries, err := os.ReadDir(s.Dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			info, err := os.Stat(filepath.Join(s.Dir, entry.Name()))
+			if err != nil || info.IsDir() {
+				continue
+			}
+			previous, seen := modTimes[entry.Name()]
+			modTimes[entry.Name()] = info.ModTime()
+			if notify && (!seen || !previous.Equal(info.ModTime())) {
+				s.Notify(entry.Name())
+			}
+		}
+	}
+	scan(false)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			scan(true)
+		}
+	}
+}
+
+// VaultSecrets reads secrets from a HashiCorp Vault KV version 2 secret,
+// each secret name being a key of the secret at Path
+type VaultSecrets struct {
+	Rotations
+	Address string       // Vault address, e.g. "https://vault.example.com:8200"
+	Token   string       // Vault token
+	Mount   string       // KV mount (default "secret")
+	Path    string       // Secret path within the mount
+	Client  *http.Client // HTTP client (default http.DefaultClient)
+}
+
+func (s *VaultSecrets) Secret(ctx context.Context, name string) (string, error) {
+	mount := s.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	url := strings.TrimSuffix(s.Address, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.Trim(s.Path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", s.Token)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: vault secret %s", ErrSecretNotFound, s.Path)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded with status %d", res.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	value, ok := body.Data.Data[name].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: key %q of vault secret %s", ErrSecretNotFound, name, s.Path)
+	}
+	return value, nil
+}
+
+// KMSSecrets holds secrets encrypted by Envelope, whose data keys are
+// unwrapped by a KeyProvider backed by a KMS, so the secrets can live in
+// configuration and only the KMS can reveal them
+type KMSSecrets struct {
+	Rotations
+	Provider KeyProvider
+	Secrets  map[string][]byte // Envelope ciphertexts by name
+}
+
+func (s *KMSSecrets) Secret(ctx context.Context, name string) (string, error) {
+	encrypted, ok := s.Secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+
+	plaintext, err := NewEnvelope(s.Provider).DecryptContext(ctx, encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Keyring encrypts with a Crypt built from options whose passphrase and salt
+// come from a SecretsProvider, and rebuilds it when they are rotated. The
+// keys before a rotation are kept to decrypt existing data. It implements
+// Cipher and is safe for concurrent use.
+//
+//	secrets := &crypt.FileSecrets{Dir: "/run/secrets"}
+//	go secrets.Watch(ctx, time.Minute)
+//
+//	keys, err := crypt.NewKeyring(crypt.CryptOpts{
+//		Algorithm:        crypt.AlgorithmAESCTR,
+//		Digest:           "sha256",
+//		KeySize:          256,
+//		Iterations:       100000,
+//		Secrets:          secrets,
+//		PassphraseSecret: "crypt_passphrase",
+//		SaltSecret:       "crypt_salt",
+//	}, 2)
+type Keyring struct {
+	opts     CryptOpts
+	previous int
+
+	mu     sync.RWMutex
+	crypts []*Crypt // Current first, then the ones before rotations
+}
+
+// NewKeyring creates a Keyring keeping up to previous keys from before
+// rotations. When opts.Secrets is a RotationNotifier, rotations of the
+// passphrase or salt secret rotate the keyring.
+func NewKeyring(opts CryptOpts, previous int) (*Keyring, error) {
+	current, err := NewE(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Keyring{opts: opts, previous: previous, crypts: []*Crypt{current}}
+	if notifier, ok := opts.Secrets.(RotationNotifier); ok {
+		notifier.OnRotate(func(name string) {
+			if name == opts.PassphraseSecret || name == opts.SaltSecret {
+				// A failed rotation keeps the current key, Rotate reports the error
+				_ = k.Rotate()
+			}
+		})
+	}
+	return k, nil
+}
+
+// Rotate reads the secrets again and makes the resulting key current
+func (k *Keyring) Rotate() error {
+	next, err := NewE(k.opts)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.crypts = append([]*Crypt{next}, k.crypts[:min(len(k.crypts), k.previous)]...)
+	return nil
+}
+
+// Current returns the Crypt of the current key
+func (k *Keyring) Current() *Crypt {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.crypts[0]
+}
+
+// Encrypt encrypts data with the current key
+func (k *Keyring) Encrypt(data []byte) ([]byte, error) {
+	return k.Current().Encrypt(data)
+}
+
+// Decrypt decrypts data with the current key, then with the previous ones.
+// Only the HMAC algorithms reliably detect a wrong key; with AES-256-CBC a
+// previous key is tried when the padding is invalid.
+func (k *Keyring) Decrypt(data []byte) ([]byte, error) {
+	k.mu.RLock()
+	crypts := append([]*Crypt(nil), k.crypts...)
+	k.mu.RUnlock()
+
+	var firstErr error
+	for _, c := range crypts {
+		plaintext, err := c.Decrypt(data)
+		if err == nil {
+			return plaintext, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
@@ -0,0 +1,124 @@
+package crypt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func secretOpts(secrets SecretsProvider) CryptOpts {
+	return CryptOpts{
+		Algorithm:        AlgorithmAESCTR,
+		Digest:           "sha1",
+		KeySize:          256,
+		Iterations:       1000,
+		Secrets:          secrets,
+		PassphraseSecret: "passphrase",
+		SaltSecret:       "salt",
+	}
+}
+
+func TestSecretsProvider(t *testing.T) {
+	plain := New(CryptOpts{Passphrase: "password", Salt: "salt", Algorithm: AlgorithmAESCTR, Digest: "sha1", KeySize: 256, Iterations: 1000})
+	encrypted, err := plain.Encrypt([]byte("hello, world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypts := func(t *testing.T, secrets SecretsProvider) {
+		t.Helper()
+		c, err := NewE(secretOpts(secrets))
+		if err != nil {
+			t.Fatalf("NewE failed: %v", err)
+		}
+		decrypted, err := c.Decrypt(encrypted)
+		if err != nil || string(decrypted) != "hello, world" {
+			t.Errorf("expected the secrets to give the same key, got %q, %v", decrypted, err)
+		}
+	}
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("APP_PASSPHRASE", "password")
+		t.Setenv("APP_SALT", "salt")
+		decrypts(t, &EnvSecrets{Prefix: "APP_"})
+	})
+
+	t.Run("file", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "passphrase"), []byte("password\n"), 0600)
+		os.WriteFile(filepath.Join(dir, "salt"), []byte("salt"), 0600)
+		decrypts(t, &FileSecrets{Dir: dir})
+	})
+
+	t.Run("vault", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/secret/data/app/crypt" || r.Header.Get("X-Vault-Token") != "token" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(`{"data": {"data": {"passphrase": "password", "salt": "salt"}}}`))
+		}))
+		defer server.Close()
+
+		decrypts(t, &VaultSecrets{Address: server.URL, Token: "token", Path: "app/crypt"})
+	})
+
+	t.Run("kms", func(t *testing.T) {
+		provider, _ := NewLocalKeyProvider("kms-key", make([]byte, 32))
+		passphrase, _ := NewEnvelope(provider).Encrypt([]byte("password"))
+		salt, _ := NewEnvelope(provider).Encrypt([]byte("salt"))
+		decrypts(t, &KMSSecrets{Provider: provider, Secrets: map[string][]byte{"passphrase": passphrase, "salt": salt}})
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := NewE(secretOpts(&EnvSecrets{Prefix: "MISSING_"}))
+		if !errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("expected ErrSecretNotFound, got %v", err)
+		}
+	})
+}
+
+func TestKeyring(t *testing.T) {
+	dir := t.TempDir()
+	write := func(passphrase string) {
+		os.WriteFile(filepath.Join(dir, "passphrase"), []byte(passphrase), 0600)
+		os.WriteFile(filepath.Join(dir, "salt"), []byte("salt"), 0600)
+	}
+	write("first")
+
+	secrets := &FileSecrets{Dir: dir}
+	keys, err := NewKeyring(secretOpts(secrets), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, _ := keys.Encrypt([]byte("before"))
+	first := keys.Current()
+
+	write("second")
+	secrets.Notify("passphrase")
+	if keys.Current() == first {
+		t.Fatal("rotation notification should rotate the key")
+	}
+
+	after, _ := keys.Encrypt([]byte("after"))
+	if _, err := first.Decrypt(after); err == nil {
+		t.Error("data encrypted after the rotation should use the new key")
+	}
+	for _, encrypted := range [][]byte{before, after} {
+		if _, err := keys.Decrypt(encrypted); err != nil {
+			t.Errorf("keyring should decrypt with current and previous keys: %v", err)
+		}
+	}
+
+	write("third")
+	if err := keys.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Decrypt(before); err == nil {
+		t.Error("keys beyond previous should be dropped")
+	}
+}
@@ -0,0 +1,153 @@
+package crypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrNotStructPointer = errors.New("value must be a non-nil pointer to a struct")
+
+// EncryptStruct encrypts the fields of *v tagged `crypt:"true"` in place with
+// cipher, so ORM models can encrypt PII before they are saved:
+//
+//	type Customer struct {
+//		ID      int
+//		Email   string   `crypt:"true"`
+//		Phones  []string `crypt:"true"`
+//		Scan    []byte   `crypt:"true"`
+//		Address Address  // tagged fields of nested structs are encrypted too
+//	}
+//
+// Tagged fields must be strings, []byte, pointers to them or slices of them.
+// Strings are replaced by the base64 ciphertext, empty values are left empty.
+// Structs, pointers to structs and slices of structs are walked recursively.
+// Encrypting a struct twice encrypts its fields twice.
+func EncryptStruct(v any, cipher Cipher) error {
+	return transformStruct(v, func(data []byte) ([]byte, error) {
+		return cipher.Encrypt(data)
+	}, true)
+}
+
+// DecryptStruct reverses EncryptStruct
+func DecryptStruct(v any, cipher Cipher) error {
+	return transformStruct(v, func(data []byte) ([]byte, error) {
+		return cipher.Decrypt(data)
+	}, false)
+}
+
+type fieldTransform func(data []byte) ([]byte, error)
+
+func transformStruct(v any, transform fieldTransform, encrypt bool) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+
+	return walkStruct(value.Elem(), value.Elem().Type().Name(), transform, encrypt)
+}
+
+func walkStruct(value reflect.Value, path string, transform fieldTransform, encrypt bool) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := path + "." + field.Name
+		if field.Tag.Get("crypt") == "true" {
+			if err := transformField(value.Field(i), fieldPath, transform, encrypt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := walkValue(value.Field(i), fieldPath, transform, encrypt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkValue descends into untagged structs, pointers and slices
+func walkValue(value reflect.Value, path string, transform fieldTransform, encrypt bool) error {
+	switch value.Kind() {
+	case reflect.Struct:
+		return walkStruct(value, path, transform, encrypt)
+	case reflect.Pointer:
+		if value.IsNil() {
+			return nil
+		}
+		return walkValue(value.Elem(), path, transform, encrypt)
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := walkValue(value.Index(i), fmt.Sprintf("%s[%d]", path, i), transform, encrypt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// transformField encrypts or decrypts a tagged field
+func transformField(value reflect.Value, path string, transform fieldTransform, encrypt bool) error {
+	switch {
+	case value.Kind() == reflect.String:
+		if value.Len() == 0 {
+			return nil
+		}
+
+		if encrypt {
+			out, err := transform([]byte(value.String()))
+			if err != nil {
+				return fmt.Errorf("crypt: %s: %w", path, err)
+			}
+			value.SetString(base64.StdEncoding.EncodeToString(out))
+			return nil
+		}
+
+		data, err := base64.StdEncoding.DecodeString(value.String())
+		if err != nil {
+			return fmt.Errorf("crypt: %s: %w", path, err)
+		}
+		out, err := transform(data)
+		if err != nil {
+			return fmt.Errorf("crypt: %s: %w", path, err)
+		}
+		value.SetString(string(out))
+		return nil
+
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+		if value.Len() == 0 {
+			return nil
+		}
+
+		out, err := transform(value.Bytes())
+		if err != nil {
+			return fmt.Errorf("crypt: %s: %w", path, err)
+		}
+		value.SetBytes(out)
+		return nil
+
+	case value.Kind() == reflect.Pointer:
+		if value.IsNil() {
+			return nil
+		}
+		return transformField(value.Elem(), path, transform, encrypt)
+
+	case value.Kind() == reflect.Slice || value.Kind() == reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := transformField(value.Index(i), fmt.Sprintf("%s[%d]", path, i), transform, encrypt); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("crypt: %s: tagged field must be a string or []byte, got %s", path, value.Type())
+	}
+}
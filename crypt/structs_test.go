@@ -0,0 +1,95 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type testAddress struct {
+	Street string `crypt:"true"`
+	City   string
+}
+
+type testCustomer struct {
+	ID        int
+	Email     string   `crypt:"true"`
+	Nickname  *string  `crypt:"true"`
+	Phones    []string `crypt:"true"`
+	Scan      []byte   `crypt:"true"`
+	Empty     string   `crypt:"true"`
+	Address   testAddress
+	Previous  []*testAddress
+	Unchanged string
+}
+
+func newTestEnvelope(t *testing.T) *Envelope {
+	provider, err := NewLocalKeyProvider("test", bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("failed to create key provider: %v", err)
+	}
+	return NewEnvelope(provider)
+}
+
+func TestEncryptStruct(t *testing.T) {
+	cipher := newTestEnvelope(t)
+	nickname := "jd"
+	customer := testCustomer{
+		ID:        1,
+		Email:     "jane@example.com",
+		Nickname:  &nickname,
+		Phones:    []string{"+1 555 0100", "+1 555 0101"},
+		Scan:      []byte("passport"),
+		Address:   testAddress{Street: "1 Main St", City: "Springfield"},
+		Previous:  []*testAddress{{Street: "2 Elm St", City: "Shelbyville"}, nil},
+		Unchanged: "plain",
+	}
+
+	if err := EncryptStruct(&customer, cipher); err != nil {
+		t.Fatalf("failed to encrypt struct: %v", err)
+	}
+
+	if customer.Email == "jane@example.com" || *customer.Nickname == "jd" || customer.Phones[1] == "+1 555 0101" {
+		t.Errorf("tagged strings should be encrypted: %+v", customer)
+	}
+	if bytes.Equal(customer.Scan, []byte("passport")) {
+		t.Errorf("tagged bytes should be encrypted")
+	}
+	if customer.Address.Street == "1 Main St" || customer.Previous[0].Street == "2 Elm St" {
+		t.Errorf("nested tagged fields should be encrypted")
+	}
+	if customer.Empty != "" || customer.Address.City != "Springfield" || customer.Unchanged != "plain" || customer.ID != 1 {
+		t.Errorf("untagged and empty fields should be unchanged: %+v", customer)
+	}
+
+	if err := DecryptStruct(&customer, cipher); err != nil {
+		t.Fatalf("failed to decrypt struct: %v", err)
+	}
+
+	if customer.Email != "jane@example.com" || *customer.Nickname != "jd" || customer.Phones[1] != "+1 555 0101" {
+		t.Errorf("tagged strings should be decrypted: %+v", customer)
+	}
+	if string(customer.Scan) != "passport" || customer.Address.Street != "1 Main St" || customer.Previous[0].Street != "2 Elm St" {
+		t.Errorf("fields should be decrypted: %+v", customer)
+	}
+}
+
+func TestEncryptStructErrors(t *testing.T) {
+	cipher := newTestEnvelope(t)
+
+	if err := EncryptStruct(testCustomer{}, cipher); !errors.Is(err, ErrNotStructPointer) {
+		t.Errorf("expected ErrNotStructPointer, got %v", err)
+	}
+
+	invalid := struct {
+		Age int `crypt:"true"`
+	}{Age: 42}
+	if err := EncryptStruct(&invalid, cipher); err == nil {
+		t.Errorf("expected an error for a tagged int")
+	}
+
+	tampered := testCustomer{Email: "bm90IGVuY3J5cHRlZA=="}
+	if err := DecryptStruct(&tampered, cipher); err == nil {
+		t.Errorf("expected an error for a value that was not encrypted")
+	}
+}
@@ -0,0 +1,150 @@
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// TokenEncoding is the text encoding of a RandomToken
+type TokenEncoding string
+
+const (
+	TokenBase64URL TokenEncoding = "base64url" // Unpadded, URL and cookie safe
+	TokenHex       TokenEncoding = "hex"
+	TokenBase32    TokenEncoding = "base32" // Unpadded lowercase, case-insensitive systems
+	TokenBase62    TokenEncoding = "base62" // Alphanumeric only, selects with a double click
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// RandomToken returns n cryptographically secure random bytes in the given
+// encoding, e.g. RandomToken(32, TokenBase64URL) for a session id or CSRF token
+func RandomToken(n int, encoding TokenEncoding) (string, error) {
+	if n <= 0 {
+		return "", fmt.Errorf("token length must be positive, got %d", n)
+	}
+
+	if encoding == TokenBase62 {
+		// base62 carries log2(62) bits per character
+		return randomBase62((n*8*1000 + 5953) / 5954)
+	}
+
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	switch encoding {
+	case TokenBase64URL, "":
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	case TokenHex:
+		return hex.EncodeToString(data), nil
+	case TokenBase32:
+		return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(data)), nil
+	default:
+		return "", fmt.Errorf("unsupported token encoding %q", encoding)
+	}
+}
+
+// randomBase62 returns n uniformly distributed base62 characters
+func randomBase62(n int) (string, error) {
+	out := make([]byte, 0, n)
+	buf := make([]byte, n+n/4+8)
+
+	for len(out) < n {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			// 248 = 4 * 62, rejecting the rest avoids modulo bias
+			if b < 248 && len(out) < n {
+				out = append(out, base62Alphabet[b%62])
+			}
+		}
+	}
+
+	return string(out), nil
+}
+
+const (
+	apiKeyBodyLength     = 30 // About 178 bits of entropy
+	apiKeyChecksumLength = 6
+)
+
+// NewAPIKey returns a random API key tagged with prefix: for "sk_live" it is
+// "sk_live_" followed by 30 random base62 characters and a 6 character CRC32
+// checksum, so scanners and services can reject malformed keys without a
+// lookup. Store HashAPIKey(key), never the key itself.
+func NewAPIKey(prefix string) (string, error) {
+	if !validAPIKeyPrefix(prefix) {
+		return "", fmt.Errorf("%w: prefix must be lowercase letters, digits and underscores", ErrInvalidAPIKey)
+	}
+
+	body, err := randomBase62(apiKeyBodyLength)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + "_" + body + apiKeyChecksum(body), nil
+}
+
+// ParseAPIKey checks the format and checksum of a key from NewAPIKey and
+// returns its prefix
+func ParseAPIKey(key string) (prefix string, err error) {
+	i := strings.LastIndex(key, "_")
+	if i <= 0 {
+		return "", ErrInvalidAPIKey
+	}
+
+	prefix, rest := key[:i], key[i+1:]
+	if !validAPIKeyPrefix(prefix) || len(rest) != apiKeyBodyLength+apiKeyChecksumLength {
+		return "", ErrInvalidAPIKey
+	}
+
+	body, checksum := rest[:apiKeyBodyLength], rest[apiKeyBodyLength:]
+	if apiKeyChecksum(body) != checksum {
+		return "", ErrInvalidAPIKey
+	}
+
+	return prefix, nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of an API key, for storing
+// keys and looking them up in a schema.APIKeyStore, which hashes with it.
+// API keys are high entropy random values, so a fast hash is sufficient.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyChecksum(body string) string {
+	sum := crc32.ChecksumIEEE([]byte(body))
+
+	out := make([]byte, apiKeyChecksumLength)
+	for i := apiKeyChecksumLength - 1; i >= 0; i-- {
+		out[i] = base62Alphabet[sum%62]
+		sum /= 62
+	}
+	return string(out)
+}
+
+func validAPIKeyPrefix(prefix string) bool {
+	if prefix == "" || prefix[0] == '_' || prefix[len(prefix)-1] == '_' {
+		return false
+	}
+	for _, r := range prefix {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
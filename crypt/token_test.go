@@ -0,0 +1,75 @@
+package crypt
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRandomToken(t *testing.T) {
+	cases := map[TokenEncoding]*regexp.Regexp{
+		TokenBase64URL: regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`),
+		TokenHex:       regexp.MustCompile(`^[0-9a-f]{64}$`),
+		TokenBase32:    regexp.MustCompile(`^[a-z2-7]{52}$`),
+		TokenBase62:    regexp.MustCompile(`^[A-Za-z0-9]{43}$`),
+	}
+
+	for encoding, pattern := range cases {
+		first, err := RandomToken(32, encoding)
+		if err != nil {
+			t.Fatalf("failed to generate %s token: %v", encoding, err)
+		}
+
+		if !pattern.MatchString(first) {
+			t.Errorf("%s token %q does not match %s", encoding, first, pattern)
+		}
+
+		second, _ := RandomToken(32, encoding)
+		if first == second {
+			t.Errorf("%s tokens should differ", encoding)
+		}
+	}
+
+	if _, err := RandomToken(32, "base58"); err == nil {
+		t.Errorf("expected an error for an unsupported encoding")
+	}
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key, err := NewAPIKey("sk_live")
+	if err != nil {
+		t.Fatalf("failed to generate api key: %v", err)
+	}
+
+	if !strings.HasPrefix(key, "sk_live_") || len(key) != len("sk_live_")+36 {
+		t.Fatalf("unexpected api key %q", key)
+	}
+
+	prefix, err := ParseAPIKey(key)
+	if err != nil || prefix != "sk_live" {
+		t.Fatalf("expected prefix sk_live, got %q, %v", prefix, err)
+	}
+
+	typo := []byte(key)
+	if typo[10] == 'a' {
+		typo[10] = 'b'
+	} else {
+		typo[10] = 'a'
+	}
+	if _, err := ParseAPIKey(string(typo)); err != ErrInvalidAPIKey {
+		t.Errorf("expected ErrInvalidAPIKey for a changed key, got %v", err)
+	}
+
+	for _, prefix := range []string{"", "SK", "sk-live", "_sk"} {
+		if _, err := NewAPIKey(prefix); err == nil {
+			t.Errorf("expected an error for prefix %q", prefix)
+		}
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	// matches schema.HashAPIKey
+	if HashAPIKey("key") != "2c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683" {
+		t.Errorf("unexpected hash %s", HashAPIKey("key"))
+	}
+}
@@ -0,0 +1,181 @@
+package inject
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Bind registers TImpl as the implementation of TInterface. Every resolution
+// of TInterface builds a new TImpl and auto-wires it: exported fields whose
+// type is registered in the container are set from it, fields tagged
+// `inject:"-"` are left alone. TImpl is usually a pointer to a struct.
+//
+//	inject.Register[Logger](c, NewConsoleLogger)
+//	inject.Bind[UserRepository, *SqlUserRepository](c)
+//
+// Fields tagged with a name are set from the first service registered under
+// it, like GetNamed, to depend on one of several instances of a type:
+//
+//	type SqlUserRepository struct {
+//		Logger  Logger
+//		Primary *sql.DB `inject:"primary"`
+//		Replica *sql.DB `inject:"replica"`
+//	}
+//
+// Unlike fields wired by type, named fields are required: resolving
+// TInterface fails with ErrServiceNotFound when nothing of the field's type
+// is registered under the name. Bind panics when TImpl does not implement
+// TInterface.
+func Bind[TInterface any, TImpl any](c *Container) {
+	interfaceType := reflect.TypeOf((*TInterface)(nil)).Elem()
+	implType := reflect.TypeOf((*TImpl)(nil)).Elem()
+	if !implType.AssignableTo(interfaceType) {
+		panic(fmt.Sprintf("inject: %s does not implement %s", implType, interfaceType))
+	}
+
+	fields := wiredFields(implType)
+	Register[TInterface](c, func(ctx context.Context, c *Container) (TInterface, error) {
+		impl, err := c.construct(implType, fields)
+		if err != nil {
+			var zero TInterface
+			return zero, err
+		}
+		return any(impl.Interface().(TImpl)).(TInterface), nil
+	})
+}
+
+// wiredField is a field construct sets
+type wiredField struct {
+	index int
+	name  string // Name the service is registered under, empty to resolve by type
+}
+
+// wiredFields returns the fields construct wires for type t, found once when
+// binding
+func wiredFields(t reflect.Type) []wiredField {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []wiredField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("inject")
+		if !field.IsExported() || field.Anonymous || name == "-" {
+			continue
+		}
+		fields = append(fields, wiredField{index: i, name: name})
+	}
+	return fields
+}
+
+// construct builds a value of type t with its wired fields set from the container
+func (c *Container) construct(t reflect.Type, fields []wiredField) (reflect.Value, error) {
+	switch {
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct:
+		value := reflect.New(t.Elem())
+		return value, c.wireFields(value.Elem(), fields)
+	case t.Kind() == reflect.Struct:
+		value := reflect.New(t).Elem()
+		return value, c.wireFields(value, fields)
+	default:
+		return reflect.New(t).Elem(), nil
+	}
+}
+
+// wireFields sets the fields of a struct value that have a registered type,
+// or a service registered under their name. Fields wired by type are left
+// alone when it isn't registered, a missing named service is an error.
+func (c *Container) wireFields(value reflect.Value, fields []wiredField) error {
+	for _, wired := range fields {
+		field := value.Field(wired.index)
+
+		if wired.name != "" {
+			service, err := c.resolveNamedType(wired.name, field.Type())
+			if err != nil {
+				return fmt.Errorf("%w: field %s", err, value.Type().Field(wired.index).Name)
+			}
+			field.Set(service)
+		} else if service, ok := c.resolveType(field.Type()); ok {
+			field.Set(service)
+		}
+	}
+	return nil
+}
+
+// resolveType is the reflection counterpart of Get, it calls factories and
+// context factories and returns values as they are
+func (c *Container) resolveType(t reflect.Type) (reflect.Value, bool) {
+	c.record(t)
+	result, _, err := c.buildType(context.Background(), t)
+	if err != nil || !result.IsValid() || (isNillable(result.Kind()) && result.IsNil()) {
+		return reflect.Value{}, false
+	}
+	return result, true
+}
+
+// buildType resolves the service registered for t with ctx, reporting whether
+// it was built by a factory rather than stored as a value
+func (c *Container) buildType(ctx context.Context, t reflect.Type) (reflect.Value, bool, error) {
+	reg, ok := c.services[t]
+	if !ok || reg.service == nil {
+		return reflect.Value{}, false, ErrServiceNotFound
+	}
+
+	switch reg.kind {
+	case serviceValue:
+		return reflect.ValueOf(reg.service), false, nil
+	case serviceFactory, serviceContextFactory:
+		result, err := reg.build(ctx, c)
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		return reflect.ValueOf(result), true, nil
+	}
+	return c.callService(ctx, reg.service, t)
+}
+
+// callService calls service when it is a factory or context factory of t
+func (c *Container) callService(ctx context.Context, service any, t reflect.Type) (reflect.Value, bool, error) {
+	serviceValue := reflect.ValueOf(service)
+	serviceType := serviceValue.Type()
+
+	if serviceType.Kind() == reflect.Func &&
+		serviceType.NumIn() == 1 &&
+		serviceType.In(0) == reflect.TypeOf((*Container)(nil)) &&
+		serviceType.NumOut() > 0 &&
+		serviceType.Out(0).AssignableTo(t) {
+		return serviceValue.Call([]reflect.Value{reflect.ValueOf(c)})[0], true, nil
+	}
+
+	if serviceType.Kind() == reflect.Func &&
+		serviceType.NumIn() == 2 &&
+		serviceType.In(0) == reflect.TypeOf((*context.Context)(nil)).Elem() &&
+		serviceType.In(1) == reflect.TypeOf((*Container)(nil)) &&
+		serviceType.NumOut() == 2 &&
+		serviceType.Out(0).AssignableTo(t) {
+		results := serviceValue.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(c)})
+		if !results[1].IsNil() {
+			return reflect.Value{}, true, results[1].Interface().(error)
+		}
+		return results[0], true, nil
+	}
+
+	if serviceType.AssignableTo(t) {
+		return serviceValue, false, nil
+	}
+
+	return reflect.Value{}, false, ErrInvalidServiceType
+}
+
+func isNillable(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package inject
+
+import (
+	"errors"
+	"testing"
+)
+
+type IRepository interface {
+	Name() string
+}
+
+type Repository struct {
+	Service IService
+	Size    int
+	Skipped IService `inject:"-"`
+	private IService
+}
+
+func (r *Repository) Name() string {
+	return "repository"
+}
+
+func TestBind(t *testing.T) {
+	t.Run("should resolve the implementation as the interface", func(t *testing.T) {
+		container := NewContainer()
+		Bind[IRepository, *Repository](container)
+
+		repository, err := Resolve[IRepository](container)
+		if err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if repository.Name() != "repository" {
+			t.Errorf("name should be repository, got %s", repository.Name())
+		}
+	})
+
+	t.Run("should wire registered exported fields", func(t *testing.T) {
+		container := NewContainer()
+		Register[IService](container, NewTestService)
+		Register[int](container, 3)
+		Bind[IRepository, *Repository](container)
+
+		repository := Get[IRepository](container).(*Repository)
+		if repository.Service == nil {
+			t.Errorf("Service should be wired")
+		}
+		if repository.Size != 3 {
+			t.Errorf("Size should be 3, got %d", repository.Size)
+		}
+		if repository.Skipped != nil {
+			t.Errorf("Skipped should not be wired")
+		}
+		if repository.private != nil {
+			t.Errorf("private should not be wired")
+		}
+	})
+
+	t.Run("should build a new implementation per resolution", func(t *testing.T) {
+		container := NewContainer()
+		Bind[IRepository, *Repository](container)
+
+		if Get[IRepository](container) == Get[IRepository](container) {
+			t.Errorf("resolutions should return different instances")
+		}
+	})
+
+	t.Run("should panic when the implementation does not satisfy the interface", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Bind should panic")
+			}
+		}()
+		Bind[IRepository, Repository](NewContainer())
+	})
+}
+
+type ReplicatedRepository struct {
+	Primary IService `inject:"primary"`
+	Replica IService `inject:"replica"`
+}
+
+func (r *ReplicatedRepository) Name() string {
+	return "replicated"
+}
+
+func TestBindNamed(t *testing.T) {
+	t.Run("should wire fields tagged with a name from named services", func(t *testing.T) {
+		primary, replica := &Service{}, &Service{}
+		container := NewContainer()
+		Register[IService](container, NewTestService)
+		RegisterNamed[IService](container, "primary", IService(primary))
+		RegisterNamed[IService](container, "replica", func(c *Container) IService { return replica })
+		Bind[IRepository, *ReplicatedRepository](container)
+
+		repository := Get[IRepository](container).(*ReplicatedRepository)
+		if repository.Primary != primary {
+			t.Errorf("Primary should be the primary service, got %v", repository.Primary)
+		}
+		if repository.Replica != replica {
+			t.Errorf("Replica should be the replica service, got %v", repository.Replica)
+		}
+	})
+
+	t.Run("should fail for missing named services", func(t *testing.T) {
+		container := NewContainer()
+		RegisterNamed[IService](container, "primary", IService(&Service{}))
+		Bind[IRepository, *ReplicatedRepository](container)
+
+		if _, err := Resolve[IRepository](container); !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should be ErrServiceNotFound, got %v", err)
+		}
+		if repository := Get[IRepository](container); repository != nil {
+			t.Errorf("Get should not return a partly wired repository, got %v", repository)
+		}
+	})
+
+	t.Run("should fail for named services of another type", func(t *testing.T) {
+		container := NewContainer()
+		RegisterNamed[IService](container, "primary", IService(&Service{}))
+		Bind[IRepository, *SizedRepository](container)
+
+		if _, err := Resolve[IRepository](container); !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should be ErrServiceNotFound, got %v", err)
+		}
+	})
+}
+
+type SizedRepository struct {
+	Size int `inject:"primary"`
+}
+
+func (r *SizedRepository) Name() string {
+	return "sized"
+}
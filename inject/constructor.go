@@ -0,0 +1,93 @@
+package inject
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// RegisterConstructor registers constructor as the factory of T, calling it
+// with its parameters resolved from the container on every resolution:
+// Named parameters from the service registered under their name, a
+// *Container and a context.Context with the ones T is resolved with, and
+// any other type like Get. constructor returns a T, or a T and an error:
+//
+//	func NewUserRepository(logger Logger, db inject.Named[*sql.DB, Primary]) (*SqlUserRepository, error)
+//
+//	inject.RegisterConstructor[UserRepository](c, NewUserRepository)
+//
+// Resolving T fails with ErrServiceNotFound when a parameter's service isn't
+// registered or isn't of the parameter's type, and with the constructor's
+// error when it fails. RegisterConstructor panics when constructor isn't a
+// function returning a T.
+func RegisterConstructor[T any](c *Container, constructor any) {
+	serviceType := reflect.TypeOf((*T)(nil)).Elem()
+	constructorValue := reflect.ValueOf(constructor)
+	constructorType := reflect.TypeOf(constructor)
+	if constructorType == nil || constructorType.Kind() != reflect.Func || constructorType.IsVariadic() ||
+		constructorType.NumOut() == 0 || constructorType.NumOut() > 2 ||
+		!constructorType.Out(0).AssignableTo(serviceType) ||
+		constructorType.NumOut() == 2 && constructorType.Out(1) != errorType {
+		panic(fmt.Sprintf("inject: %T is not a constructor of %s", constructor, serviceType))
+	}
+
+	Register[T](c, func(ctx context.Context, c *Container) (T, error) {
+		var zero T
+		args := make([]reflect.Value, constructorType.NumIn())
+		for i := range args {
+			arg, err := c.resolveParameter(ctx, constructorType.In(i))
+			if err != nil {
+				return zero, fmt.Errorf("%w: parameter %d of %s", err, i, constructorType)
+			}
+			args[i] = arg
+		}
+
+		results := constructorValue.Call(args)
+		if len(results) == 2 && !results[1].IsNil() {
+			return zero, results[1].Interface().(error)
+		}
+		result, _ := results[0].Interface().(T)
+		return result, nil
+	})
+}
+
+var (
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+	namedParameterType = reflect.TypeOf((*namedParameter)(nil)).Elem()
+)
+
+// resolveParameter resolves a constructor parameter of type t
+func (c *Container) resolveParameter(ctx context.Context, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case t == reflect.TypeOf(c):
+		return reflect.ValueOf(c), nil
+	case t == reflect.TypeOf((*context.Context)(nil)).Elem():
+		return reflect.ValueOf(&ctx).Elem(), nil
+	case t.Implements(namedParameterType):
+		name, serviceType := reflect.Zero(t).Interface().(namedParameter).service()
+		service, err := c.resolveNamedType(name, serviceType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		param := reflect.New(t).Elem()
+		param.Field(0).Set(service)
+		return param, nil
+	}
+
+	c.record(t)
+	if reg, ok := c.services[t]; ok && reg.service == nil {
+		return reflect.Zero(t), nil
+	}
+	result, _, err := c.buildType(ctx, t)
+	switch {
+	case err == ErrInvalidServiceType:
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrServiceNotFound, t)
+	case err == ErrServiceNotFound:
+		return reflect.Value{}, fmt.Errorf("%w: %s", err, t)
+	case err != nil:
+		return reflect.Value{}, err
+	case !result.IsValid():
+		return reflect.Zero(t), nil
+	}
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type Primary struct{}
+
+func (Primary) Name() string { return "primary" }
+
+type Replica struct{}
+
+func (Replica) Name() string { return "replica" }
+
+type PortName struct{}
+
+func (PortName) Name() string { return "port" }
+
+func NewReplicatedRepository(primary Named[IService, Primary], replica Named[IService, Replica]) *ReplicatedRepository {
+	return &ReplicatedRepository{Primary: primary.Value, Replica: replica.Value}
+}
+
+func TestRegisterConstructor(t *testing.T) {
+	t.Run("should call the constructor with named services", func(t *testing.T) {
+		primary, replica := &Service{}, &Service{}
+		container := NewContainer()
+		RegisterNamed[IService](container, "primary", IService(primary))
+		RegisterNamed[IService](container, "replica", func(c *Container) IService { return replica })
+		RegisterConstructor[IRepository](container, NewReplicatedRepository)
+
+		repository, err := Resolve[IRepository](container)
+		if err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if repository.(*ReplicatedRepository).Primary != primary {
+			t.Errorf("Primary should be the primary service")
+		}
+		if repository.(*ReplicatedRepository).Replica != replica {
+			t.Errorf("Replica should be the replica service")
+		}
+	})
+
+	t.Run("should resolve other parameters by type", func(t *testing.T) {
+		container := NewContainer()
+		Register[IService](container, NewTestService)
+		RegisterNamed[int](container, "port", 8080)
+
+		type server struct {
+			service IService
+			port    int
+			tenant  any
+		}
+		RegisterConstructor[*server](container, func(ctx context.Context, c *Container, service IService, port Named[int, PortName]) *server {
+			return &server{service: service, port: port.Value, tenant: ctx.Value("tenant")}
+		})
+
+		ctx := context.WithValue(context.Background(), "tenant", "acme")
+		s, err := ResolveCtx[*server](ctx, container)
+		if err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if s.service == nil || s.port != 8080 || s.tenant != "acme" {
+			t.Errorf("server should be wired, got %+v", s)
+		}
+	})
+
+	t.Run("should fail for missing or mistyped services", func(t *testing.T) {
+		container := NewContainer()
+		RegisterConstructor[IRepository](container, NewReplicatedRepository)
+
+		if _, err := Resolve[IRepository](container); !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should be ErrServiceNotFound for a missing name, got %v", err)
+		}
+
+		RegisterNamed[int](container, "port", "8080")
+		RegisterConstructor[int](container, func(port Named[int, PortName]) int { return port.Value })
+		if _, err := Resolve[int](container); !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should be ErrServiceNotFound for a service of another type, got %v", err)
+		}
+
+		RegisterConstructor[string](container, func(service IService) string { return "" })
+		if _, err := Resolve[string](container); !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should be ErrServiceNotFound for a missing type, got %v", err)
+		}
+	})
+
+	t.Run("should return the constructor's error", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		container := NewContainer()
+		RegisterConstructor[IService](container, func() (IService, error) { return nil, errFailed })
+
+		if _, err := Resolve[IService](container); !errors.Is(err, errFailed) {
+			t.Errorf("error should be the constructor's, got %v", err)
+		}
+	})
+
+	t.Run("should panic for functions that don't return the service", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("RegisterConstructor should panic")
+			}
+		}()
+		RegisterConstructor[IRepository](NewContainer(), func() int { return 0 })
+	})
+}
@@ -1,6 +1,7 @@
 package inject
 
 import (
+	"context"
 	"errors"
 	"reflect"
 )
@@ -13,14 +14,18 @@ var (
 var container *Container
 
 type Container struct {
-	services map[any]interface{}
+	services   map[any]registration
+	singletons map[reflect.Type]RegistrationValue // Factories of cached singletons, for Refresh
+	refreshed  map[reflect.Type]*refreshedSingleton
+	eager      []reflect.Type
+	recording  *Recording
 }
 
 type RegistrationValue interface{}
 
 func NewContainer() *Container {
 	return &Container{
-		services: make(map[any]interface{}),
+		services: make(map[any]registration),
 	}
 }
 
@@ -31,14 +36,14 @@ func (c *Container) Reset() *Container {
 
 func (c *Container) CreateChild() *Container {
 	return &Container{
-		services: make(map[any]interface{}),
+		services: make(map[any]registration),
 	}
 }
 
 func Default() *Container {
 	if container == nil {
 		container = &Container{
-			services: make(map[any]interface{}),
+			services: make(map[any]registration),
 		}
 	}
 
@@ -46,17 +51,18 @@ func Default() *Container {
 }
 
 func Register[T any](c *Container, factory RegistrationValue) {
-	c.services[reflect.TypeOf((*T)(nil)).Elem()] = factory
+	setService[T](c, reflect.TypeOf((*T)(nil)).Elem(), factory)
+	c.forgetSingleton(reflect.TypeOf((*T)(nil)).Elem())
 }
 
 func RegisterNamed[T any](c *Container, name interface{}, factory RegistrationValue) {
 	// check if we already have a service with this name
 	if existing, ok := c.services[name]; ok {
 		// existing should be a slice of factories
-		factories := existing.([]RegistrationValue)
-		c.services[name] = append(factories, factory)
+		factories := existing.service.([]namedService)
+		c.services[name] = registration{service: append(factories, newNamedService[T](factory))}
 	} else {
-		c.services[name] = []RegistrationValue{factory}
+		c.services[name] = registration{service: []namedService{newNamedService[T](factory)}}
 	}
 }
 
@@ -72,115 +78,59 @@ func RegisterSingleton[T any](c *Container, factory RegistrationValue) {
 		// call the factory function with the container
 		results := factoryValue.Call([]reflect.Value{reflect.ValueOf(c)})
 		if len(results) > 0 {
-			c.services[reflect.TypeOf((*T)(nil)).Elem()] = results[0].Interface()
+			setService[T](c, reflect.TypeOf((*T)(nil)).Elem(), results[0].Interface())
+			c.forgetSingleton(reflect.TypeOf((*T)(nil)).Elem())
+			c.rememberSingleton(reflect.TypeOf((*T)(nil)).Elem(), factory)
 		}
 	} else {
 		// store the value directly
-		c.services[reflect.TypeOf((*T)(nil)).Elem()] = factory
+		setService[T](c, reflect.TypeOf((*T)(nil)).Elem(), factory)
+		c.forgetSingleton(reflect.TypeOf((*T)(nil)).Elem())
 	}
 }
 
 func Get[T any](c *Container) T {
-	var zero T
-	service, ok := c.services[reflect.TypeOf((*T)(nil)).Elem()]
-	if !ok {
-		return zero
-	}
-
-	// Check if it's a factory function (transient)
-	if factory, ok := service.(func(c *Container) T); ok {
-		return factory(c)
-	}
-
-	// otherwise, its a singleton instance
-	result, ok := service.(T)
-	if !ok {
-		return zero
-	}
-
+	result, _ := TryGet[T](c)
 	return result
 }
 
 func GetNamed[T any](c *Container, name interface{}) T {
 	var zero T
-	service, ok := c.services[name]
-	if !ok {
+	c.record(name)
+	services, ok := c.services[name].service.([]namedService)
+	if !ok || len(services) == 0 {
 		return zero
 	}
 
 	// Named services are stored as slices, get the first one
-	if factories, ok := service.([]RegistrationValue); ok {
-		if len(factories) == 0 {
-			return zero
-		}
-
-		factory := factories[0]
-
-		// Check if it's a factory function
-		factoryValue := reflect.ValueOf(factory)
-		factoryType := factoryValue.Type()
-
-		if factoryType.Kind() == reflect.Func &&
-			factoryType.NumIn() == 1 &&
-			factoryType.In(0) == reflect.TypeOf((*Container)(nil)) {
-			// call the factory function with the container
-			results := factoryValue.Call([]reflect.Value{reflect.ValueOf(c)})
-			if len(results) > 0 {
-				if result, ok := results[0].Interface().(T); ok {
-					return result
-				}
-			}
-		} else {
-			// store the value directly
-			if result, ok := factory.(T); ok {
-				return result
-			}
-		}
+	if result, ok := services[0].resolve(c).(T); ok {
+		return result
 	}
 
 	return zero
 }
 
 func GetAllNamed[T any](c *Container, name interface{}) []T {
-	var result []T
-	services, ok := c.services[name]
+	c.record(name)
+	services, ok := c.services[name].service.([]namedService)
 	if !ok {
 		return []T{}
 	}
 
-	if factories, ok := services.([]RegistrationValue); ok {
-		for _, factory := range factories {
-			// Check if it's a factory function
-			factoryValue := reflect.ValueOf(factory)
-			factoryType := factoryValue.Type()
-
-			if factoryType.Kind() == reflect.Func &&
-				factoryType.NumIn() == 1 &&
-				factoryType.In(0) == reflect.TypeOf((*Container)(nil)) {
-				// call the factory function with the container
-				results := factoryValue.Call([]reflect.Value{reflect.ValueOf(c)})
-				if len(results) > 0 {
-					if service, ok := results[0].Interface().(T); ok {
-						result = append(result, service)
-					}
-				}
-			} else {
-				// store the value directly
-				if service, ok := factory.(T); ok {
-					result = append(result, service)
-				}
-			}
+	var result []T
+	for _, service := range services {
+		if service, ok := service.resolve(c).(T); ok {
+			result = append(result, service)
 		}
-		return result
 	}
-
-	return []T{}
+	return result
 }
 
 func Resolve[T any](c *Container) (T, error) {
 	var zero T
 	requestedType := reflect.TypeOf((*T)(nil)).Elem()
-	service, ok := c.services[requestedType]
+	c.record(requestedType)
+	reg, ok := c.services[requestedType]
 	if !ok {
 		// Check if any type-based services are registered (exclude named services)
 		hasTypeBasedServices := false
@@ -198,13 +148,11 @@ func Resolve[T any](c *Container) (T, error) {
 		return zero, ErrInvalidServiceType
 	}
 
-	// Check if it's a factory function (transient)
-	if factory, ok := service.(func(c *Container) T); ok {
-		return factory(c), nil
+	// Factories are called, context factories without a request context
+	result, ok, err := resolveRegistration[T](context.Background(), c, reg)
+	if err != nil {
+		return zero, err
 	}
-
-	// Otherwise, it's a singleton instance
-	result, ok := service.(T)
 	if !ok {
 		return zero, ErrInvalidServiceType
 	}
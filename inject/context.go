@@ -0,0 +1,32 @@
+package inject
+
+import (
+	"context"
+	"reflect"
+)
+
+// ResolveCtx resolves T like Resolve, passing ctx to context factories so
+// request-scoped services can honor deadlines and read request metadata such
+// as trace IDs or the tenant. Context factories are registered like any other:
+//
+//	inject.Register[*Session](c, func(ctx context.Context, c *inject.Container) (*Session, error) {
+//		return OpenSession(ctx, inject.Get[*DB](c), TenantFrom(ctx))
+//	})
+//
+// Get and Resolve call context factories with context.Background(). ResolveCtx
+// returns the context's error without resolving when ctx is already done.
+func ResolveCtx[T any](ctx context.Context, c *Container) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	requestedType := reflect.TypeOf((*T)(nil)).Elem()
+	if reg, ok := c.services[requestedType]; ok && reg.kind == serviceContextFactory {
+		c.record(requestedType)
+		result, _, err := resolveRegistration[T](ctx, c, reg)
+		return result, err
+	}
+
+	return Resolve[T](c)
+}
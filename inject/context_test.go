@@ -0,0 +1,77 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type tenantKey struct{}
+
+type Tenant struct {
+	Name string
+}
+
+func NewTenant(ctx context.Context, c *Container) (*Tenant, error) {
+	name, ok := ctx.Value(tenantKey{}).(string)
+	if !ok {
+		return nil, errors.New("no tenant")
+	}
+	return &Tenant{Name: name}, nil
+}
+
+func TestResolveCtx(t *testing.T) {
+	t.Run("should pass the context to context factories", func(t *testing.T) {
+		container := NewContainer()
+		Register[*Tenant](container, NewTenant)
+
+		ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+		tenant, err := ResolveCtx[*Tenant](ctx, container)
+		if err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if tenant.Name != "acme" {
+			t.Errorf("tenant should be acme, got %s", tenant.Name)
+		}
+	})
+
+	t.Run("should return the factory error", func(t *testing.T) {
+		container := NewContainer()
+		Register[*Tenant](container, NewTenant)
+
+		_, err := ResolveCtx[*Tenant](context.Background(), container)
+		if err == nil || err.Error() != "no tenant" {
+			t.Errorf("error should be no tenant, got %v", err)
+		}
+		if _, err := Resolve[*Tenant](container); err == nil {
+			t.Errorf("Resolve should return the factory error")
+		}
+		if Get[*Tenant](container) != nil {
+			t.Errorf("Get should return nil when the factory fails")
+		}
+	})
+
+	t.Run("should return the context error when the context is done", func(t *testing.T) {
+		container := NewContainer()
+		Register[*Tenant](container, NewTenant)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := ResolveCtx[*Tenant](ctx, container); !errors.Is(err, context.Canceled) {
+			t.Errorf("error should be context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("should resolve plain factories and values", func(t *testing.T) {
+		container := NewContainer()
+		Register[IService](container, NewTestService)
+		Register[int](container, 1)
+
+		if service, err := ResolveCtx[IService](context.Background(), container); err != nil || service == nil {
+			t.Errorf("service should resolve, got %v, %v", service, err)
+		}
+		if value, err := ResolveCtx[int](context.Background(), container); err != nil || value != 1 {
+			t.Errorf("value should be 1, got %v, %v", value, err)
+		}
+	})
+}
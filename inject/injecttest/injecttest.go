@@ -0,0 +1,83 @@
+// Package injecttest provides a recording container and assertions for
+// testing service wiring without inspecting the container's internals.
+//
+//	func TestWiring(t *testing.T) {
+//		c := injecttest.NewContainer()
+//		RegisterServices(c)
+//
+//		inject.Get[*UserHandler](c)
+//
+//		injecttest.AssertResolved[*UserRepository](t, c)
+//		injecttest.AssertNotResolved[*AdminRepository](t, c)
+//	}
+package injecttest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fxfn/x/inject"
+)
+
+// NewContainer returns a new container that records its resolutions
+func NewContainer() *inject.Container {
+	c := inject.NewContainer()
+	c.Record()
+	return c
+}
+
+// AssertResolved fails the test unless T was resolved at least once
+func AssertResolved[T any](t testing.TB, c *inject.Container) {
+	t.Helper()
+	key := typeOf[T]()
+	if count(t, c, key) == 0 {
+		t.Errorf("expected %s to be resolved, resolved: %v", key, c.Recording().Resolutions())
+	}
+}
+
+// AssertNotResolved fails the test if T was resolved
+func AssertNotResolved[T any](t testing.TB, c *inject.Container) {
+	t.Helper()
+	key := typeOf[T]()
+	if n := count(t, c, key); n != 0 {
+		t.Errorf("expected %s not to be resolved, resolved %d times", key, n)
+	}
+}
+
+// AssertResolvedTimes fails the test unless T was resolved exactly n times
+func AssertResolvedTimes[T any](t testing.TB, c *inject.Container, n int) {
+	t.Helper()
+	key := typeOf[T]()
+	if got := count(t, c, key); got != n {
+		t.Errorf("expected %s to be resolved %d times, resolved %d times", key, n, got)
+	}
+}
+
+// AssertResolvedNamed fails the test unless the named service was resolved at least once
+func AssertResolvedNamed(t testing.TB, c *inject.Container, name any) {
+	t.Helper()
+	if count(t, c, name) == 0 {
+		t.Errorf("expected %v to be resolved, resolved: %v", name, c.Recording().Resolutions())
+	}
+}
+
+// AssertNotResolvedNamed fails the test if the named service was resolved
+func AssertNotResolvedNamed(t testing.TB, c *inject.Container, name any) {
+	t.Helper()
+	if n := count(t, c, name); n != 0 {
+		t.Errorf("expected %v not to be resolved, resolved %d times", name, n)
+	}
+}
+
+func count(t testing.TB, c *inject.Container, key any) int {
+	t.Helper()
+	recording := c.Recording()
+	if recording == nil {
+		t.Fatalf("container is not recording, create it with injecttest.NewContainer or call Record")
+	}
+	return recording.Count(key)
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
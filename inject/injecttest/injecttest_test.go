@@ -0,0 +1,63 @@
+package injecttest
+
+import (
+	"testing"
+
+	"github.com/fxfn/x/inject"
+)
+
+type Logger interface {
+	Info(message string)
+}
+
+type ConsoleLogger struct{}
+
+func (l *ConsoleLogger) Info(message string) {}
+
+type Repository interface{}
+
+type UserRepository struct {
+	Logger Logger
+}
+
+func TestAssertions(t *testing.T) {
+	c := NewContainer()
+	inject.Register[Logger](c, func(c *inject.Container) Logger { return &ConsoleLogger{} })
+	inject.Bind[Repository, *UserRepository](c)
+	inject.RegisterNamed[Logger](c, "audit", &ConsoleLogger{})
+
+	inject.Get[Repository](c)
+	inject.Get[Repository](c)
+
+	AssertResolved[Repository](t, c)
+	AssertResolvedTimes[Repository](t, c, 2)
+	AssertResolvedTimes[Logger](t, c, 2)
+	AssertNotResolved[int](t, c)
+	AssertNotResolvedNamed(t, c, "audit")
+
+	inject.GetNamed[Logger](c, "audit")
+	AssertResolvedNamed(t, c, "audit")
+
+	resolutions := c.Recording().Resolutions()
+	if len(resolutions) != 3 || resolutions[0].Key != typeOf[Repository]() {
+		t.Errorf("resolutions should start with Repository, got %v", resolutions)
+	}
+}
+
+func TestAssertionsFail(t *testing.T) {
+	c := NewContainer()
+	inject.Register[int](c, 1)
+	inject.Get[int](c)
+
+	mock := &testing.T{}
+	AssertNotResolved[int](mock, c)
+	if !mock.Failed() {
+		t.Errorf("AssertNotResolved should fail for a resolved service")
+	}
+
+	mock = &testing.T{}
+	AssertResolved[string](mock, c)
+	if !mock.Failed() {
+		t.Errorf("AssertResolved should fail for an unresolved service")
+	}
+}
@@ -0,0 +1,102 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ResolveNamed resolves the first service registered under name like
+// GetNamed, with the errors of Resolve: ErrServiceNotFound when nothing is
+// registered under name, ErrInvalidServiceType when the service isn't a T.
+// Services registered as nil resolve to the zero value.
+func ResolveNamed[T any](c *Container, name interface{}) (T, error) {
+	var zero T
+	c.record(name)
+	services, ok := c.services[name].service.([]namedService)
+	if !ok || len(services) == 0 {
+		return zero, ErrServiceNotFound
+	}
+
+	result, ok := resolveNamed[T](c, services[0])
+	if !ok {
+		return zero, ErrInvalidServiceType
+	}
+	return result, nil
+}
+
+// ResolveAllNamed resolves every service registered under name, in order of
+// registration. It returns ErrServiceNotFound when nothing is registered
+// under name and ErrInvalidServiceType when any of the services isn't a T,
+// rather than skipping it like GetAllNamed.
+func ResolveAllNamed[T any](c *Container, name interface{}) ([]T, error) {
+	c.record(name)
+	services, ok := c.services[name].service.([]namedService)
+	if !ok {
+		return []T{}, ErrServiceNotFound
+	}
+
+	result := make([]T, 0, len(services))
+	for _, service := range services {
+		resolved, ok := resolveNamed[T](c, service)
+		if !ok {
+			return []T{}, ErrInvalidServiceType
+		}
+		result = append(result, resolved)
+	}
+	return result, nil
+}
+
+// A Name names the service of a Named parameter
+type Name interface {
+	Name() string
+}
+
+// Named is a constructor parameter set from the first service registered
+// under the name of N, like GetNamed, to depend on one of several instances
+// of a type without calling GetNamed in a factory:
+//
+//	type Primary struct{}
+//
+//	func (Primary) Name() string { return "primary" }
+//
+//	func NewUserRepository(logger Logger, db inject.Named[*sql.DB, Primary]) *SqlUserRepository {
+//		return &SqlUserRepository{Logger: logger, DB: db.Value}
+//	}
+//
+//	inject.RegisterNamed[*sql.DB](c, "primary", primaryDB)
+//	inject.RegisterConstructor[UserRepository](c, NewUserRepository)
+type Named[T any, N Name] struct {
+	Value T
+}
+
+// namedParameter is implemented by every Named, for RegisterConstructor to
+// find the service of a parameter with reflection
+type namedParameter interface {
+	service() (name string, t reflect.Type)
+}
+
+func (Named[T, N]) service() (string, reflect.Type) {
+	var name N
+	return name.Name(), reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// resolveNamedType is the reflection counterpart of ResolveNamed, used to
+// wire fields tagged with a name and Named parameters. It returns
+// ErrServiceNotFound when nothing of type t is registered under name.
+// Services registered as nil resolve to the zero value of t.
+func (c *Container) resolveNamedType(name string, t reflect.Type) (reflect.Value, error) {
+	c.record(name)
+	services, ok := c.services[name].service.([]namedService)
+	if !ok || len(services) == 0 {
+		return reflect.Value{}, fmt.Errorf("%w: %q", ErrServiceNotFound, name)
+	}
+
+	service := services[0].resolve(c)
+	if service == nil {
+		return reflect.Zero(t), nil
+	}
+	if !reflect.TypeOf(service).AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("%w: %q is a %T, not a %s", ErrServiceNotFound, name, service, t)
+	}
+	return reflect.ValueOf(service), nil
+}
@@ -0,0 +1,77 @@
+package inject
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveNamed(t *testing.T) {
+	t.Run("should resolve the first service", func(t *testing.T) {
+		container := NewContainer()
+		RegisterNamed[int](container, "port", 8080)
+		RegisterNamed[int](container, "port", 8081)
+
+		port, err := ResolveNamed[int](container, "port")
+		if err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if port != 8080 {
+			t.Errorf("port should be 8080, got %d", port)
+		}
+	})
+
+	t.Run("should return ErrServiceNotFound for missing names", func(t *testing.T) {
+		container := NewContainer()
+
+		if _, err := ResolveNamed[int](container, "port"); !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should be ErrServiceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("should return ErrInvalidServiceType for services of another type", func(t *testing.T) {
+		container := NewContainer()
+		RegisterNamed[int](container, "port", "8080")
+
+		if _, err := ResolveNamed[int](container, "port"); !errors.Is(err, ErrInvalidServiceType) {
+			t.Errorf("error should be ErrInvalidServiceType, got %v", err)
+		}
+	})
+}
+
+func TestResolveAllNamed(t *testing.T) {
+	t.Run("should resolve every service", func(t *testing.T) {
+		container := NewContainer()
+		RegisterNamed[IService](container, "handlers", NewTestService)
+		RegisterNamed[IService](container, "handlers", IService(&Service{}))
+
+		services, err := ResolveAllNamed[IService](container, "handlers")
+		if err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if len(services) != 2 {
+			t.Errorf("there should be 2 services, got %d", len(services))
+		}
+	})
+
+	t.Run("should return ErrServiceNotFound for missing names", func(t *testing.T) {
+		container := NewContainer()
+
+		if _, err := ResolveAllNamed[IService](container, "handlers"); !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should be ErrServiceNotFound, got %v", err)
+		}
+	})
+
+	t.Run("should return ErrInvalidServiceType when any service has another type", func(t *testing.T) {
+		container := NewContainer()
+		RegisterNamed[int](container, "ports", 8080)
+		RegisterNamed[int](container, "ports", "8081")
+
+		services, err := ResolveAllNamed[int](container, "ports")
+		if !errors.Is(err, ErrInvalidServiceType) {
+			t.Errorf("error should be ErrInvalidServiceType, got %v", err)
+		}
+		if len(services) != 0 {
+			t.Errorf("services should be empty, got %v", services)
+		}
+	})
+}
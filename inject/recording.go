@@ -0,0 +1,82 @@
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Resolution is a service requested from a recording container
+type Resolution struct {
+	Key   any // The reflect.Type of the service, or the name of a named service
+	Count int
+}
+
+func (r Resolution) String() string {
+	if t, ok := r.Key.(reflect.Type); ok {
+		return fmt.Sprintf("%s (%d)", t, r.Count)
+	}
+	return fmt.Sprintf("%q (%d)", fmt.Sprint(r.Key), r.Count)
+}
+
+// Recording counts the resolutions of a container, see Container.Record
+type Recording struct {
+	mu     sync.Mutex
+	counts map[any]int
+	order  []any
+}
+
+// Record starts recording every resolution made through the container, by
+// Get, Resolve, ResolveCtx, the named getters and auto-wired fields. It is
+// meant for tests, see the injecttest package for assertions.
+func (c *Container) Record() *Recording {
+	if c.recording == nil {
+		c.recording = &Recording{counts: make(map[any]int)}
+	}
+	return c.recording
+}
+
+// Recording returns the recording started with Record, or nil
+func (c *Container) Recording() *Recording {
+	return c.recording
+}
+
+func (c *Container) record(key any) {
+	if c.recording == nil {
+		return
+	}
+
+	c.recording.mu.Lock()
+	defer c.recording.mu.Unlock()
+	if _, exists := c.recording.counts[key]; !exists {
+		c.recording.order = append(c.recording.order, key)
+	}
+	c.recording.counts[key]++
+}
+
+// Count returns how often key, a reflect.Type or a service name, was resolved
+func (r *Recording) Count(key any) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[key]
+}
+
+// Resolutions returns the resolved services in the order of their first resolution
+func (r *Recording) Resolutions() []Resolution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	resolutions := make([]Resolution, len(r.order))
+	for i, key := range r.order {
+		resolutions[i] = Resolution{Key: key, Count: r.counts[key]}
+	}
+	return resolutions
+}
+
+// Reset forgets the recorded resolutions
+func (r *Recording) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts = make(map[any]int)
+	r.order = nil
+}
@@ -0,0 +1,120 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// ErrNotRefreshable is returned by Refresh for services that were registered
+// as a value rather than built by a factory
+var ErrNotRefreshable = errors.New("service is not refreshable")
+
+// Disposable is implemented by services that release resources, such as
+// connections, when a refreshed instance replaces them
+type Disposable interface {
+	Dispose() error
+}
+
+// refreshedSingleton is the lazily rebuilt instance of a refreshed singleton
+type refreshedSingleton struct {
+	mu       sync.Mutex
+	instance any
+	built    bool
+}
+
+func (s *refreshedSingleton) get(c *Container, factory RegistrationValue, t reflect.Type) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.built {
+		return s.instance
+	}
+
+	value, _, err := c.callService(context.Background(), factory, t)
+	if err != nil || !value.IsValid() {
+		return nil
+	}
+	s.instance, s.built = value.Interface(), true
+	return s.instance
+}
+
+func (s *refreshedSingleton) current() any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.instance
+}
+
+func (c *Container) rememberSingleton(t reflect.Type, factory RegistrationValue) {
+	if c.singletons == nil {
+		c.singletons = make(map[reflect.Type]RegistrationValue)
+	}
+	c.singletons[t] = factory
+}
+
+func (c *Container) forgetSingleton(t reflect.Type) {
+	delete(c.singletons, t)
+	delete(c.refreshed, t)
+}
+
+// isFactory reports whether service is a factory or a context factory
+func isFactory(service any) bool {
+	serviceType := reflect.TypeOf(service)
+	if serviceType == nil || serviceType.Kind() != reflect.Func {
+		return false
+	}
+	containerType := reflect.TypeOf((*Container)(nil))
+	contextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	return serviceType.NumIn() == 1 && serviceType.In(0) == containerType ||
+		serviceType.NumIn() == 2 && serviceType.In(0) == contextType && serviceType.In(1) == containerType
+}
+
+// Refresh discards the cached instance of a singleton T, built by
+// RegisterSingleton with a factory or by Warmup, so its factory builds a new
+// one on the next resolution. The old instance is disposed when it
+// implements Disposable and its error returned. This supports reloading
+// configuration and rotating credentials of long-lived services:
+//
+//	inject.RegisterSingleton[*Client](c, NewClient)
+//
+//	// after the credentials changed
+//	err := inject.Refresh[*Client](c)
+//
+// Refresh does nothing for transient services, which are built on every
+// resolution, and returns ErrNotRefreshable for values.
+func Refresh[T any](c *Container) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	reg, registered := c.services[t]
+	old := reg.service
+	if !registered {
+		return ErrServiceNotFound
+	}
+
+	factory, ok := c.singletons[t]
+	if !ok {
+		if isFactory(old) {
+			return nil
+		}
+		return ErrNotRefreshable
+	}
+
+	if previous, ok := c.refreshed[t]; ok {
+		old = previous.current()
+	}
+
+	next := &refreshedSingleton{}
+	if c.refreshed == nil {
+		c.refreshed = make(map[reflect.Type]*refreshedSingleton)
+	}
+	c.refreshed[t] = next
+	setService[T](c, t, func(c *Container) T {
+		instance, _ := next.get(c, factory, t).(T)
+		return instance
+	})
+
+	if disposable, ok := old.(Disposable); ok {
+		return disposable.Dispose()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package inject
+
+import (
+	"errors"
+	"testing"
+)
+
+type Client struct {
+	Generation int
+	disposed   bool
+}
+
+func (c *Client) Dispose() error {
+	c.disposed = true
+	return nil
+}
+
+func TestRefresh(t *testing.T) {
+	t.Run("should rebuild the singleton on the next resolution and dispose the old one", func(t *testing.T) {
+		generation := 0
+		container := NewContainer()
+		RegisterSingleton[*Client](container, func(c *Container) *Client {
+			generation++
+			return &Client{Generation: generation}
+		})
+
+		first := Get[*Client](container)
+		if err := Refresh[*Client](container); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if !first.disposed {
+			t.Errorf("old instance should be disposed")
+		}
+		if generation != 1 {
+			t.Errorf("new instance should be built lazily")
+		}
+
+		second := Get[*Client](container)
+		if second.Generation != 2 || Get[*Client](container) != second {
+			t.Errorf("new instance should be built once, got generation %d", second.Generation)
+		}
+
+		if err := Refresh[*Client](container); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if !second.disposed {
+			t.Errorf("refreshed instance should be disposed on the next refresh")
+		}
+		if Get[*Client](container).Generation != 3 {
+			t.Errorf("third instance should be built")
+		}
+	})
+
+	t.Run("should refresh services built by warmup", func(t *testing.T) {
+		generation := 0
+		container := NewContainer()
+		Register[*Client](container, func(c *Container) *Client {
+			generation++
+			return &Client{Generation: generation}
+		})
+		Eager[*Client](container)
+		if _, err := container.Warmup(t.Context()); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+
+		if err := Refresh[*Client](container); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if Get[*Client](container).Generation != 2 {
+			t.Errorf("new instance should be built")
+		}
+	})
+
+	t.Run("should report values and missing services", func(t *testing.T) {
+		container := NewContainer()
+		RegisterSingleton[*Client](container, &Client{})
+		Register[IService](container, NewTestService)
+
+		if err := Refresh[*Client](container); !errors.Is(err, ErrNotRefreshable) {
+			t.Errorf("error should be ErrNotRefreshable, got %v", err)
+		}
+		if err := Refresh[IService](container); err != nil {
+			t.Errorf("transient services should refresh without error, got %v", err)
+		}
+		if err := Refresh[int](container); !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should be ErrServiceNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package inject
+
+import (
+	"context"
+	"reflect"
+)
+
+// serviceKind is what a registered service is, worked out once when it is
+// registered rather than on every resolution
+type serviceKind int
+
+const (
+	serviceUnclassified   serviceKind = iota // Inspected with reflection when resolved
+	serviceValue                             // A value of the registered type
+	serviceFactory                           // func(*Container) T
+	serviceContextFactory                    // func(context.Context, *Container) (T, error)
+)
+
+// registration is a registered service with its classification. Services
+// registered under a name are a []namedService.
+type registration struct {
+	service any
+	kind    serviceKind
+
+	// build calls a factory or context factory, nil for values
+	build func(ctx context.Context, c *Container) (any, error)
+}
+
+// classify works out the kind of a service registered for T. Factories
+// returning another type than T, such as an implementation of interface T,
+// stay unclassified so resolving them checks their result type.
+func classify[T any](service any) registration {
+	switch factory := service.(type) {
+	case func(c *Container) T:
+		return registration{kind: serviceFactory, build: func(ctx context.Context, c *Container) (any, error) {
+			return factory(c), nil
+		}}
+	case func(ctx context.Context, c *Container) (T, error):
+		return registration{kind: serviceContextFactory, build: func(ctx context.Context, c *Container) (any, error) {
+			return factory(ctx, c)
+		}}
+	}
+
+	if _, ok := service.(T); ok && !isFactory(service) {
+		return registration{kind: serviceValue}
+	}
+	return registration{kind: serviceUnclassified}
+}
+
+// setService registers service for t, classified for T
+func setService[T any](c *Container, t reflect.Type, service any) {
+	reg := classify[T](service)
+	reg.service = service
+	c.services[t] = reg
+}
+
+// resolveRegistration resolves a service registered for T with ctx through
+// its classification, calling factories with reg.build. ok is false when the
+// service or the factory's result is not a T.
+func resolveRegistration[T any](ctx context.Context, c *Container, reg registration) (result T, ok bool, err error) {
+	switch reg.kind {
+	case serviceFactory, serviceContextFactory:
+		built, err := reg.build(ctx, c)
+		if err != nil || built == nil {
+			return result, true, err
+		}
+		result, ok = built.(T)
+		return result, ok, nil
+	}
+
+	result, ok = reg.service.(T)
+	return result, ok, nil
+}
+
+// namedService is one of the services registered under a name
+type namedService struct {
+	value RegistrationValue
+	build func(c *Container) any // Calls value when it is a factory, nil otherwise
+}
+
+// newNamedService classifies a service registered under a name for T.
+// Factories returning another type than T are called with reflection, their
+// result is checked against T when resolved.
+func newNamedService[T any](service RegistrationValue) namedService {
+	if factory, ok := service.(func(c *Container) T); ok {
+		return namedService{value: service, build: func(c *Container) any {
+			return factory(c)
+		}}
+	}
+
+	serviceType := reflect.TypeOf(service)
+	if serviceType != nil &&
+		serviceType.Kind() == reflect.Func &&
+		serviceType.NumIn() == 1 &&
+		serviceType.In(0) == reflect.TypeOf((*Container)(nil)) {
+		factory := reflect.ValueOf(service)
+		return namedService{value: service, build: func(c *Container) any {
+			results := factory.Call([]reflect.Value{reflect.ValueOf(c)})
+			if len(results) == 0 {
+				return nil
+			}
+			return results[0].Interface()
+		}}
+	}
+
+	return namedService{value: service}
+}
+
+// resolve returns the service, built when it is a factory
+func (n namedService) resolve(c *Container) any {
+	if n.build != nil {
+		return n.build(c)
+	}
+	return n.value
+}
@@ -0,0 +1,105 @@
+package inject
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Snapshot is an immutable copy of the registrations of a container, taken
+// with Container.Snapshot. Registering services afterwards changes neither
+// the snapshot nor containers it is restored into.
+type Snapshot struct {
+	services   map[any]registration
+	singletons map[reflect.Type]RegistrationValue
+	refreshed  map[reflect.Type]*refreshedSingleton
+	eager      []reflect.Type
+}
+
+// Snapshot copies the registrations of the container, including the
+// singletons it built so far
+func (c *Container) Snapshot() *Snapshot {
+	return &Snapshot{
+		services:   cloneServices(c.services),
+		singletons: maps.Clone(c.singletons),
+		refreshed:  maps.Clone(c.refreshed),
+		eager:      slices.Clone(c.eager),
+	}
+}
+
+// Restore replaces the registrations of the container with a snapshot, then
+// disposes the singletons the container built that are not part of it.
+// Together with Snapshot this supports hot reloading configuration: a new
+// generation of services is built and validated in its own container and
+// swapped in only once it is healthy.
+//
+//	next := inject.NewContainer()
+//	configure(next, newConfig)
+//	if _, err := next.Warmup(ctx); err != nil {
+//		return err // keep serving with the current generation
+//	}
+//	err := c.Restore(next.Snapshot())
+//
+// Restoring a snapshot taken earlier from the container itself rolls back
+// registrations made since. Restore returns the errors of disposing, joined.
+// Like Refresh, it must not run concurrently with resolutions.
+func (c *Container) Restore(snapshot *Snapshot) error {
+	old := c.builtSingletons()
+
+	c.services = cloneServices(snapshot.services)
+	c.singletons = maps.Clone(snapshot.singletons)
+	c.refreshed = maps.Clone(snapshot.refreshed)
+	c.eager = slices.Clone(snapshot.eager)
+
+	kept := c.builtSingletons()
+	var errs []error
+	for _, instance := range old {
+		if slices.ContainsFunc(kept, func(k any) bool { return sameInstance(k, instance) }) {
+			continue
+		}
+		if disposable, ok := instance.(Disposable); ok {
+			errs = append(errs, disposable.Dispose())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// builtSingletons returns the instances of the singletons the container
+// built, by RegisterSingleton with a factory, Refresh or Warmup
+func (c *Container) builtSingletons() []any {
+	var instances []any
+	for t := range c.singletons {
+		if refreshed, ok := c.refreshed[t]; ok {
+			if instance := refreshed.current(); instance != nil {
+				instances = append(instances, instance)
+			}
+			continue
+		}
+		if instance := c.services[t].service; instance != nil && !isFactory(instance) {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
+
+// cloneServices copies services, including the slices of named services, so
+// registering under a name doesn't append to a shared slice
+func cloneServices(services map[any]registration) map[any]registration {
+	clone := make(map[any]registration, len(services))
+	for key, reg := range services {
+		if named, ok := reg.service.([]namedService); ok {
+			reg.service = slices.Clone(named)
+		}
+		clone[key] = reg
+	}
+	return clone
+}
+
+// sameInstance compares instances without panicking on uncomparable types
+func sameInstance(a, b any) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
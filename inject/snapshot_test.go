@@ -0,0 +1,104 @@
+package inject
+
+import (
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run("should not change when registering afterwards", func(t *testing.T) {
+		container := NewContainer()
+		Register[int](container, 1)
+		RegisterNamed[string](container, "names", "first")
+		snapshot := container.Snapshot()
+
+		Register[int](container, 2)
+		RegisterNamed[string](container, "names", "second")
+
+		restored := NewContainer()
+		if err := restored.Restore(snapshot); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if Get[int](restored) != 1 {
+			t.Errorf("int should be 1, got %d", Get[int](restored))
+		}
+		if names := GetAllNamed[string](restored, "names"); len(names) != 1 {
+			t.Errorf("there should be 1 name, got %v", names)
+		}
+	})
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("should swap in a new generation and dispose the old singletons", func(t *testing.T) {
+		container := NewContainer()
+		RegisterSingleton[*Client](container, func(c *Container) *Client {
+			return &Client{Generation: 1}
+		})
+		old := Get[*Client](container)
+
+		next := NewContainer()
+		RegisterSingleton[*Client](next, func(c *Container) *Client {
+			return &Client{Generation: 2}
+		})
+		if err := container.Restore(next.Snapshot()); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+
+		if Get[*Client](container).Generation != 2 {
+			t.Errorf("new generation should be resolved")
+		}
+		if !old.disposed {
+			t.Errorf("old singleton should be disposed")
+		}
+		if Get[*Client](container).disposed {
+			t.Errorf("new singleton should not be disposed")
+		}
+	})
+
+	t.Run("should roll back registrations made after the snapshot", func(t *testing.T) {
+		container := NewContainer()
+		RegisterSingleton[*Client](container, func(c *Container) *Client {
+			return &Client{Generation: 1}
+		})
+		kept := Get[*Client](container)
+		snapshot := container.Snapshot()
+
+		RegisterSingleton[*Client](container, func(c *Container) *Client {
+			return &Client{Generation: 2}
+		})
+		discarded := Get[*Client](container)
+		if err := container.Restore(snapshot); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+
+		if Get[*Client](container) != kept {
+			t.Errorf("snapshot singleton should be resolved")
+		}
+		if kept.disposed {
+			t.Errorf("snapshot singleton should not be disposed")
+		}
+		if !discarded.disposed {
+			t.Errorf("discarded singleton should be disposed")
+		}
+	})
+
+	t.Run("should dispose refreshed singletons", func(t *testing.T) {
+		container := NewContainer()
+		RegisterSingleton[*Client](container, func(c *Container) *Client {
+			return &Client{}
+		})
+		if err := Refresh[*Client](container); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		refreshed := Get[*Client](container)
+
+		if err := container.Restore(NewContainer().Snapshot()); err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if !refreshed.disposed {
+			t.Errorf("refreshed singleton should be disposed")
+		}
+		if _, ok := TryGet[*Client](container); ok {
+			t.Errorf("client should no longer be registered")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package inject
+
+import (
+	"context"
+	"reflect"
+)
+
+// TryGet resolves T like Get, also reporting whether it was resolved, so a
+// missing registration can be told apart from one that is nil on purpose:
+//
+//	inject.Register[*Tracer](c, nil) // tracing disabled
+//
+//	tracer, ok := inject.TryGet[*Tracer](c)
+//	if !ok {
+//		return errors.New("tracer is not configured")
+//	}
+//
+// It returns true with the zero value for services registered as nil and
+// factories returning nil. It returns false when T isn't registered, the
+// registered service isn't a T, or its context factory fails.
+func TryGet[T any](c *Container) (T, bool) {
+	var zero T
+	requestedType := reflect.TypeOf((*T)(nil)).Elem()
+	c.record(requestedType)
+	reg, ok := c.services[requestedType]
+	if !ok {
+		return zero, false
+	}
+	if reg.service == nil {
+		return zero, true
+	}
+
+	// Factories are called, context factories without a request context
+	result, ok, err := resolveRegistration[T](context.Background(), c, reg)
+	if err != nil {
+		return zero, false
+	}
+	return result, ok
+}
+
+// TryGetNamed resolves the first service registered under name like
+// GetNamed, reporting whether it was resolved. Services registered as nil and
+// factories returning nil resolve to the zero value.
+func TryGetNamed[T any](c *Container, name interface{}) (T, bool) {
+	c.record(name)
+	services, ok := c.services[name].service.([]namedService)
+	if !ok || len(services) == 0 {
+		var zero T
+		return zero, false
+	}
+	return resolveNamed[T](c, services[0])
+}
+
+// TryGetAllNamed resolves every service registered under name, reporting
+// whether the name is registered. Unlike GetAllNamed it keeps services
+// registered as nil, as zero values, and skips only those that aren't a T.
+func TryGetAllNamed[T any](c *Container, name interface{}) ([]T, bool) {
+	c.record(name)
+	services, ok := c.services[name].service.([]namedService)
+	if !ok {
+		return []T{}, false
+	}
+
+	result := make([]T, 0, len(services))
+	for _, service := range services {
+		if service, ok := resolveNamed[T](c, service); ok {
+			result = append(result, service)
+		}
+	}
+	return result, true
+}
+
+// resolveNamed resolves a named service, accepting nil as the zero value of T
+func resolveNamed[T any](c *Container, service namedService) (T, bool) {
+	resolved := service.resolve(c)
+	if resolved == nil {
+		var zero T
+		return zero, true
+	}
+	result, ok := resolved.(T)
+	return result, ok
+}
@@ -0,0 +1,102 @@
+package inject
+
+import (
+	"testing"
+)
+
+func TestTryGet(t *testing.T) {
+	t.Run("should report missing services", func(t *testing.T) {
+		container := NewContainer()
+
+		service, ok := TryGet[IService](container)
+		if ok {
+			t.Errorf("ok should be false")
+		}
+		if service != nil {
+			t.Errorf("service should be nil, got %v", service)
+		}
+	})
+
+	t.Run("should report services registered as nil", func(t *testing.T) {
+		container := NewContainer()
+		Register[IService](container, nil)
+
+		service, ok := TryGet[IService](container)
+		if !ok {
+			t.Errorf("ok should be true")
+		}
+		if service != nil {
+			t.Errorf("service should be nil, got %v", service)
+		}
+	})
+
+	t.Run("should resolve factories", func(t *testing.T) {
+		container := NewContainer()
+		Register[IService](container, NewTestService)
+
+		service, ok := TryGet[IService](container)
+		if !ok || service == nil {
+			t.Errorf("service should be resolved, got %v, %v", service, ok)
+		}
+	})
+
+	t.Run("should report services of another type", func(t *testing.T) {
+		container := NewContainer()
+		Register[int](container, "one")
+
+		if _, ok := TryGet[int](container); ok {
+			t.Errorf("ok should be false")
+		}
+	})
+
+	t.Run("should report failing context factories", func(t *testing.T) {
+		container := NewContainer()
+		Register[*Tenant](container, NewTenant)
+
+		if _, ok := TryGet[*Tenant](container); ok {
+			t.Errorf("ok should be false")
+		}
+	})
+}
+
+func TestTryGetNamed(t *testing.T) {
+	t.Run("should report missing names", func(t *testing.T) {
+		container := NewContainer()
+
+		if _, ok := TryGetNamed[IService](container, "primary"); ok {
+			t.Errorf("ok should be false")
+		}
+		services, ok := TryGetAllNamed[IService](container, "primary")
+		if ok || len(services) != 0 {
+			t.Errorf("services should be empty and not ok, got %v, %v", services, ok)
+		}
+	})
+
+	t.Run("should report services registered as nil", func(t *testing.T) {
+		container := NewContainer()
+		RegisterNamed[IService](container, "primary", nil)
+		RegisterNamed[IService](container, "primary", NewTestService)
+
+		service, ok := TryGetNamed[IService](container, "primary")
+		if !ok || service != nil {
+			t.Errorf("service should be nil and ok, got %v, %v", service, ok)
+		}
+
+		services, ok := TryGetAllNamed[IService](container, "primary")
+		if !ok || len(services) != 2 {
+			t.Fatalf("both services should be resolved, got %v, %v", services, ok)
+		}
+		if services[0] != nil || services[1] == nil {
+			t.Errorf("services should be nil and the factory's, got %v", services)
+		}
+	})
+
+	t.Run("should report services of another type", func(t *testing.T) {
+		container := NewContainer()
+		RegisterNamed[int](container, "count", "one")
+
+		if _, ok := TryGetNamed[int](container, "count"); ok {
+			t.Errorf("ok should be false")
+		}
+	})
+}
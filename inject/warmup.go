@@ -0,0 +1,86 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Eager marks T to be built by Warmup. T is registered as usual, with a factory
+// or a context factory.
+func Eager[T any](c *Container) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for _, existing := range c.eager {
+		if existing == t {
+			return
+		}
+	}
+	c.eager = append(c.eager, t)
+}
+
+// WarmupResult is the outcome of building one eager service
+type WarmupResult struct {
+	Type     reflect.Type
+	Duration time.Duration
+	Err      error
+}
+
+// Warmup builds every service marked with Eager concurrently, passing ctx to
+// context factories, and keeps each built instance as a singleton, so expensive
+// services such as database pools and caches exist before traffic arrives:
+//
+//	inject.Register[*sql.DB](c, OpenDatabase)
+//	inject.Eager[*sql.DB](c)
+//
+//	results, err := c.Warmup(ctx)
+//
+// It returns one result per eager service, in the order they were marked, and
+// the errors of all failed services joined. Failed services keep their factory.
+// Call Warmup before the container is used concurrently.
+func (c *Container) Warmup(ctx context.Context) ([]WarmupResult, error) {
+	results := make([]WarmupResult, len(c.eager))
+	built := make([]reflect.Value, len(c.eager))
+
+	var wg sync.WaitGroup
+	for i, t := range c.eager {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			results[i].Type = t
+			if err := ctx.Err(); err != nil {
+				results[i].Err = err
+				return
+			}
+
+			value, isFactory, err := c.buildType(ctx, t)
+			results[i].Duration = time.Since(start)
+			results[i].Err = err
+			if err == nil && isFactory {
+				built[i] = value
+			}
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("inject: warmup %s: %w", result.Type, result.Err))
+			continue
+		}
+		if built[i].IsValid() {
+			if _, exists := c.singletons[result.Type]; !exists {
+				c.rememberSingleton(result.Type, c.services[result.Type].service)
+			}
+			delete(c.refreshed, result.Type)
+			c.services[result.Type] = registration{service: built[i].Interface(), kind: serviceValue}
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type Pool struct {
+	ID int64
+}
+
+func TestWarmup(t *testing.T) {
+	t.Run("should build eager services once and keep them", func(t *testing.T) {
+		var built atomic.Int64
+		container := NewContainer()
+		Register[*Pool](container, func(c *Container) *Pool {
+			return &Pool{ID: built.Add(1)}
+		})
+		Eager[*Pool](container)
+		Eager[*Pool](container)
+
+		results, err := container.Warmup(context.Background())
+		if err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("results should have 1 entry, got %d", len(results))
+		}
+
+		first := Get[*Pool](container)
+		second := Get[*Pool](container)
+		if first != second || first.ID != 1 || built.Load() != 1 {
+			t.Errorf("pool should be built once, built %d times", built.Load())
+		}
+	})
+
+	t.Run("should build services concurrently", func(t *testing.T) {
+		container := NewContainer()
+		Register[*Pool](container, func(ctx context.Context, c *Container) (*Pool, error) {
+			time.Sleep(50 * time.Millisecond)
+			return &Pool{}, nil
+		})
+		Register[*Tenant](container, func(ctx context.Context, c *Container) (*Tenant, error) {
+			time.Sleep(50 * time.Millisecond)
+			return &Tenant{}, nil
+		})
+		Eager[*Pool](container)
+		Eager[*Tenant](container)
+
+		start := time.Now()
+		results, err := container.Warmup(context.Background())
+		if err != nil {
+			t.Fatalf("error should be nil, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+			t.Errorf("warmup should run concurrently, took %s", elapsed)
+		}
+		for _, result := range results {
+			if result.Duration < 50*time.Millisecond {
+				t.Errorf("duration of %s should be at least 50ms, got %s", result.Type, result.Duration)
+			}
+		}
+	})
+
+	t.Run("should aggregate errors", func(t *testing.T) {
+		container := NewContainer()
+		Register[*Tenant](container, NewTenant)
+		Eager[*Tenant](container)
+		Eager[*Pool](container)
+
+		_, err := container.Warmup(context.Background())
+		if err == nil {
+			t.Fatalf("error should not be nil")
+		}
+		if !errors.Is(err, ErrServiceNotFound) {
+			t.Errorf("error should contain ErrServiceNotFound, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "no tenant") {
+			t.Errorf("error should contain the tenant factory error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sync"
+
+	"github.com/fxfn/x/crypt"
+	"github.com/gin-gonic/gin"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyInfo describes the owner of an API key. It is stored on the context
+// under "api_key_info" once the key has been validated.
+type APIKeyInfo struct {
+	ID       string
+	Owner    string
+	Scopes   []string
+	Metadata map[string]interface{}
+}
+
+// HasScope reports whether the key was granted scope
+func (i *APIKeyInfo) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyStore looks up API keys by their hash, see HashAPIKey.
+// Keys should never be stored in plain text.
+type APIKeyStore interface {
+	Lookup(ctx context.Context, hashedKey string) (*APIKeyInfo, error)
+}
+
+// HashAPIKey returns the hash APIKeyStore looks keys up by, the one
+// crypt.HashAPIKey computes
+func HashAPIKey(apiKey string) string {
+	return crypt.HashAPIKey(apiKey)
+}
+
+// MemoryAPIKeyStore is an in-memory APIKeyStore
+type MemoryAPIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]APIKeyInfo
+}
+
+// NewMemoryAPIKeyStore creates an empty in-memory key store
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{
+		keys: make(map[string]APIKeyInfo),
+	}
+}
+
+// Add stores the hash of apiKey with its info
+func (s *MemoryAPIKeyStore) Add(apiKey string, info APIKeyInfo) {
+	s.AddHashed(HashAPIKey(apiKey), info)
+}
+
+// AddHashed stores an already hashed key with its info
+func (s *MemoryAPIKeyStore) AddHashed(hashedKey string, info APIKeyInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[hashedKey] = info
+}
+
+// Remove deletes a key by its hash
+func (s *MemoryAPIKeyStore) Remove(hashedKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, hashedKey)
+}
+
+// Lookup compares hashedKey against every stored hash in constant time
+func (s *MemoryAPIKeyStore) Lookup(ctx context.Context, hashedKey string) (*APIKeyInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found *APIKeyInfo
+	for stored, info := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hashedKey)) == 1 {
+			info := info
+			found = &info
+		}
+	}
+
+	if found == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	return found, nil
+}
+
+// GetAPIKeyInfo returns the info of the API key that authenticated the request
+func GetAPIKeyInfo(c *gin.Context) (*APIKeyInfo, bool) {
+	value, exists := c.Get("api_key_info")
+	if !exists {
+		return nil, false
+	}
+	info, ok := value.(*APIKeyInfo)
+	return info, ok
+}
+
+// validateAPIKey checks key against the scheme's store and ValidateKey callback
+func (a *APIKeySecurity) validateAPIKey(c *gin.Context, key string) bool {
+	if a.Store != nil {
+		info, err := a.Store.Lookup(c.Request.Context(), HashAPIKey(key))
+		if err != nil || info == nil {
+			return false
+		}
+		c.Set("api_key_info", info)
+	}
+
+	if a.ValidateKey != nil && !a.ValidateKey(c, key) {
+		return false
+	}
+
+	return true
+}
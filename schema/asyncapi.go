@@ -0,0 +1,233 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventConfig describes a registered event beyond its payload
+type EventConfig struct {
+	Summary     string
+	Description string
+	Receive     bool // The service consumes the event instead of publishing it
+}
+
+// registeredEvent is an event payload type published to or read from a channel
+type registeredEvent struct {
+	name        string
+	payloadType reflect.Type
+	config      EventConfig
+}
+
+var (
+	eventRegistryMu sync.RWMutex
+	eventRegistry   = make(map[string]registeredEvent)
+)
+
+// RegisterEvent records the payload type of the event published on the
+// channel name, e.g. RegisterEvent("user.created", UserCreated{}), so
+// AsyncAPI can document it. Registering a name again replaces it.
+func RegisterEvent(name string, payload interface{}, config ...EventConfig) {
+	event := registeredEvent{name: name, payloadType: reflect.TypeOf(payload)}
+	if len(config) > 0 {
+		event.config = config[0]
+	}
+
+	eventRegistryMu.Lock()
+	defer eventRegistryMu.Unlock()
+	eventRegistry[name] = event
+}
+
+// registeredEvents returns the registered events sorted by name
+func registeredEvents() []registeredEvent {
+	eventRegistryMu.RLock()
+	defer eventRegistryMu.RUnlock()
+
+	events := make([]registeredEvent, 0, len(eventRegistry))
+	for _, event := range eventRegistry {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].name < events[j].name })
+	return events
+}
+
+// AsyncAPIOpts configures the document built by AsyncAPI
+type AsyncAPIOpts struct {
+	Title       string
+	Description string
+	Version     string
+	Servers     map[string]AsyncAPIServer // Brokers by name
+	OutputFile  string                    // Path to write the document to, JSON when it contains "json"
+}
+
+// AsyncAPI 3.0 document structures
+type AsyncAPISpec struct {
+	AsyncAPI   string                       `json:"asyncapi" yaml:"asyncapi"`
+	Info       Info                         `json:"info" yaml:"info"`
+	Servers    map[string]AsyncAPIServer    `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Channels   map[string]AsyncAPIChannel   `json:"channels" yaml:"channels"`
+	Operations map[string]AsyncAPIOperation `json:"operations" yaml:"operations"`
+	Components AsyncAPIComponents           `json:"components" yaml:"components"`
+}
+
+type AsyncAPIServer struct {
+	Host        string `json:"host" yaml:"host"`
+	Protocol    string `json:"protocol" yaml:"protocol"` // e.g. "kafka", "amqp", "nats"
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type AsyncAPIChannel struct {
+	Address  string                 `json:"address" yaml:"address"`
+	Messages map[string]AsyncAPIRef `json:"messages" yaml:"messages"`
+}
+
+type AsyncAPIOperation struct {
+	Action   string        `json:"action" yaml:"action"` // "send" or "receive"
+	Channel  AsyncAPIRef   `json:"channel" yaml:"channel"`
+	Summary  string        `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Messages []AsyncAPIRef `json:"messages" yaml:"messages"`
+}
+
+type AsyncAPIMessage struct {
+	Name        string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Summary     string      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	ContentType string      `json:"contentType" yaml:"contentType"`
+	Payload     *JSONSchema `json:"payload,omitempty" yaml:"payload,omitempty"`
+}
+
+type AsyncAPIComponents struct {
+	Messages map[string]AsyncAPIMessage `json:"messages" yaml:"messages"`
+	Schemas  map[string]*JSONSchema     `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+type AsyncAPIRef struct {
+	Ref string `json:"$ref" yaml:"$ref"`
+}
+
+// AsyncAPI builds an AsyncAPI 3.0 document of the events registered with
+// RegisterEvent. Each event gets a channel addressed by its name, a message
+// with its payload schema and a send operation, or receive with
+// EventConfig.Receive. Payload types share components.schemas.
+func AsyncAPI(opts *AsyncAPIOpts) *AsyncAPISpec {
+	if opts == nil {
+		opts = &AsyncAPIOpts{}
+	}
+
+	spec := &AsyncAPISpec{
+		AsyncAPI: "3.0.0",
+		Info: Info{
+			Title:       opts.Title,
+			Description: opts.Description,
+			Version:     opts.Version,
+		},
+		Servers:    opts.Servers,
+		Channels:   make(map[string]AsyncAPIChannel),
+		Operations: make(map[string]AsyncAPIOperation),
+		Components: AsyncAPIComponents{
+			Messages: make(map[string]AsyncAPIMessage),
+			Schemas:  make(map[string]*JSONSchema),
+		},
+	}
+
+	for _, event := range registeredEvents() {
+		key := asyncAPIKey(event.name)
+
+		message := AsyncAPIMessage{
+			Summary:     event.config.Summary,
+			Description: event.config.Description,
+			ContentType: "application/json",
+		}
+		if event.payloadType != nil {
+			message.Name = event.payloadType.Name()
+			message.Payload = generateJSONSchemaFromType(event.payloadType, spec.Components.Schemas)
+		}
+		spec.Components.Messages[key] = message
+
+		spec.Channels[key] = AsyncAPIChannel{
+			Address: event.name,
+			Messages: map[string]AsyncAPIRef{
+				key: {Ref: "#/components/messages/" + key},
+			},
+		}
+
+		action := "send"
+		if event.config.Receive {
+			action = "receive"
+		}
+		spec.Operations[asyncAPIOperationID(action, event.name)] = AsyncAPIOperation{
+			Action:   action,
+			Channel:  AsyncAPIRef{Ref: "#/channels/" + key},
+			Summary:  event.config.Summary,
+			Messages: []AsyncAPIRef{{Ref: "#/channels/" + key + "/messages/" + key}},
+		}
+	}
+
+	if opts.OutputFile != "" {
+		if err := spec.WriteFile(opts.OutputFile); err != nil {
+			fmt.Printf("Error writing AsyncAPI file: %v\n", err)
+		} else {
+			fmt.Printf("AsyncAPI document written to %s\n", opts.OutputFile)
+		}
+	}
+
+	return spec
+}
+
+// asyncAPIKey makes an event name usable as a component key, which allows
+// letters, digits, ".", "-" and "_"
+func asyncAPIKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// asyncAPIOperationID joins the action and event name in camel case:
+// send and user.created give sendUserCreated
+func asyncAPIOperationID(action, name string) string {
+	var b strings.Builder
+	b.WriteString(action)
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// WriteFile writes the document as JSON when the file name contains "json", YAML otherwise
+func (a *AsyncAPISpec) WriteFile(filename string) error {
+	var data []byte
+	var err error
+	if strings.Contains(filename, "json") {
+		data, err = json.MarshalIndent(a, "", "  ")
+	} else {
+		data, err = marshalYAML(a)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal AsyncAPI document: %w", err)
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+// HandleGetAsyncAPI serves the document as JSON when the path contains "json", YAML otherwise
+func (a *AsyncAPISpec) HandleGetAsyncAPI(c *gin.Context) {
+	if strings.Contains(c.Request.URL.Path, "json") {
+		data, _ := json.MarshalIndent(a, "", "  ")
+		c.Data(200, "application/json", data)
+	} else {
+		data, _ := marshalYAML(a)
+		c.Data(200, "text/vnd.yaml", data)
+	}
+}
@@ -0,0 +1,150 @@
+package schema
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeDenied  AuditOutcome = "denied"  // 401, 403 and 429 responses
+	AuditOutcomeInvalid AuditOutcome = "invalid" // other 4xx responses
+	AuditOutcomeError   AuditOutcome = "error"   // 5xx responses
+)
+
+// AuditEvent describes one authenticated (or rejected) request
+type AuditEvent struct {
+	Time       time.Time     `json:"time"`
+	Method     string        `json:"method"`
+	Route      string        `json:"route"`
+	Path       string        `json:"path"`
+	ClientIP   string        `json:"client_ip"`
+	Principal  string        `json:"principal,omitempty"`
+	AuthMethod string        `json:"auth_method,omitempty"`
+	Outcome    AuditOutcome  `json:"outcome"`
+	Status     int           `json:"status"`
+	ErrorCode  string        `json:"error_code,omitempty"`
+	Latency    time.Duration `json:"latency_ns"`
+}
+
+// AuditLogger receives audit events from the security middlewares and ValidateAndHandle
+type AuditLogger interface {
+	Log(event AuditEvent)
+}
+
+// Global audit logger configuration
+var globalAuditLogger AuditLogger
+
+// SetAuditLogger sets the global audit logger, nil disables auditing
+func SetAuditLogger(logger AuditLogger) {
+	globalAuditLogger = logger
+}
+
+// GetAuditLogger returns the current audit logger
+func GetAuditLogger() AuditLogger {
+	return globalAuditLogger
+}
+
+// JSONLinesAuditLogger writes one JSON object per event
+type JSONLinesAuditLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONLinesAuditLogger(w io.Writer) *JSONLinesAuditLogger {
+	return &JSONLinesAuditLogger{w: w}
+}
+
+func (l *JSONLinesAuditLogger) Log(event AuditEvent) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(append(line, '\n'))
+}
+
+// SetPrincipal records who is making the request, for audit events
+func SetPrincipal(c *gin.Context, principal string) {
+	c.Set("principal", principal)
+}
+
+// auditStart returns the time the request entered the first audited handler
+func auditStart(c *gin.Context) time.Time {
+	if start, exists := c.Get("audit_start"); exists {
+		if t, ok := start.(time.Time); ok {
+			return t
+		}
+	}
+
+	now := time.Now()
+	c.Set("audit_start", now)
+	return now
+}
+
+// auditRequest logs the request once, after the response status is known
+func auditRequest(c *gin.Context, start time.Time) {
+	if globalAuditLogger == nil || c.GetBool("audit_logged") {
+		return
+	}
+
+	// Only requests that went through authentication are audited
+	authMethod := c.GetString("auth_method")
+	principal := auditPrincipal(c)
+	status := c.Writer.Status()
+	outcome := auditOutcome(status)
+	if authMethod == "" && principal == "" && outcome != AuditOutcomeDenied {
+		return
+	}
+
+	c.Set("audit_logged", true)
+	globalAuditLogger.Log(AuditEvent{
+		Time:       start,
+		Method:     c.Request.Method,
+		Route:      c.FullPath(),
+		Path:       c.Request.URL.Path,
+		ClientIP:   c.ClientIP(),
+		Principal:  principal,
+		AuthMethod: authMethod,
+		Outcome:    outcome,
+		Status:     status,
+		ErrorCode:  c.GetString("error_code"),
+		Latency:    time.Since(start),
+	})
+}
+
+func auditPrincipal(c *gin.Context) string {
+	if principal := c.GetString("principal"); principal != "" {
+		return principal
+	}
+
+	if info, ok := GetAPIKeyInfo(c); ok {
+		if info.Owner != "" {
+			return info.Owner
+		}
+		return info.ID
+	}
+
+	return ""
+}
+
+func auditOutcome(status int) AuditOutcome {
+	switch {
+	case status == 401 || status == 403 || status == 429:
+		return AuditOutcomeDenied
+	case status >= 500:
+		return AuditOutcomeError
+	case status >= 400:
+		return AuditOutcomeInvalid
+	default:
+		return AuditOutcomeSuccess
+	}
+}
@@ -0,0 +1,205 @@
+package schema
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BruteForceConfig configures failed authentication tracking.
+//
+// Failures expire through Window and are not cleared by a successful
+// authentication unless ResetOnSuccess is set. Only set it when KeyFunc
+// identifies the account being guessed, such as the username: keyed by
+// client IP, an attacker holding any valid credential could authenticate
+// between guesses and never be locked out.
+type BruteForceConfig struct {
+	MaxFailures    int                                               // Failures allowed within Window before locking out (default 5)
+	Window         time.Duration                                     // Sliding window for counting failures (default 1m)
+	Lockout        time.Duration                                     // How long a key stays locked out (default 5m)
+	MaxEntries     int                                               // Callers tracked at once, the least recently failed are dropped beyond it (default 100000)
+	KeyFunc        func(c *gin.Context) string                       // Identifies the caller, defaults to the client IP
+	ResetOnSuccess bool                                              // Clear the caller's failures when it authenticates, only with an account KeyFunc
+	OnLockout      func(c *gin.Context, key string, until time.Time) // Called when a key gets locked out (optional)
+}
+
+// BruteForceProtector tracks failed authentication attempts per caller and
+// rejects callers with 429 while they are locked out. A nil protector is
+// valid and does nothing.
+type BruteForceProtector struct {
+	config    BruteForceConfig
+	mu        sync.Mutex
+	entries   map[string]*bruteForceEntry
+	lastSweep time.Time
+}
+
+type bruteForceEntry struct {
+	failures    []time.Time
+	lockedUntil time.Time
+	lastFailure time.Time
+}
+
+// expired reports whether an entry has no failures left in the window and
+// no lockout, so forgetting it changes nothing
+func (e *bruteForceEntry) expired(now time.Time, window time.Duration) bool {
+	return !now.Before(e.lockedUntil) && !e.lastFailure.After(now.Add(-window))
+}
+
+// NewBruteForceProtector creates a protector, filling in defaults for unset values
+func NewBruteForceProtector(config BruteForceConfig) *BruteForceProtector {
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = 5
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Lockout <= 0 {
+		config.Lockout = 5 * time.Minute
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 100000
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(c *gin.Context) string {
+			return c.ClientIP()
+		}
+	}
+
+	return &BruteForceProtector{
+		config:  config,
+		entries: make(map[string]*bruteForceEntry),
+	}
+}
+
+// rejectIfLocked responds with 429 and reports true if the caller is locked out
+func (p *BruteForceProtector) rejectIfLocked(c *gin.Context) bool {
+	if p == nil {
+		return false
+	}
+
+	key := p.config.KeyFunc(c)
+	now := time.Now()
+
+	p.mu.Lock()
+	entry, exists := p.entries[key]
+	var until time.Time
+	if exists && now.Before(entry.lockedUntil) {
+		until = entry.lockedUntil
+	}
+	p.mu.Unlock()
+
+	if until.IsZero() {
+		return false
+	}
+
+	retryAfter := int(until.Sub(now).Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(429, globalWrapper.WrapError("TOO_MANY_REQUESTS", "Too many failed authentication attempts"))
+	c.Abort()
+	return true
+}
+
+// recordFailure counts a failed attempt and locks the caller out once the threshold is reached
+func (p *BruteForceProtector) recordFailure(c *gin.Context) {
+	if p == nil {
+		return
+	}
+
+	key := p.config.KeyFunc(c)
+	now := time.Now()
+
+	p.mu.Lock()
+	p.sweep(now)
+	entry, exists := p.entries[key]
+	if !exists {
+		if len(p.entries) >= p.config.MaxEntries {
+			p.evictOldest(now)
+		}
+		entry = &bruteForceEntry{}
+		p.entries[key] = entry
+	}
+	entry.lastFailure = now
+
+	// Drop failures that fell out of the window
+	cutoff := now.Add(-p.config.Window)
+	kept := entry.failures[:0]
+	for _, failure := range entry.failures {
+		if failure.After(cutoff) {
+			kept = append(kept, failure)
+		}
+	}
+	entry.failures = append(kept, now)
+
+	lockedOut := false
+	if len(entry.failures) >= p.config.MaxFailures {
+		entry.lockedUntil = now.Add(p.config.Lockout)
+		entry.failures = nil
+		lockedOut = true
+	}
+	until := entry.lockedUntil
+	p.mu.Unlock()
+
+	if lockedOut && p.config.OnLockout != nil {
+		p.config.OnLockout(c, key, until)
+	}
+}
+
+// sweep drops expired entries, at most once per window so failures stay
+// cheap. Callers that fail and never succeed would stay forever otherwise.
+func (p *BruteForceProtector) sweep(now time.Time) {
+	if now.Sub(p.lastSweep) < p.config.Window {
+		return
+	}
+	p.lastSweep = now
+
+	for key, entry := range p.entries {
+		if entry.expired(now, p.config.Window) {
+			delete(p.entries, key)
+		}
+	}
+}
+
+// evictOldest drops the entry that failed least recently, making room for
+// a new caller once MaxEntries are tracked. Locked out callers are dropped
+// last, so flooding the protector with new keys doesn't lift a lockout.
+func (p *BruteForceProtector) evictOldest(now time.Time) {
+	var oldestKey string
+	var oldest *bruteForceEntry
+	for key, entry := range p.entries {
+		if oldest == nil || evictsBefore(entry, oldest, now) {
+			oldestKey, oldest = key, entry
+		}
+	}
+	delete(p.entries, oldestKey)
+}
+
+// evictsBefore reports whether a is evicted before b
+func evictsBefore(a, b *bruteForceEntry, now time.Time) bool {
+	aLocked, bLocked := now.Before(a.lockedUntil), now.Before(b.lockedUntil)
+	if aLocked != bLocked {
+		return bLocked
+	}
+	return a.lastFailure.Before(b.lastFailure)
+}
+
+// recordSuccess clears the failure history of the caller when ResetOnSuccess is set
+func (p *BruteForceProtector) recordSuccess(c *gin.Context) {
+	if p == nil || !p.config.ResetOnSuccess {
+		return
+	}
+
+	key := p.config.KeyFunc(c)
+
+	p.mu.Lock()
+	delete(p.entries, key)
+	p.mu.Unlock()
+}
+
+// Unlock clears a lockout early, for example from an admin endpoint
+func (p *BruteForceProtector) Unlock(key string) {
+	p.mu.Lock()
+	delete(p.entries, key)
+	p.mu.Unlock()
+}
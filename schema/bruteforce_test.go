@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bruteForceServer serves GET /private behind an API key scheme accepting
+// "valid", protected by protector
+func bruteForceServer(protector *BruteForceProtector) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	apiKey := NewAPIKeySecurity(APIKeyConfig{
+		Name:        "ApiKeyAuth",
+		In:          "header",
+		KeyName:     "X-API-Key",
+		ValidateKey: func(c *gin.Context, key string) bool { return key == "valid" },
+		BruteForce:  protector,
+	})
+
+	engine := gin.New()
+	engine.GET("/private", apiKey.Middleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return engine
+}
+
+func sendAPIKey(engine *gin.Engine, key, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-API-Key", key)
+	req.Header.Set("X-User", user)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func expectStatuses(t *testing.T, engine *gin.Engine, user string, keys []string, statuses []int) {
+	t.Helper()
+	for i, key := range keys {
+		if w := sendAPIKey(engine, key, user); w.Code != statuses[i] {
+			t.Fatalf("request %d with key %q: expected %d, got %d", i+1, key, statuses[i], w.Code)
+		}
+	}
+}
+
+func TestBruteForceProtector(t *testing.T) {
+	t.Run("locks out after MaxFailures", func(t *testing.T) {
+		var lockedKey string
+		engine := bruteForceServer(NewBruteForceProtector(BruteForceConfig{
+			MaxFailures: 3,
+			Lockout:     time.Minute,
+			OnLockout:   func(c *gin.Context, key string, until time.Time) { lockedKey = key },
+		}))
+
+		expectStatuses(t, engine, "", []string{"bad", "bad", "bad"}, []int{401, 401, 401})
+		if lockedKey != "192.0.2.1" {
+			t.Errorf("expected the client IP to be locked out, got %q", lockedKey)
+		}
+
+		// Locked out callers are rejected even with a valid key
+		w := sendAPIKey(engine, "valid", "")
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", w.Code)
+		}
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		if err != nil || retryAfter <= 0 || retryAfter > 61 {
+			t.Errorf("expected Retry-After within the lockout, got %q", w.Header().Get("Retry-After"))
+		}
+		var body ErrorResult
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.ErrorInfo.Code != "TOO_MANY_REQUESTS" {
+			t.Errorf("expected a wrapped TOO_MANY_REQUESTS error, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("success does not reset failures by client IP", func(t *testing.T) {
+		engine := bruteForceServer(NewBruteForceProtector(BruteForceConfig{MaxFailures: 3}))
+
+		// A valid key between guesses must not keep the lockout from triggering
+		expectStatuses(t, engine, "",
+			[]string{"bad", "bad", "valid", "bad", "valid"},
+			[]int{401, 401, 200, 401, 429})
+	})
+
+	t.Run("ResetOnSuccess clears the account's failures", func(t *testing.T) {
+		engine := bruteForceServer(NewBruteForceProtector(BruteForceConfig{
+			MaxFailures:    3,
+			ResetOnSuccess: true,
+			KeyFunc:        func(c *gin.Context) string { return c.GetHeader("X-User") },
+		}))
+
+		expectStatuses(t, engine, "alice",
+			[]string{"bad", "bad", "valid", "bad", "bad", "valid"},
+			[]int{401, 401, 200, 401, 401, 200})
+	})
+
+	t.Run("Unlock clears a lockout", func(t *testing.T) {
+		protector := NewBruteForceProtector(BruteForceConfig{MaxFailures: 1})
+		engine := bruteForceServer(protector)
+
+		expectStatuses(t, engine, "", []string{"bad", "valid"}, []int{401, 429})
+		protector.Unlock("192.0.2.1")
+		expectStatuses(t, engine, "", []string{"valid"}, []int{200})
+	})
+
+	t.Run("expired entries are pruned and capped", func(t *testing.T) {
+		protector := NewBruteForceProtector(BruteForceConfig{MaxFailures: 5, Window: time.Minute, MaxEntries: 2})
+		now := time.Now()
+		protector.entries["old"] = &bruteForceEntry{lastFailure: now.Add(-2 * time.Minute)}
+		protector.entries["locked"] = &bruteForceEntry{lastFailure: now.Add(-2 * time.Minute), lockedUntil: now.Add(time.Minute)}
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/private", nil)
+		c.Request.RemoteAddr = "192.0.2.2:1234"
+		protector.recordFailure(c)
+
+		if _, exists := protector.entries["old"]; exists {
+			t.Errorf("expected the expired entry to be pruned")
+		}
+		if _, exists := protector.entries["locked"]; !exists {
+			t.Errorf("expected the locked out entry to be kept")
+		}
+
+		// At the cap, callers that aren't locked out are evicted first
+		c.Request.RemoteAddr = "192.0.2.3:1234"
+		protector.recordFailure(c)
+		if len(protector.entries) != 2 {
+			t.Errorf("expected at most 2 entries, got %d", len(protector.entries))
+		}
+		for _, key := range []string{"locked", "192.0.2.3"} {
+			if _, exists := protector.entries[key]; !exists {
+				t.Errorf("expected %s to be tracked", key)
+			}
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControl sets the Cache-Control header of a route's responses, see
+// WithCacheControl
+type CacheControl struct {
+	value string
+}
+
+// WithCacheControl creates a route option that sends value as the
+// Cache-Control header of successful responses and documents it in the spec,
+// so the caching policy lives next to the route definition:
+//
+//	router.GET("/countries", schema.WithCacheControl("public, max-age=3600"), schema.ValidateAndHandle(ListCountries))
+//
+// Error responses (4xx and 5xx) and handlers that set Cache-Control
+// themselves are left alone.
+func WithCacheControl(value string) *CacheControl {
+	return &CacheControl{value: value}
+}
+
+// NoStore creates a route option forbidding any cache from storing responses
+func NoStore() *CacheControl {
+	return WithCacheControl("no-store")
+}
+
+// PrivateCache creates a route option letting only the client's own cache
+// store responses, for up to maxAge
+func PrivateCache(maxAge time.Duration) *CacheControl {
+	return WithCacheControl(fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+}
+
+// PublicCache creates a route option letting shared caches such as CDNs
+// store responses, for up to maxAge
+func PublicCache(maxAge time.Duration) *CacheControl {
+	return WithCacheControl(fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+}
+
+// Value returns the Cache-Control header value
+func (cc *CacheControl) Value() string {
+	return cc.value
+}
+
+// Middleware returns the gin.HandlerFunc setting the header
+func (cc *CacheControl) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = &cacheControlWriter{ResponseWriter: c.Writer, value: cc.value}
+		c.Next()
+	}
+}
+
+// cacheControlWriter sets Cache-Control just before the header is written,
+// once the status is known
+type cacheControlWriter struct {
+	gin.ResponseWriter
+	value   string
+	applied bool
+}
+
+func (w *cacheControlWriter) apply() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+
+	if w.Status() >= http.StatusBadRequest || w.Header().Get("Cache-Control") != "" {
+		return
+	}
+	w.Header().Set("Cache-Control", w.value)
+}
+
+func (w *cacheControlWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheControlWriter) Write(data []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheControlWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheControlWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
+
+// RegisterCacheControl records the caching policy of a route
+func (r *Registry) RegisterCacheControl(method, path string, cacheControl *CacheControl) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cacheControl[routeKey(method, path)] = cacheControl
+}
+
+// GetCacheControl retrieves the caching policy of a route
+func (r *Registry) GetCacheControl(method, path string) (*CacheControl, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cacheControl, exists := r.cacheControl[routeKey(method, path)]
+	return cacheControl, exists
+}
+
+// RegisterCacheControl records the caching policy of a route in the global registry
+func RegisterCacheControl(method, path string, cacheControl *CacheControl) {
+	defaultRegistry.RegisterCacheControl(method, path, cacheControl)
+}
+
+// GetCacheControl retrieves the caching policy of a route from the global registry
+func GetCacheControl(method, path string) (*CacheControl, bool) {
+	return defaultRegistry.GetCacheControl(method, path)
+}
+
+// addCacheControl documents the Cache-Control header of successful responses
+func addCacheControl(operation *Operation, value string) {
+	headerSchema := newJSONSchema("string", nil)
+	headerSchema.Example = value
+
+	for status, response := range operation.Responses {
+		if status >= "400" {
+			continue
+		}
+		if response.Headers == nil {
+			response.Headers = make(map[string]Header)
+		}
+		response.Headers["Cache-Control"] = Header{
+			Description: fmt.Sprintf("Caching policy of the response, always %q", value),
+			Schema:      headerSchema,
+		}
+		operation.Responses[status] = response
+	}
+}
@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// Compact deduplicates structurally identical component schemas and removes
+// components no operation references. OpenAPI calls it after generation; call
+// it again after editing a spec by hand.
+func (o *OpenAPISpec) Compact() {
+	if o.Components == nil {
+		return
+	}
+
+	// Merging two schemas can make their parents identical, so repeat until stable
+	for o.dedupeSchemas() {
+	}
+
+	referenced := o.referencedSchemas()
+	for name := range o.Components.Schemas {
+		if !referenced[name] {
+			delete(o.Components.Schemas, name)
+		}
+	}
+}
+
+// dedupeSchemas merges identical schemas into one name and reports whether anything changed
+func (o *OpenAPISpec) dedupeSchemas() bool {
+	byShape := make(map[string][]string)
+	for name, schema := range o.Components.Schemas {
+		shape, err := canonicalJSON(schema)
+		if err != nil {
+			continue
+		}
+		byShape[string(shape)] = append(byShape[string(shape)], name)
+	}
+
+	renames := make(map[string]string)
+	for _, names := range byShape {
+		if len(names) < 2 {
+			continue
+		}
+
+		sort.Slice(names, func(i, j int) bool {
+			return preferSchemaName(names[i], names[j])
+		})
+		for _, duplicate := range names[1:] {
+			renames[componentSchemaPrefix+duplicate] = componentSchemaPrefix + names[0]
+			delete(o.Components.Schemas, duplicate)
+		}
+	}
+
+	if len(renames) == 0 {
+		return false
+	}
+
+	rewrite := func(schema *JSONSchema) {
+		walkSchemas(schema, func(s *JSONSchema) {
+			if target, ok := renames[s.Ref]; ok {
+				s.Ref = target
+			}
+		})
+	}
+	for _, entry := range o.operations() {
+		forEachOperationSchema(entry.Operation, rewrite)
+	}
+	for _, schema := range o.Components.Schemas {
+		rewrite(schema)
+	}
+
+	return true
+}
+
+// preferSchemaName orders candidate names: generated anonymous names last, then shorter, then alphabetical
+func preferSchemaName(a, b string) bool {
+	anonymousA := strings.HasPrefix(a, "AnonymousStruct")
+	anonymousB := strings.HasPrefix(b, "AnonymousStruct")
+	if anonymousA != anonymousB {
+		return anonymousB
+	}
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// walkSchemas calls fn for schema and every schema nested in it
+func walkSchemas(schema *JSONSchema, fn func(*JSONSchema)) {
+	if schema == nil {
+		return
+	}
+
+	fn(schema)
+	for _, property := range schema.Properties {
+		walkSchemas(property, fn)
+	}
+	walkSchemas(schema.Items, fn)
+	if additional, ok := schema.AdditionalProperties.(*JSONSchema); ok {
+		walkSchemas(additional, fn)
+	}
+}
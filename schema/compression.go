@@ -0,0 +1,247 @@
+package schema
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompressionEncoder creates a compressing writer for one response
+type CompressionEncoder func(w io.Writer) (io.WriteCloser, error)
+
+// CompressionConfig configures response compression
+type CompressionConfig struct {
+	MinSize      int                           // Responses smaller than this are sent as is (default 1024 bytes)
+	ContentTypes []string                      // Compressible types, "text/*" matches a prefix (default JSON, YAML and text)
+	GzipLevel    int                           // gzip level, 0 for gzip.DefaultCompression
+	Encoders     map[string]CompressionEncoder // Additional encodings by name, e.g. "br"
+}
+
+var defaultCompressionTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/yaml",
+	"text/*",
+}
+
+// UseCompression adds response compression to the router. Clients choose the
+// encoding with Accept-Encoding; registered Encoders are preferred over gzip,
+// so registering a "br" encoder enables brotli for clients that accept it.
+func (r *RouterHelper) UseCompression(config CompressionConfig) gin.IRoutes {
+	return r.Engine.Use(CompressionMiddleware(config))
+}
+
+// CompressionMiddleware returns the compression middleware used by UseCompression
+func CompressionMiddleware(config CompressionConfig) gin.HandlerFunc {
+	if config.MinSize <= 0 {
+		config.MinSize = 1024
+	}
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = defaultCompressionTypes
+	}
+	if config.GzipLevel == 0 {
+		config.GzipLevel = gzip.DefaultCompression
+	}
+
+	encoders := map[string]CompressionEncoder{
+		"gzip": func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, config.GzipLevel)
+		},
+	}
+	preference := []string{}
+	for name, encoder := range config.Encoders {
+		encoders[name] = encoder
+		preference = append(preference, name)
+	}
+	sort.Strings(preference)
+	preference = append(preference, "gzip")
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), preference)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			config:         &config,
+			encoding:       encoding,
+			newEncoder:     encoders[encoding],
+		}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the first preferred encoding the client accepts
+func negotiateEncoding(acceptEncoding string, preference []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, name := range preference {
+		quality, ok := accepted[name]
+		if !ok {
+			quality, ok = accepted["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = name, quality
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until it knows whether
+// compressing it is worthwhile
+type compressWriter struct {
+	gin.ResponseWriter
+	config     *CompressionConfig
+	encoding   string
+	newEncoder CompressionEncoder
+
+	buffer  []byte
+	decided bool
+	encoder io.WriteCloser
+	size    int
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	if w.decided {
+		return w.writeOut(data)
+	}
+
+	w.buffer = append(w.buffer, data...)
+	if len(w.buffer) >= w.config.MinSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Written() bool {
+	return w.size > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Size() int {
+	if w.size == 0 {
+		return w.ResponseWriter.Size()
+	}
+	return w.size
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide starts compressing when the response qualifies and writes out the buffer
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	header := w.Header()
+	if w.compressible() {
+		header.Add("Vary", "Accept-Encoding")
+		if len(w.buffer) >= w.config.MinSize {
+			encoder, err := w.newEncoder(w.ResponseWriter)
+			if err != nil {
+				return err
+			}
+			w.encoder = encoder
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+		}
+	}
+
+	buffered := w.buffer
+	w.buffer = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	_, err := w.writeOut(buffered)
+	return err
+}
+
+func (w *compressWriter) writeOut(data []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// finish writes out anything still buffered and closes the encoder
+func (w *compressWriter) finish() {
+	if !w.decided {
+		if len(w.buffer) == 0 {
+			return
+		}
+		w.decide()
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
+
+func (w *compressWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	status := w.Status()
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	contentType, _, _ := strings.Cut(header.Get("Content-Type"), ";")
+	contentType = strings.TrimSpace(strings.ToLower(contentType))
+	for _, allowed := range w.config.ContentTypes {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(contentType, prefix) {
+				return true
+			}
+		} else if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConcurrencyLimit caps how many requests a route handles at once. Requests
+// over the limit wait up to the queue timeout for a free slot and are then
+// rejected with a wrapped 503. Passing the same limit to several routes
+// shares the slots between them.
+type ConcurrencyLimit struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+}
+
+// WithConcurrencyLimit creates a route option allowing n concurrent requests.
+// Without a queue timeout, requests over the limit are rejected immediately.
+func WithConcurrencyLimit(n int) *ConcurrencyLimit {
+	if n <= 0 {
+		n = 1
+	}
+
+	return &ConcurrencyLimit{
+		slots: make(chan struct{}, n),
+	}
+}
+
+// WithQueueTimeout sets how long a request waits for a free slot
+func (l *ConcurrencyLimit) WithQueueTimeout(timeout time.Duration) *ConcurrencyLimit {
+	l.queueTimeout = timeout
+	return l
+}
+
+// InFlight returns the number of requests currently being handled
+func (l *ConcurrencyLimit) InFlight() int {
+	return len(l.slots)
+}
+
+// Middleware returns the gin.HandlerFunc enforcing the limit
+func (l *ConcurrencyLimit) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !l.acquire(c) {
+			respondError(c, 503, "SERVICE_UNAVAILABLE", "Too many concurrent requests, try again later")
+			c.Abort()
+			return
+		}
+		defer l.release()
+
+		c.Next()
+	}
+}
+
+func (l *ConcurrencyLimit) acquire(c *gin.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.queueTimeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
+
+func (l *ConcurrencyLimit) release() {
+	<-l.slots
+}
+
+// RegisterConcurrencyLimit records the concurrency limit of a route
+func (r *Registry) RegisterConcurrencyLimit(method, path string, limit *ConcurrencyLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.concurrency[routeKey(method, path)] = limit
+}
+
+// GetConcurrencyLimit retrieves the concurrency limit of a route
+func (r *Registry) GetConcurrencyLimit(method, path string) (*ConcurrencyLimit, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	limit, exists := r.concurrency[routeKey(method, path)]
+	return limit, exists
+}
+
+// RegisterConcurrencyLimit records the concurrency limit of a route in the global registry
+func RegisterConcurrencyLimit(method, path string, limit *ConcurrencyLimit) {
+	defaultRegistry.RegisterConcurrencyLimit(method, path, limit)
+}
+
+// GetConcurrencyLimit retrieves the concurrency limit of a route from the global registry
+func GetConcurrencyLimit(method, path string) (*ConcurrencyLimit, bool) {
+	return defaultRegistry.GetConcurrencyLimit(method, path)
+}
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseEnvelope is implemented by response wrappers that carry metadata
+// alongside the data returned by a handler
+type responseEnvelope interface {
+	responseData() interface{}
+	responseDataType() reflect.Type
+}
+
+// LastModifiedResult wraps handler data with its modification time
+type LastModifiedResult[T any] struct {
+	LastModified time.Time
+	Data         T
+}
+
+// WithLastModified wraps data so the framework sets the Last-Modified header
+// and answers GET/HEAD requests carrying a matching If-Modified-Since with 304
+func WithLastModified[T any](t time.Time, data T) *LastModifiedResult[T] {
+	return &LastModifiedResult[T]{
+		LastModified: t,
+		Data:         data,
+	}
+}
+
+func (r LastModifiedResult[T]) responseData() interface{} {
+	return r.Data
+}
+
+func (r LastModifiedResult[T]) responseDataType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (r LastModifiedResult[T]) lastModified() time.Time {
+	return r.LastModified
+}
+
+type lastModifier interface {
+	lastModified() time.Time
+}
+
+var lastModifierType = reflect.TypeOf((*lastModifier)(nil)).Elem()
+
+// unwrapResponseType returns the documented data type of a handler response
+// type, and whether the response supports conditional requests
+func unwrapResponseType(t reflect.Type) (reflect.Type, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	envelope, ok := reflect.New(t).Elem().Interface().(responseEnvelope)
+	if !ok {
+		return t, false
+	}
+
+	return envelope.responseDataType(), t.Implements(lastModifierType)
+}
+
+// handleLastModified sets Last-Modified and reports whether the request was
+// answered with 304 Not Modified
+func handleLastModified(c *gin.Context, modified time.Time) bool {
+	if modified.IsZero() {
+		return false
+	}
+
+	// HTTP dates have second precision
+	modified = modified.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", modified.Format(http.TimeFormat))
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	since := c.GetHeader("If-Modified-Since")
+	if since == "" {
+		return false
+	}
+
+	sinceTime, err := http.ParseTime(since)
+	if err != nil || modified.After(sinceTime) {
+		return false
+	}
+
+	c.Status(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentTypes restricts the request body media types of a route, see
+// AcceptContentTypes
+type ContentTypes struct {
+	types []string
+}
+
+// AcceptContentTypes creates a route option rejecting request bodies sent
+// with any other Content-Type with 415 Unsupported Media Type, instead of
+// attempting to parse them as JSON. Types may end in a wildcard subtype such
+// as "image/*". The spec documents the request body under each type.
+//
+//	router.POST("/users", schema.AcceptContentTypes("application/json"), schema.ValidateAndHandle(CreateUser))
+//
+// Requests without a body are let through.
+func AcceptContentTypes(types ...string) *ContentTypes {
+	normalized := make([]string, len(types))
+	for i, contentType := range types {
+		normalized[i] = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return &ContentTypes{types: normalized}
+}
+
+// Types returns the accepted media types
+func (ct *ContentTypes) Types() []string {
+	return ct.types
+}
+
+// Accepts reports whether a Content-Type header value is one of the accepted types
+func (ct *ContentTypes) Accepts(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, accepted := range ct.types {
+		if accepted == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(accepted, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware returns the gin.HandlerFunc rejecting other media types
+func (ct *ContentTypes) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasRequestBody(c.Request) {
+			c.Next()
+			return
+		}
+
+		contentType := c.GetHeader("Content-Type")
+		if !ct.Accepts(contentType) {
+			if contentType == "" {
+				contentType = "none"
+			}
+			respondError(c, http.StatusUnsupportedMediaType, "ERR_UNSUPPORTED_MEDIA_TYPE",
+				fmt.Sprintf("Content-Type %s is not supported, use %s", contentType, strings.Join(ct.types, " or ")))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// hasRequestBody reports whether a request carries a body
+func hasRequestBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+// RegisterContentTypes records the accepted request body media types of a route
+func (r *Registry) RegisterContentTypes(method, path string, contentTypes *ContentTypes) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.contentTypes[routeKey(method, path)] = contentTypes
+}
+
+// GetContentTypes retrieves the accepted request body media types of a route
+func (r *Registry) GetContentTypes(method, path string) (*ContentTypes, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	contentTypes, exists := r.contentTypes[routeKey(method, path)]
+	return contentTypes, exists
+}
+
+// RegisterContentTypes records the accepted request body media types of a
+// route in the global registry
+func RegisterContentTypes(method, path string, contentTypes *ContentTypes) {
+	defaultRegistry.RegisterContentTypes(method, path, contentTypes)
+}
+
+// GetContentTypes retrieves the accepted request body media types of a route
+// from the global registry
+func GetContentTypes(method, path string) (*ContentTypes, bool) {
+	return defaultRegistry.GetContentTypes(method, path)
+}
+
+// addContentTypes documents the request body under each accepted media type
+// and the 415 response for the others
+func addContentTypes(operation *Operation, types []string, schemas map[string]*JSONSchema) {
+	if operation.RequestBody != nil {
+		bodySchema := operation.RequestBody.Content["application/json"].Schema
+		content := make(map[string]MediaType, len(types))
+		for _, contentType := range types {
+			if mediaType, ok := operation.RequestBody.Content[contentType]; ok {
+				content[contentType] = mediaType
+				continue
+			}
+			content[contentType] = MediaType{Schema: bodySchema}
+		}
+		operation.RequestBody.Content = content
+	}
+
+	unsupported := generateErrorResponse(schemas)
+	unsupported.Description = "Unsupported Media Type"
+	operation.Responses["415"] = unsupported
+}
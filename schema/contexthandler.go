@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextHandlerFunc is a handler taking the request's context.Context
+// instead of the gin.Context, so it can be called without gin, e.g. from
+// tests or other transports
+type ContextHandlerFunc[T Schema, R any] func(ctx context.Context, schema T) (*R, error)
+
+// SchemaHandlerFunc is a handler that only needs its validated input
+type SchemaHandlerFunc[T Schema, R any] func(schema T) (*R, error)
+
+type ginContextKey struct{}
+
+// ValidateAndHandleContext wraps a handler taking a context.Context like
+// ValidateAndHandle. The handler gets the request's context, which is
+// cancelled when the client goes away, carrying the gin.Context for the rare
+// handler that needs it, see GinContext:
+//
+//	func GetUser(ctx context.Context, req GetUserRequest) (*User, error) {
+//		return users.Find(ctx, req.Params.ID)
+//	}
+//
+//	router.GET("/users/:id", schema.ValidateAndHandleContext(GetUser))
+func ValidateAndHandleContext[T Schema, R any](handler ContextHandlerFunc[T, R]) TypedHandlerFunc {
+	return ValidateAndHandle(func(c *gin.Context, schema T) (*R, error) {
+		ctx := context.WithValue(c.Request.Context(), ginContextKey{}, c)
+		return handler(ctx, schema)
+	})
+}
+
+// ValidateAndHandleSchema wraps a handler that only takes its validated
+// input like ValidateAndHandle
+func ValidateAndHandleSchema[T Schema, R any](handler SchemaHandlerFunc[T, R]) TypedHandlerFunc {
+	return ValidateAndHandle(func(c *gin.Context, schema T) (*R, error) {
+		return handler(schema)
+	})
+}
+
+// GinContext returns the gin.Context of a context passed to a
+// ContextHandlerFunc, or nil when ctx does not come from a request, such as
+// in tests calling the handler directly
+func GinContext(ctx context.Context) *gin.Context {
+	c, _ := ctx.Value(ginContextKey{}).(*gin.Context)
+	return c
+}
@@ -0,0 +1,103 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cookieName returns the cookie a field is read from: its `cookie` tag, or
+// the lowercase field name
+func cookieName(field reflect.StructField) string {
+	if name := getTagValue(field, "cookie"); name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
+
+// parseCookies extracts request cookies into the fields of a Cookies section
+func parseCookies(c *gin.Context, field reflect.Value) error {
+	fieldType := field.Type()
+
+	for i := 0; i < field.NumField(); i++ {
+		structField := field.Field(i)
+		typeField := fieldType.Field(i)
+
+		if !structField.CanSet() {
+			continue
+		}
+
+		if err := parseCookieField(c, structField, typeField); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseCookieField extracts a single cookie into structField
+func parseCookieField(c *gin.Context, structField reflect.Value, typeField reflect.StructField) error {
+	name := cookieName(typeField)
+
+	value, err := c.Cookie(name)
+	if err != nil || value == "" {
+		// Check for default value
+		if defaultVal := getTagValue(typeField, "default"); defaultVal != "" {
+			value = defaultVal
+		} else if isRequired(typeField) {
+			return fmt.Errorf("required cookie '%s' is missing", name)
+		} else {
+			return nil
+		}
+	}
+
+	if err := setFieldValue(structField, value); err != nil {
+		// Conversion errors echo the raw value, so drop them for sensitive fields
+		if isSensitiveField(typeField) {
+			return fmt.Errorf("invalid cookie '%s'", name)
+		}
+		return fmt.Errorf("invalid cookie '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+func extractCookieParameters(cookiesType reflect.Type, schemas map[string]*JSONSchema) []Parameter {
+	var parameters []Parameter
+
+	// Handle pointers
+	if cookiesType.Kind() == reflect.Ptr {
+		cookiesType = cookiesType.Elem()
+	}
+
+	// Ensure we have a struct type before calling NumField
+	if cookiesType.Kind() != reflect.Struct {
+		return parameters
+	}
+
+	for i := 0; i < cookiesType.NumField(); i++ {
+		parameters = append(parameters, cookieParameter(cookiesType.Field(i), schemas))
+	}
+
+	return parameters
+}
+
+// cookieParameter documents a cookie field
+func cookieParameter(field reflect.StructField, schemas map[string]*JSONSchema) Parameter {
+	jsonSchema := generateJSONSchemaFromType(field.Type, schemas)
+
+	// Check if parameter has a default value
+	if defaultVal := getTagValue(field, "default"); defaultVal != "" {
+		jsonSchema.Default = parseDefaultValue(defaultVal, field.Type)
+	}
+
+	return Parameter{
+		Name:       cookieName(field),
+		In:         "cookie",
+		Required:   isRequired(field),
+		Schema:     jsonSchema,
+		Extensions: parseExtensionTag(field),
+	}
+}
@@ -0,0 +1,295 @@
+package schema
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fxfn/x/crypt"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrCSRFTokenMissing = errors.New("CSRF token is missing")
+	ErrCSRFTokenInvalid = errors.New("CSRF token is invalid")
+)
+
+// CSRFConfig holds configuration for CSRF protection
+type CSRFConfig struct {
+	Key          []byte        // HMAC-SHA256 key signing the tokens
+	CookieName   string        // Cookie carrying the token (default "csrf_token")
+	HeaderName   string        // Header the client echoes the token in (default "X-CSRF-Token")
+	FormField    string        // Form field accepted instead of the header (default "csrf_token")
+	TTL          time.Duration // How long a token is valid (default 12 hours)
+	CookiePath   string        // Path of the cookie (default "/")
+	CookieDomain string        // Domain of the cookie (optional)
+	SameSite     http.SameSite // SameSite of the cookie (default Lax)
+	Insecure     bool          // Send the cookie over plain HTTP too, for local development
+
+	// SessionID returns the caller's session, which the token signature
+	// covers so a token is only accepted from the session it was issued to.
+	// It can return "" for callers without a session. (optional)
+	SessionID func(c *gin.Context) string
+}
+
+// CSRFProtection guards unsafe requests against cross-site request forgery
+// with signed double-submit cookies. Safe requests (GET, HEAD, OPTIONS and
+// TRACE) are issued a token in an HttpOnly cookie and in the response
+// header. Other requests must echo the cookie's token in the header, or in
+// the form field of URL-encoded forms, and are rejected with 403
+// ERR_CSRF_INVALID otherwise.
+//
+//	csrf := schema.NewCSRFProtection(schema.CSRFConfig{Key: key})
+//	router.GET("/csrf-token", csrf.TokenHandler())
+//	router.POST("/transfers", csrf, schema.ValidateAndHandle(CreateTransfer))
+//
+// Tokens are signed with crypt.SignMAC, so they can't be forged without the
+// key. A sibling subdomain can still plant a cookie with a token the server
+// issued to it; set SessionID to bind tokens to the session they were issued
+// to and reject those. Passed as a route option the header is documented as
+// a required parameter; csrf.Middleware() can also protect a whole router or
+// group with router.Use.
+type CSRFProtection struct {
+	config CSRFConfig
+}
+
+// CSRFToken is the response of the token endpoint, see TokenHandler
+type CSRFToken struct {
+	Token     string    `json:"token"`
+	Header    string    `json:"header"` // Header to send the token in
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewCSRFProtection creates CSRF protection signing tokens with config.Key
+func NewCSRFProtection(config CSRFConfig) *CSRFProtection {
+	if config.CookieName == "" {
+		config.CookieName = "csrf_token"
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = "X-CSRF-Token"
+	}
+	if config.FormField == "" {
+		config.FormField = "csrf_token"
+	}
+	if config.TTL <= 0 {
+		config.TTL = 12 * time.Hour
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+
+	return &CSRFProtection{config: config}
+}
+
+// HeaderName returns the header the client sends the token in
+func (p *CSRFProtection) HeaderName() string {
+	return p.config.HeaderName
+}
+
+// Token returns the request's token, issuing a new one in the cookie when
+// the request has none or it is invalid or expired
+func (p *CSRFProtection) Token(c *gin.Context) (*CSRFToken, error) {
+	if cookie, err := c.Cookie(p.config.CookieName); err == nil {
+		if expiresAt, err := p.verifyToken(c, cookie); err == nil {
+			return &CSRFToken{Token: cookie, Header: p.config.HeaderName, ExpiresAt: expiresAt}, nil
+		}
+	}
+
+	nonce, err := crypt.RandomToken(32, crypt.TokenBase64URL)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(p.config.TTL).Truncate(time.Second)
+	payload := nonce + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	token := payload + "." + base64.RawURLEncoding.EncodeToString(crypt.SignMAC(p.config.Key, p.signed(c, payload)))
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     p.config.CookieName,
+		Value:    token,
+		Path:     p.config.CookiePath,
+		Domain:   p.config.CookieDomain,
+		Expires:  expiresAt,
+		Secure:   !p.config.Insecure,
+		HttpOnly: true,
+		SameSite: p.config.SameSite,
+	})
+	// Later calls during the request see the issued token
+	c.Request.AddCookie(&http.Cookie{Name: p.config.CookieName, Value: token})
+
+	return &CSRFToken{Token: token, Header: p.config.HeaderName, ExpiresAt: expiresAt}, nil
+}
+
+// Verify checks that the request echoes the signed token of its cookie
+func (p *CSRFProtection) Verify(c *gin.Context) error {
+	cookie, err := c.Cookie(p.config.CookieName)
+	if err != nil || cookie == "" {
+		return ErrCSRFTokenMissing
+	}
+
+	submitted := c.GetHeader(p.config.HeaderName)
+	if submitted == "" && isFormRequest(c) {
+		submitted = c.PostForm(p.config.FormField)
+	}
+	if submitted == "" {
+		return ErrCSRFTokenMissing
+	}
+
+	if subtle.ConstantTimeCompare([]byte(submitted), []byte(cookie)) != 1 {
+		return ErrCSRFTokenInvalid
+	}
+	if _, err := p.verifyToken(c, cookie); err != nil {
+		return err
+	}
+	return nil
+}
+
+// signed returns what the signature of a token covers: its payload and the
+// caller's session. The payload has no "|", so the two can't be mixed up.
+func (p *CSRFProtection) signed(c *gin.Context, payload string) []byte {
+	if p.config.SessionID == nil {
+		return []byte(payload)
+	}
+	return []byte(payload + "|" + p.config.SessionID(c))
+}
+
+// verifyToken checks the signature and expiry of a token for the caller,
+// returning when it expires
+func (p *CSRFProtection) verifyToken(c *gin.Context, token string) (time.Time, error) {
+	payload, signature, ok := cutLast(token, ".")
+	if !ok {
+		return time.Time{}, ErrCSRFTokenInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return time.Time{}, ErrCSRFTokenInvalid
+	}
+	if err := crypt.VerifyMAC(p.config.Key, p.signed(c, payload), mac); err != nil {
+		return time.Time{}, ErrCSRFTokenInvalid
+	}
+
+	_, expires, ok := cutLast(payload, ".")
+	if !ok {
+		return time.Time{}, ErrCSRFTokenInvalid
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrCSRFTokenInvalid
+	}
+	expiresAt := time.Unix(unix, 0)
+	if time.Now().After(expiresAt) {
+		return time.Time{}, ErrCSRFTokenInvalid
+	}
+	return expiresAt, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// isSafeMethod reports whether a method is exempt from CSRF checks
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// Middleware returns the gin.HandlerFunc issuing tokens on safe requests and
+// verifying them on the others
+func (p *CSRFProtection) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSafeMethod(c.Request.Method) {
+			token, err := p.Token(c)
+			if err != nil {
+				respondError(c, http.StatusInternalServerError, "ERR_INTERNAL", "CSRF token could not be issued")
+				c.Abort()
+				return
+			}
+			c.Header(p.config.HeaderName, token.Token)
+			c.Next()
+			return
+		}
+
+		if err := p.Verify(c); err != nil {
+			message := "CSRF token is invalid or expired"
+			if errors.Is(err, ErrCSRFTokenMissing) {
+				message = "CSRF token is missing, send it in the " + p.config.HeaderName + " header"
+			}
+			respondError(c, http.StatusForbidden, "ERR_CSRF_INVALID", message)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// TokenHandler returns a handler for an endpoint handing out the token, for
+// clients that can't read the response header of a safe request
+func (p *CSRFProtection) TokenHandler() TypedHandlerFunc {
+	return ValidateAndHandle(func(c *gin.Context, _ struct{}) (*CSRFToken, error) {
+		token, err := p.Token(c)
+		if err != nil {
+			return nil, err
+		}
+		c.Header(p.config.HeaderName, token.Token)
+		return token, nil
+	})
+}
+
+// RegisterCSRFProtection records that a route is CSRF protected
+func (r *Registry) RegisterCSRFProtection(method, path string, protection *CSRFProtection) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.csrf[routeKey(method, path)] = protection
+}
+
+// GetCSRFProtection retrieves the CSRF protection of a route
+func (r *Registry) GetCSRFProtection(method, path string) (*CSRFProtection, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	protection, exists := r.csrf[routeKey(method, path)]
+	return protection, exists
+}
+
+// RegisterCSRFProtection records that a route of the global registry is CSRF protected
+func RegisterCSRFProtection(method, path string, protection *CSRFProtection) {
+	defaultRegistry.RegisterCSRFProtection(method, path, protection)
+}
+
+// GetCSRFProtection retrieves the CSRF protection of a route from the global registry
+func GetCSRFProtection(method, path string) (*CSRFProtection, bool) {
+	return defaultRegistry.GetCSRFProtection(method, path)
+}
+
+// addCSRFHeader documents the token header and the 403 response of unsafe
+// requests to a protected route
+func addCSRFHeader(operation *Operation, method, header string, schemas map[string]*JSONSchema) {
+	if isSafeMethod(method) {
+		return
+	}
+
+	operation.Parameters = append(operation.Parameters, Parameter{
+		Name:        header,
+		In:          "header",
+		Description: "CSRF token issued in the " + header + " response header of safe requests",
+		Required:    true,
+		Schema:      newJSONSchema("string", nil),
+	})
+
+	forbidden := generateErrorResponse(schemas)
+	forbidden.Description = "CSRF token is missing or invalid"
+	operation.Responses["403"] = forbidden
+}
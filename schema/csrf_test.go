@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// csrfServer serves GET and POST /transfers behind csrf, with the session
+// taken from the X-Session header
+func csrfServer(config CSRFConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	config.Key = []byte("0123456789abcdef0123456789abcdef")
+	config.SessionID = func(c *gin.Context) string { return c.GetHeader("X-Session") }
+	csrf := NewCSRFProtection(config)
+
+	engine := gin.New()
+	engine.Use(csrf.Middleware())
+	engine.GET("/transfers", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.POST("/transfers", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return engine
+}
+
+// issueCSRFToken sends a safe request for session, returning the token cookie
+func issueCSRFToken(t *testing.T, engine *gin.Engine, session string) *http.Cookie {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/transfers", nil)
+	req.Header.Set("X-Session", session)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "csrf_token" {
+		t.Fatalf("expected a csrf_token cookie, got %v", cookies)
+	}
+	if !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Errorf("expected an HttpOnly and Secure cookie")
+	}
+	if header := w.Header().Get("X-CSRF-Token"); header != cookies[0].Value {
+		t.Errorf("expected the token in the response header, got %q", header)
+	}
+	return cookies[0]
+}
+
+func postTransfer(engine *gin.Engine, session string, cookie *http.Cookie, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/transfers", nil)
+	req.Header.Set("X-Session", session)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	if token != "" {
+		req.Header.Set("X-CSRF-Token", token)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func expectCSRFRejected(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+	var body ErrorResult
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.ErrorInfo.Code != "ERR_CSRF_INVALID" {
+		t.Errorf("expected ERR_CSRF_INVALID, got %s", w.Body.String())
+	}
+}
+
+func TestCSRFProtection(t *testing.T) {
+	t.Run("issues a token that verifies", func(t *testing.T) {
+		engine := csrfServer(CSRFConfig{})
+		cookie := issueCSRFToken(t, engine, "alice")
+
+		if w := postTransfer(engine, "alice", cookie, cookie.Value); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("accepts the token in a form field", func(t *testing.T) {
+		engine := csrfServer(CSRFConfig{})
+		cookie := issueCSRFToken(t, engine, "alice")
+
+		form := url.Values{"csrf_token": {cookie.Value}}
+		req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Session", "alice")
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects missing and mismatched tokens", func(t *testing.T) {
+		engine := csrfServer(CSRFConfig{})
+		cookie := issueCSRFToken(t, engine, "alice")
+		other := issueCSRFToken(t, engine, "alice")
+
+		expectCSRFRejected(t, postTransfer(engine, "alice", nil, cookie.Value))
+		expectCSRFRejected(t, postTransfer(engine, "alice", cookie, ""))
+		expectCSRFRejected(t, postTransfer(engine, "alice", cookie, other.Value))
+	})
+
+	t.Run("rejects forged tokens", func(t *testing.T) {
+		engine := csrfServer(CSRFConfig{})
+		cookie := issueCSRFToken(t, engine, "alice")
+
+		forged := cookie.Value[:strings.LastIndex(cookie.Value, ".")] + ".c2lnbmF0dXJl"
+		expectCSRFRejected(t, postTransfer(engine, "alice", &http.Cookie{Name: "csrf_token", Value: forged}, forged))
+	})
+
+	t.Run("rejects tokens issued to another session", func(t *testing.T) {
+		engine := csrfServer(CSRFConfig{})
+		planted := issueCSRFToken(t, engine, "mallory")
+
+		expectCSRFRejected(t, postTransfer(engine, "alice", planted, planted.Value))
+	})
+
+	t.Run("reissues tokens of another session on safe requests", func(t *testing.T) {
+		engine := csrfServer(CSRFConfig{})
+		anonymous := issueCSRFToken(t, engine, "")
+
+		req := httptest.NewRequest(http.MethodGet, "/transfers", nil)
+		req.Header.Set("X-Session", "alice")
+		req.AddCookie(anonymous)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Value == anonymous.Value {
+			t.Fatalf("expected a new token after login, got %v", cookies)
+		}
+		if w := postTransfer(engine, "alice", cookies[0], cookies[0].Value); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 with the new token, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugConfig configures the debug endpoints registered by RegisterDebugRoutes
+type DebugConfig struct {
+	Enabled    bool              // Register the routes, also enabled by SCHEMA_DEBUG=true
+	Prefix     string            // Route prefix (default "/_debug/schema")
+	Spec       *OpenAPIOpts      // Options for the live spec compared by /spec-diff
+	Middleware []gin.HandlerFunc // Guards for the debug routes, e.g. an admin security middleware
+}
+
+// debugEnabled reports whether debug routes are switched on by the environment
+func debugEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("SCHEMA_DEBUG"))
+	return enabled
+}
+
+// RegisterDebugRoutes adds read-only endpoints for debugging documentation in
+// a running service when config.Enabled is set or SCHEMA_DEBUG=true:
+//
+//	GET {prefix}/routes     gin routes with their typed handler, security and options
+//	GET {prefix}/security   the security registry
+//	GET {prefix}/spec-diff  operations and schemas changed since the spec was last served
+//
+// The routes are not documented in the spec. It reports whether they were registered.
+func (r *RouterHelper) RegisterDebugRoutes(config DebugConfig) bool {
+	if !config.Enabled && !debugEnabled() {
+		return false
+	}
+	if config.Prefix == "" {
+		config.Prefix = "/_debug/schema"
+	}
+	spec := OpenAPIOpts{}
+	if config.Spec != nil {
+		spec = *config.Spec
+	}
+	if spec.Registry == nil {
+		spec.Registry = r.Registry()
+	}
+
+	group := r.Engine.Group(config.Prefix, config.Middleware...)
+	group.GET("/routes", func(c *gin.Context) {
+		c.JSON(200, debugRoutes(r.Registry(), r.Engine.Routes(), config.Prefix))
+	})
+	group.GET("/security", func(c *gin.Context) {
+		c.JSON(200, debugSecurity(r.Registry()))
+	})
+	group.GET("/spec-diff", func(c *gin.Context) {
+		c.JSON(200, debugSpecDiff(generateOpenAPISpec(r.Engine.Routes(), &spec)))
+	})
+	return true
+}
+
+// DebugRoute describes one gin route and what the registries know about it
+type DebugRoute struct {
+	Method           string   `json:"method"`
+	Path             string   `json:"path"`
+	Handler          string   `json:"handler"`
+	Documented       bool     `json:"documented"`
+	SchemaType       string   `json:"schemaType,omitempty"`
+	ResponseType     string   `json:"responseType,omitempty"`
+	Security         []string `json:"security,omitempty"`
+	Public           bool     `json:"public,omitempty"`
+	ConcurrencyLimit int      `json:"concurrencyLimit,omitempty"`
+	CacheControl     string   `json:"cacheControl,omitempty"`
+	ContentTypes     []string `json:"contentTypes,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	Issue            string   `json:"issue,omitempty"`
+}
+
+// DebugRoutes is the response of the /routes debug endpoint
+type DebugRoutes struct {
+	Routes   []DebugRoute `json:"routes"`
+	Unrouted []string     `json:"unrouted"` // Typed handlers registered for a method and path gin does not serve
+}
+
+func debugRoutes(registry *Registry, routes gin.RoutesInfo, debugPrefix string) DebugRoutes {
+	result := DebugRoutes{Routes: []DebugRoute{}, Unrouted: []string{}}
+	routed := make(map[string]bool)
+
+	for _, route := range routes {
+		routed[routeKey(route.Method, route.Path)] = true
+
+		entry := DebugRoute{
+			Method:   route.Method,
+			Path:     route.Path,
+			Handler:  route.Handler,
+			Security: schemeNames(registry.GetSecuritySchemes(route.Method, route.Path)),
+			Public:   registry.IsPublicRoute(route.Method, route.Path),
+			Tags:     registry.GetRouteTags(route.Method, route.Path),
+		}
+		if limit, exists := registry.GetConcurrencyLimit(route.Method, route.Path); exists {
+			entry.ConcurrencyLimit = cap(limit.slots)
+		}
+		if cacheControl, exists := registry.GetCacheControl(route.Method, route.Path); exists {
+			entry.CacheControl = cacheControl.value
+		}
+		if contentTypes, exists := registry.GetContentTypes(route.Method, route.Path); exists {
+			entry.ContentTypes = contentTypes.types
+		}
+
+		if handler, exists := registry.GetTypedHandler(route.Method, route.Path); exists {
+			entry.Documented = true
+			if handler.GetSchemaType() != nil {
+				entry.SchemaType = handler.GetSchemaType().String()
+			}
+			if handler.GetResponseType() != nil {
+				entry.ResponseType = handler.GetResponseType().String()
+			}
+		} else if !strings.HasPrefix(route.Path, debugPrefix+"/") {
+			entry.Issue = "no typed handler is registered for this method and path; register the route through RouterHelper or RouterGroup, or call RegisterTypedHandler"
+		}
+
+		result.Routes = append(result.Routes, entry)
+	}
+
+	for _, key := range registry.keys() {
+		if !routed[key] {
+			result.Unrouted = append(result.Unrouted, key)
+		}
+	}
+
+	return result
+}
+
+func schemeNames(schemes []SecurityScheme) []string {
+	var names []string
+	for _, scheme := range schemes {
+		name, _ := scheme.GetSecurityScheme()
+		names = append(names, name)
+	}
+	return names
+}
+
+func debugSecurity(registry *Registry) map[string][]string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	result := make(map[string][]string, len(registry.securitySchemes))
+	for key, schemes := range registry.securitySchemes {
+		result[key] = schemeNames(schemes)
+	}
+	return result
+}
+
+// servedSpec is the spec last written by HandleGetSwagger
+type servedSpec struct {
+	spec *OpenAPISpec
+	at   time.Time
+}
+
+var lastServedSpec atomic.Pointer[servedSpec]
+
+// recordServedSpec remembers the spec for the /spec-diff debug endpoint
+func recordServedSpec(spec *OpenAPISpec) {
+	lastServedSpec.Store(&servedSpec{spec: spec, at: time.Now()})
+}
+
+// DebugSpecDiff is the response of the /spec-diff debug endpoint
+type DebugSpecDiff struct {
+	ServedAt *time.Time `json:"servedAt"` // Nil when no spec has been served yet
+	Added    []string   `json:"added"`
+	Removed  []string   `json:"removed"`
+	Changed  []string   `json:"changed"`
+}
+
+// debugSpecDiff compares the live spec with the one last served, listing
+// operations as "GET /users/{id}" and component schemas as "schema User"
+func debugSpecDiff(live *OpenAPISpec) DebugSpecDiff {
+	diff := DebugSpecDiff{Added: []string{}, Removed: []string{}, Changed: []string{}}
+
+	previous := make(map[string]string)
+	if served := lastServedSpec.Load(); served != nil {
+		diff.ServedAt = &served.at
+		previous = specEntries(served.spec)
+	}
+	current := specEntries(live)
+
+	for key, value := range current {
+		old, exists := previous[key]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, key)
+		case old != value:
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for key := range previous {
+		if _, exists := current[key]; !exists {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// specEntries flattens a spec into comparable JSON per operation and schema
+func specEntries(spec *OpenAPISpec) map[string]string {
+	entries := make(map[string]string)
+	for _, entry := range spec.operations() {
+		data, _ := json.Marshal(entry.Operation)
+		entries[strings.ToUpper(entry.Method)+" "+entry.Path] = string(data)
+	}
+	if spec.Components != nil {
+		for name, schema := range spec.Components.Schemas {
+			data, _ := json.Marshal(schema)
+			entries["schema "+name] = string(data)
+		}
+	}
+	return entries
+}
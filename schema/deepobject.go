@@ -0,0 +1,185 @@
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isDeepObjectType reports whether t binds from deepObject style query keys such as filter[status]=active
+func isDeepObjectType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+	return t.Kind() == reflect.Struct || (t.Kind() == reflect.Map && t.Key().Kind() == reflect.String)
+}
+
+// isQueryArrayType reports whether t binds from repeated query keys such as tag=a&tag=b
+func isQueryArrayType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// parseQueryCollection binds an array or deepObject query parameter
+func parseQueryCollection(c *gin.Context, structField reflect.Value, typeField reflect.StructField) error {
+	values := c.Request.URL.Query()
+	name := collectionQueryName(values, typeField)
+
+	var found bool
+	var err error
+	if isQueryArrayType(typeField.Type) {
+		found, err = setQueryArray(structField, queryArrayValues(values, name))
+	} else {
+		found, err = parseDeepObject(values, name, structField)
+	}
+
+	if err != nil {
+		if isSensitiveField(typeField) {
+			return fmt.Errorf("invalid query param '%s'", name)
+		}
+		return fmt.Errorf("invalid query param '%s': %w", name, err)
+	}
+
+	if !found && isRequired(typeField) {
+		return fmt.Errorf("required query param '%s' is missing", name)
+	}
+	return nil
+}
+
+// collectionQueryName picks the name a collection was sent under, trying the
+// same variants as scalar query fields before falling back to the documented name
+func collectionQueryName(values url.Values, typeField reflect.StructField) string {
+	candidates := []string{
+		getTagValue(typeField, "query"),
+		typeField.Name,
+		strings.ToLower(typeField.Name),
+		getJSONFieldName(typeField),
+	}
+	for _, candidate := range candidates {
+		if candidate == "" || candidate == "-" {
+			continue
+		}
+		for key := range values {
+			if key == candidate || strings.HasPrefix(key, candidate+"[") {
+				return candidate
+			}
+		}
+	}
+	return getQueryParameterName(typeField)
+}
+
+// queryArrayValues accepts both name=a&name=b and name[]=a&name[]=b
+func queryArrayValues(values url.Values, name string) []string {
+	if list := values[name]; len(list) > 0 {
+		return list
+	}
+	return values[name+"[]"]
+}
+
+func setQueryArray(field reflect.Value, list []string) (bool, error) {
+	if len(list) == 0 {
+		return false, nil
+	}
+
+	slice := reflect.MakeSlice(field.Type(), len(list), len(list))
+	for i, value := range list {
+		if err := setFieldValue(slice.Index(i), value); err != nil {
+			return false, err
+		}
+	}
+	field.Set(slice)
+	return true, nil
+}
+
+// parseDeepObject binds prefix[key] query values into a struct or map field,
+// recursing for nested keys such as filter[date][from]
+func parseDeepObject(values url.Values, prefix string, field reflect.Value) (bool, error) {
+	switch field.Kind() {
+	case reflect.Ptr:
+		target := reflect.New(field.Type().Elem())
+		found, err := parseDeepObject(values, prefix, target.Elem())
+		if found && err == nil {
+			field.Set(target)
+		}
+		return found, err
+
+	case reflect.Map:
+		found := false
+		for key, list := range values {
+			inner, ok := deepObjectKey(key, prefix)
+			if !ok || len(list) == 0 {
+				continue
+			}
+
+			element := reflect.New(field.Type().Elem()).Elem()
+			if err := setFieldValue(element, list[0]); err != nil {
+				return false, fmt.Errorf("%s: %w", key, err)
+			}
+			if field.IsNil() {
+				field.Set(reflect.MakeMap(field.Type()))
+			}
+			field.SetMapIndex(reflect.ValueOf(inner).Convert(field.Type().Key()), element)
+			found = true
+		}
+		return found, nil
+
+	case reflect.Struct:
+		found := false
+		fieldType := field.Type()
+		for i := 0; i < field.NumField(); i++ {
+			typeField := fieldType.Field(i)
+			structField := field.Field(i)
+			if !typeField.IsExported() || !structField.CanSet() {
+				continue
+			}
+
+			key := prefix + "[" + getQueryParameterName(typeField) + "]"
+
+			var fieldFound bool
+			var err error
+			switch {
+			case isDeepObjectType(typeField.Type):
+				fieldFound, err = parseDeepObject(values, key, structField)
+				if err != nil {
+					return false, err
+				}
+			case isQueryArrayType(typeField.Type):
+				fieldFound, err = setQueryArray(structField, queryArrayValues(values, key))
+			default:
+				value, exists := values[key]
+				if exists && len(value) > 0 {
+					err = setFieldValue(structField, value[0])
+					fieldFound = true
+				} else if defaultVal := getTagValue(typeField, "default"); defaultVal != "" {
+					err = setFieldValue(structField, defaultVal)
+				}
+			}
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", key, err)
+			}
+			found = found || fieldFound
+		}
+		return found, nil
+	}
+
+	return false, fmt.Errorf("unsupported field type: %s", field.Kind())
+}
+
+// deepObjectKey returns the inner key of prefix[inner], rejecting nested keys
+func deepObjectKey(key, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, prefix+"[")
+	if !ok || !strings.HasSuffix(rest, "]") {
+		return "", false
+	}
+	inner := strings.TrimSuffix(rest, "]")
+	if strings.ContainsAny(inner, "[]") {
+		return "", false
+	}
+	return inner, true
+}
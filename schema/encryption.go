@@ -0,0 +1,249 @@
+package schema
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// FieldCipher encrypts and decrypts fields tagged with `encrypt:"true"`.
+// *crypt.Crypt and *crypt.Envelope from github.com/fxfn/x/crypt both implement it.
+type FieldCipher interface {
+	Encrypt(data []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
+}
+
+var ErrFieldCipherNotConfigured = errors.New("field encryption is not configured, use schema.SetFieldCipher()")
+
+// Global field cipher configuration
+var globalFieldCipher FieldCipher
+
+// SetFieldCipher sets the cipher used for `encrypt:"true"` fields.
+// Incoming body fields are expected as base64 ciphertext and are decrypted
+// before validation, outgoing response fields are encrypted and base64 encoded.
+func SetFieldCipher(cipher FieldCipher) {
+	globalFieldCipher = cipher
+}
+
+// GetFieldCipher returns the current field cipher
+func GetFieldCipher() FieldCipher {
+	return globalFieldCipher
+}
+
+func isEncryptedField(field reflect.StructField) bool {
+	return field.Tag.Get("encrypt") == "true"
+}
+
+// hasEncryptedFields reports whether t contains any `encrypt:"true"` fields
+func hasEncryptedFields(t reflect.Type) bool {
+	return hasEncryptedFieldsSeen(t, map[reflect.Type]bool{})
+}
+
+func hasEncryptedFieldsSeen(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if isEncryptedField(field) || hasEncryptedFieldsSeen(field.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// decryptFields decrypts `encrypt:"true"` string fields of a bound body in place
+func decryptFields(v reflect.Value) error {
+	if !hasEncryptedFields(v.Type()) {
+		return nil
+	}
+
+	if globalFieldCipher == nil {
+		return ErrFieldCipherNotConfigured
+	}
+
+	return transformFields(v, false, "", decryptString)
+}
+
+// decryptBodyField decrypts a top-level body field bound by parseBodyFields,
+// which is encrypted itself or contains encrypted fields
+func decryptBodyField(v reflect.Value, field reflect.StructField) error {
+	if !isEncryptedField(field) && !hasEncryptedFields(field.Type) {
+		return nil
+	}
+
+	if globalFieldCipher == nil {
+		return ErrFieldCipherNotConfigured
+	}
+
+	return transformFields(v, isEncryptedField(field), getJSONFieldName(field), decryptString)
+}
+
+// decryptString decrypts a base64 ciphertext with the field cipher
+func decryptString(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := globalFieldCipher.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// encryptFields returns a copy of data with `encrypt:"true"` string fields encrypted.
+// data itself is never modified.
+func encryptFields(data any) (any, error) {
+	if data == nil || !hasEncryptedFields(reflect.TypeOf(data)) {
+		return data, nil
+	}
+
+	if globalFieldCipher == nil {
+		return nil, ErrFieldCipherNotConfigured
+	}
+
+	copied := deepCopyValue(reflect.ValueOf(data))
+	err := transformFields(copied, false, "", func(value string) (string, error) {
+		if value == "" {
+			return "", nil
+		}
+
+		ciphertext, err := globalFieldCipher.Encrypt([]byte(value))
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString(ciphertext), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copied.Interface(), nil
+}
+
+// transformFields walks v and applies fn to every string reachable through an encrypted field
+func transformFields(v reflect.Value, encrypted bool, name string, fn func(string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return transformFields(v.Elem(), encrypted, name, fn)
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return nil
+		}
+		// The dynamic value can't be set in place, transform a copy
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := transformFields(elem, encrypted, name, fn); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		if !encrypted || !v.CanSet() {
+			return nil
+		}
+		value, err := fn(v.String())
+		if err != nil {
+			return fmt.Errorf("invalid encrypted field '%s': %w", name, err)
+		}
+		v.SetString(value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := transformFields(v.Index(i), encrypted, name, fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values aren't addressable, transform copies and store them back
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+			if err := transformFields(value, encrypted, name, fn); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := transformFields(v.Field(i), isEncryptedField(field), getJSONFieldName(field), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deepCopyValue returns an addressable copy of v that shares no pointers, slices or maps with it
+func deepCopyValue(v reflect.Value) reflect.Value {
+	copied := reflect.New(v.Type()).Elem()
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			inner := deepCopyValue(v.Elem())
+			ptr := reflect.New(v.Type().Elem())
+			ptr.Elem().Set(inner)
+			copied.Set(ptr)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			copied.Set(deepCopyValue(v.Elem()))
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			slice := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				slice.Index(i).Set(deepCopyValue(v.Index(i)))
+			}
+			copied.Set(slice)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			m := reflect.MakeMapWithSize(v.Type(), v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				m.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+			}
+			copied.Set(m)
+		}
+	case reflect.Struct:
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(deepCopyValue(v.Field(i)))
+			}
+		}
+	default:
+		copied.Set(v)
+	}
+
+	return copied
+}
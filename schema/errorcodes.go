@@ -0,0 +1,191 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ErrorCode describes an error code clients may receive
+type ErrorCode struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status,omitempty"` // Usual response status, zero when it varies
+	Description string `json:"description,omitempty"`
+}
+
+// errorCodeSchemaName is the component schema enumerating the error codes
+const errorCodeSchemaName = "ErrorCode"
+
+var (
+	errorCodeRegistryMu sync.RWMutex
+	errorCodeRegistry   = make(map[string]ErrorCode)
+)
+
+// RegisterErrorCodes adds the application's error codes to the catalog
+// exported by WriteErrorCodes and documented with OpenAPIOpts.ErrorCodes:
+//
+//	schema.RegisterErrorCodes(
+//		schema.ErrorCode{Code: "ERR_EMAIL_TAKEN", Status: 409, Description: "Email already registered"},
+//		schema.ErrorCode{Code: "ERR_ORDER_LOOKUP", Status: 500, Description: "Order could not be loaded"},
+//	)
+//
+// Registering a code again, including a framework code, replaces it.
+func RegisterErrorCodes(codes ...ErrorCode) {
+	errorCodeRegistryMu.Lock()
+	defer errorCodeRegistryMu.Unlock()
+	for _, code := range codes {
+		errorCodeRegistry[code.Code] = code
+	}
+}
+
+// frameworkErrorCodes are the codes the package itself responds with
+func frameworkErrorCodes() []ErrorCode {
+	return []ErrorCode{
+		{Code: "ERR_INVALID_PARAMS", Status: http.StatusBadRequest, Description: "Path parameters could not be parsed or validated"},
+		{Code: "ERR_INVALID_QUERY", Status: http.StatusBadRequest, Description: "Query parameters could not be parsed or validated"},
+		{Code: "ERR_INVALID_HEADERS", Status: http.StatusBadRequest, Description: "Request headers could not be parsed or validated"},
+		{Code: "ERR_INVALID_COOKIES", Status: http.StatusBadRequest, Description: "Request cookies could not be parsed or validated"},
+		{Code: "ERR_INVALID_BODY", Status: http.StatusBadRequest, Description: "Request body could not be read, parsed or validated"},
+		{Code: "ERR_INVALID_JSON", Status: http.StatusBadRequest, Description: "Request body contains invalid JSON"},
+		{Code: "ERR_MISSING_REQUIRED", Status: http.StatusBadRequest, Description: "A required value is missing"},
+		{Code: "ERR_VALIDATION_FAILED", Status: validationStatus, Description: "Request failed validation"},
+		{Code: "ERR_NOT_SPECIFIED", Status: http.StatusBadRequest, Description: "Handler failed without an error code"},
+		{Code: "ERR_INTERNAL", Status: http.StatusInternalServerError, Description: "Internal server error"},
+		{Code: "ERR_NOT_FOUND", Status: http.StatusNotFound, Description: "Route not found"},
+		{Code: "ERR_METHOD_NOT_ALLOWED", Status: http.StatusMethodNotAllowed, Description: "Method not allowed on the route"},
+		{Code: "ERR_BODY_TOO_LARGE", Status: http.StatusRequestEntityTooLarge, Description: "Request body exceeds the size limit"},
+		{Code: "ERR_UNSUPPORTED_MEDIA_TYPE", Status: http.StatusUnsupportedMediaType, Description: "Request body media type is not accepted"},
+		{Code: "ERR_CSRF_INVALID", Status: http.StatusForbidden, Description: "CSRF token is missing, invalid or expired"},
+		{Code: "ERR_FORBIDDEN", Status: http.StatusForbidden, Description: "Token lacks a required scope"},
+		{Code: "UNAUTHORIZED", Status: http.StatusUnauthorized, Description: "Authentication is missing or invalid"},
+		{Code: "INVALID_REQUEST", Status: http.StatusBadRequest, Description: "Signed request could not be verified"},
+		{Code: "INTERNAL_ERROR", Status: http.StatusInternalServerError, Description: "Security scheme is misconfigured"},
+		{Code: "TOO_MANY_REQUESTS", Status: http.StatusTooManyRequests, Description: "Too many failed authentication attempts"},
+		{Code: "SERVICE_UNAVAILABLE", Status: http.StatusServiceUnavailable, Description: "Too many concurrent requests"},
+	}
+}
+
+// ErrorCodes returns the framework's error codes and the registered ones,
+// sorted by code
+func ErrorCodes() []ErrorCode {
+	catalog := make(map[string]ErrorCode)
+	for _, code := range frameworkErrorCodes() {
+		catalog[code.Code] = code
+	}
+
+	errorCodeRegistryMu.RLock()
+	for name, code := range errorCodeRegistry {
+		catalog[name] = code
+	}
+	errorCodeRegistryMu.RUnlock()
+
+	codes := make([]ErrorCode, 0, len(catalog))
+	for _, name := range sortedKeys(catalog) {
+		codes = append(codes, catalog[name])
+	}
+	return codes
+}
+
+// WriteErrorCodes writes the error code catalog for client SDKs: as a
+// TypeScript enum when the file name ends in ".ts", as a Go file with one
+// constant per code when it ends in ".go", and as a JSON array otherwise
+func WriteErrorCodes(filename string) error {
+	codes := ErrorCodes()
+
+	var data []byte
+	switch {
+	case strings.HasSuffix(filename, ".ts"):
+		data = errorCodesTypeScript(codes)
+	case strings.HasSuffix(filename, ".go"):
+		data = errorCodesGo(codes)
+	default:
+		var err error
+		data, err = json.MarshalIndent(codes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal error codes: %w", err)
+		}
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+func errorCodesTypeScript(codes []ErrorCode) []byte {
+	var b strings.Builder
+	b.WriteString("// Code generated by schema.WriteErrorCodes. DO NOT EDIT.\n\n")
+	b.WriteString("export enum ErrorCode {\n")
+	for _, code := range codes {
+		if code.Description != "" {
+			fmt.Fprintf(&b, "  /** %s */\n", code.Description)
+		}
+		fmt.Fprintf(&b, "  %s = %q,\n", errorCodeIdentifier(code.Code), code.Code)
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+func errorCodesGo(codes []ErrorCode) []byte {
+	var b strings.Builder
+	b.WriteString("// Code generated by schema.WriteErrorCodes. DO NOT EDIT.\n\n")
+	b.WriteString("package errorcodes\n\n")
+	b.WriteString("const (\n")
+	for _, code := range codes {
+		if code.Description != "" {
+			fmt.Fprintf(&b, "\t// %s\n", code.Description)
+		}
+		fmt.Fprintf(&b, "\t%s = %q\n", errorCodeIdentifier(code.Code), code.Code)
+	}
+	b.WriteString(")\n")
+	return []byte(b.String())
+}
+
+// errorCodeIdentifier turns a code into a PascalCase identifier:
+// ERR_NOT_FOUND gives ErrNotFound
+func errorCodeIdentifier(code string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(code, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+	}
+	identifier := b.String()
+	if identifier == "" || unicode.IsDigit(rune(identifier[0])) {
+		identifier = "Code" + identifier
+	}
+	return identifier
+}
+
+// documentErrorCodes adds the ErrorCode enum to the components and refers
+// to it from the code of every error response
+func (o *OpenAPISpec) documentErrorCodes() {
+	codes := ErrorCodes()
+	enum := make([]interface{}, len(codes))
+	descriptions := make([]string, len(codes))
+	for i, code := range codes {
+		enum[i] = code.Code
+		descriptions[i] = code.Description
+	}
+
+	codeSchema := newJSONSchema("string", nil)
+	codeSchema.Description = "Error codes the API responds with"
+	codeSchema.Enum = enum
+	codeSchema.Extensions = Extensions{"x-enum-descriptions": descriptions}
+	o.Components.Schemas[errorCodeSchemaName] = codeSchema
+
+	for _, entry := range o.operations() {
+		for _, response := range entry.Operation.Responses {
+			for _, mediaType := range response.Content {
+				if mediaType.Schema == nil || mediaType.Schema.Properties["error"] == nil {
+					continue
+				}
+				errorObject := mediaType.Schema.Properties["error"]
+				if _, exists := errorObject.Properties["code"]; exists {
+					errorObject.Properties["code"] = &JSONSchema{Ref: componentSchemaPrefix + errorCodeSchemaName}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// WrappedError is an internal error with the code and message clients see
+// instead of it, see WrapError
+type WrappedError struct {
+	Code    string
+	Message string // Public message, sent instead of the cause
+	cause   error
+	status  int
+}
+
+// WrapError hides err behind a public code and message. Handlers returning
+// it answer with a wrapped 500 error showing only code and publicMessage,
+// while err stays available to logs and middleware through c.Errors and
+// errors.Is / errors.As:
+//
+//	user, err := db.FindUser(ctx, id)
+//	if err != nil {
+//		return nil, schema.WrapError(err, "ERR_USER_LOOKUP", "Could not load the user")
+//	}
+//
+// Use SetErrorDebug to include the cause in responses outside production.
+func WrapError(err error, code, publicMessage string) WrappedError {
+	return WrappedError{Code: code, Message: publicMessage, cause: err}
+}
+
+// WithStatus sets the response status, 500 when not set
+func (e WrappedError) WithStatus(status int) WrappedError {
+	e.status = status
+	return e
+}
+
+// Status returns the response status of the error
+func (e WrappedError) Status() int {
+	if e.status == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.status
+}
+
+// Error includes the cause, so logs see what clients do not
+func (e WrappedError) Error() string {
+	if e.cause == nil {
+		return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %v", e.Code, e.Message, e.cause)
+}
+
+// Unwrap returns the hidden error
+func (e WrappedError) Unwrap() error {
+	return e.cause
+}
+
+// Global error debug configuration
+var errorDebug bool
+
+// SetErrorDebug makes error responses include the cause of WrapError errors
+// and of errors handlers return without a code. It leaks internal details,
+// so only enable it in development and test environments.
+func SetErrorDebug(enabled bool) {
+	errorDebug = enabled
+}
+
+// GetErrorDebug reports whether error responses include causes
+func GetErrorDebug() bool {
+	return errorDebug
+}
+
+// debugMessage appends the cause chain to a public message in debug mode
+func debugMessage(message string, cause error) string {
+	if !errorDebug || cause == nil {
+		return message
+	}
+	return fmt.Sprintf("%s (cause: %v)", message, cause)
+}
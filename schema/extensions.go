@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Extensions holds OpenAPI vendor extensions. Keys are normalized to start with "x-".
+type Extensions map[string]interface{}
+
+// extensionKey adds the "x-" prefix when it is missing
+func extensionKey(key string) string {
+	if strings.HasPrefix(key, "x-") {
+		return key
+	}
+	return "x-" + key
+}
+
+// normalizeExtensions returns a copy of ext with "x-" prefixed keys, or nil when empty
+func normalizeExtensions(ext map[string]interface{}) Extensions {
+	if len(ext) == 0 {
+		return nil
+	}
+
+	normalized := make(Extensions, len(ext))
+	for key, value := range ext {
+		normalized[extensionKey(key)] = value
+	}
+	return normalized
+}
+
+// parseExtensionTag reads `x:"key=value;other=value"` tags. Values that are
+// valid JSON (numbers, booleans, quoted strings, objects) keep their type,
+// anything else is used as a plain string.
+func parseExtensionTag(field reflect.StructField) Extensions {
+	tag := field.Tag.Get("x")
+	if tag == "" {
+		return nil
+	}
+
+	ext := make(Extensions)
+	for _, pair := range strings.Split(tag, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if key == "" {
+			continue
+		}
+
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			parsed = value
+		}
+		ext[extensionKey(key)] = parsed
+	}
+
+	if len(ext) == 0 {
+		return nil
+	}
+	return ext
+}
+
+// mergeExtensions adds src to dst, allocating dst when needed
+func mergeExtensions(dst, src Extensions) Extensions {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(Extensions, len(src))
+	}
+	for key, value := range src {
+		dst[key] = value
+	}
+	return dst
+}
+
+// appendExtensions adds extension members to an already marshaled JSON object,
+// keeping the struct fields in their declared order
+func appendExtensions(object []byte, ext Extensions) ([]byte, error) {
+	if len(ext) == 0 {
+		return object, nil
+	}
+
+	members, err := json.Marshal(map[string]interface{}(ext))
+	if err != nil {
+		return nil, err
+	}
+
+	object = bytes.TrimSuffix(object, []byte("}"))
+	members = bytes.TrimPrefix(members, []byte("{"))
+	if !bytes.HasSuffix(object, []byte("{")) {
+		object = append(object, ',')
+	}
+	return append(object, members...), nil
+}
+
+func (o OpenAPISpec) MarshalJSON() ([]byte, error) {
+	type openAPISpec OpenAPISpec
+	data, err := json.Marshal(openAPISpec(o))
+	if err != nil {
+		return nil, err
+	}
+	return appendExtensions(data, o.Extensions)
+}
+
+func (i Info) MarshalJSON() ([]byte, error) {
+	type info Info
+	data, err := json.Marshal(info(i))
+	if err != nil {
+		return nil, err
+	}
+	return appendExtensions(data, i.Extensions)
+}
+
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type operation Operation
+	data, err := json.Marshal(operation(o))
+	if err != nil {
+		return nil, err
+	}
+	return appendExtensions(data, o.Extensions)
+}
+
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	type parameter Parameter
+	data, err := json.Marshal(parameter(p))
+	if err != nil {
+		return nil, err
+	}
+	return appendExtensions(data, p.Extensions)
+}
+
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	type jsonSchema JSONSchema
+	// Type and Properties shadow the embedded fields to keep their position
+	data, err := json.Marshal(struct {
+		Type       string             `json:"type,omitempty"`
+		Properties *orderedProperties `json:"properties,omitempty"`
+		jsonSchema
+	}{s.Type, s.orderedProperties(), jsonSchema(s)})
+	if err != nil {
+		return nil, err
+	}
+	return appendExtensions(data, s.Extensions)
+}
@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NoRoute sets the handlers for requests matching no route. The chain ends
+// with NotFoundHandler, so middleware passed here runs first and the client
+// gets a 404 wrapped by the configured ResponseWrapper instead of gin's
+// plain text "404 page not found". Handlers that already wrote a response,
+// such as a SPA fallback serving index.html, take precedence.
+func (r *RouterHelper) NoRoute(middleware ...gin.HandlerFunc) {
+	r.Engine.NoRoute(append(middleware, NotFoundHandler())...)
+}
+
+// NoMethod sets the handlers for requests whose path is routed for other
+// methods only, and enables gin's HandleMethodNotAllowed. The chain ends with
+// MethodNotAllowedHandler; gin sets the Allow header.
+func (r *RouterHelper) NoMethod(middleware ...gin.HandlerFunc) {
+	r.Engine.HandleMethodNotAllowed = true
+	r.Engine.NoMethod(append(middleware, MethodNotAllowedHandler())...)
+}
+
+// NotFoundHandler responds with a wrapped 404 and code ERR_NOT_FOUND
+func NotFoundHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Writer.Written() {
+			return
+		}
+		respondError(c, http.StatusNotFound, "ERR_NOT_FOUND", "Route not found")
+	}
+}
+
+// MethodNotAllowedHandler responds with a wrapped 405 and code ERR_METHOD_NOT_ALLOWED
+func MethodNotAllowedHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Writer.Written() {
+			return
+		}
+		respondError(c, http.StatusMethodNotAllowed, "ERR_METHOD_NOT_ALLOWED", "Method not allowed")
+	}
+}
+
+// addFallbackResponses documents the responses of NoRoute and NoMethod
+func addFallbackResponses(operation *Operation, schemas map[string]*JSONSchema) {
+	if _, exists := operation.Responses["404"]; !exists {
+		notFound := generateErrorResponse(schemas)
+		notFound.Description = "Not Found"
+		operation.Responses["404"] = notFound
+	}
+	if _, exists := operation.Responses["405"]; !exists {
+		notAllowed := generateErrorResponse(schemas)
+		notAllowed.Description = "Method Not Allowed"
+		operation.Responses["405"] = notAllowed
+	}
+}
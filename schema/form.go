@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"fmt"
+	"mime"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// formMediaType is the media type of URL-encoded form bodies
+const formMediaType = "application/x-www-form-urlencoded"
+
+// isFormRequest reports whether the request body is a URL-encoded form
+func isFormRequest(c *gin.Context) bool {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	return err == nil && mediaType == formMediaType
+}
+
+// formName returns the form key a Body field is bound from: its `form` tag,
+// or the field name
+func formName(field reflect.StructField) string {
+	if name := getTagValue(field, "form"); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// hasFormTags reports whether a Body struct declares `form` tags, which
+// documents it as accepting URL-encoded forms
+func hasFormTags(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("form"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFormBody binds a URL-encoded form body into bodyPtr by the fields'
+// `form` tags. Fields missing from the form get their `default` tag.
+func parseFormBody(c *gin.Context, bodyPtr reflect.Value) error {
+	if err := c.ShouldBindWith(bodyPtr.Interface(), binding.FormPost); err != nil {
+		return fmt.Errorf("invalid form body: %w", err)
+	}
+
+	body := bodyPtr.Elem()
+	if body.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < body.NumField(); i++ {
+		typeField := body.Type().Field(i)
+		if !body.Field(i).CanSet() || !body.Field(i).IsZero() {
+			continue
+		}
+		if _, sent := c.Request.PostForm[formName(typeField)]; sent {
+			continue
+		}
+		if defaultVal := getTagValue(typeField, "default"); defaultVal != "" {
+			if err := setFieldValue(body.Field(i), defaultVal); err != nil {
+				return fmt.Errorf("invalid default for '%s': %w", formName(typeField), err)
+			}
+		}
+	}
+	return nil
+}
+
+// formBodySchema documents a Body struct as a URL-encoded form, with its
+// properties named by their form keys
+func formBodySchema(t reflect.Type, schemas map[string]*JSONSchema) *JSONSchema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	properties := make(map[string]*JSONSchema)
+	var required, order []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || getTagValue(field, "form") == "-" {
+			continue
+		}
+
+		name := formName(field)
+		fieldSchema := generateJSONSchemaFromType(field.Type, schemas)
+		addValidationConstraints(fieldSchema, field)
+		fieldSchema.Extensions = mergeExtensions(fieldSchema.Extensions, parseExtensionTag(field))
+		if defaultVal := getTagValue(field, "default"); defaultVal != "" {
+			fieldSchema.Default = parseDefaultValue(defaultVal, field.Type)
+		}
+		properties[name] = fieldSchema
+		order = append(order, name)
+
+		if isRequired(field) {
+			required = append(required, name)
+		}
+	}
+
+	formSchema := newJSONSchema("object", properties)
+	formSchema.propertyOrder = order
+	if len(required) > 0 {
+		formSchema.Required = required
+	}
+	return formSchema
+}
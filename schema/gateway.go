@@ -0,0 +1,212 @@
+package schema
+
+import (
+	"strings"
+)
+
+// ExportProfile decorates a generated spec for a specific deployment target,
+// typically an API gateway that reads x- extensions
+type ExportProfile interface {
+	Apply(spec *OpenAPISpec)
+}
+
+// ApplyProfile decorates the spec in place. Generate a separate spec for the
+// gateway definition if the public documentation should stay undecorated.
+func (o *OpenAPISpec) ApplyProfile(profiles ...ExportProfile) *OpenAPISpec {
+	for _, profile := range profiles {
+		profile.Apply(o)
+	}
+	return o
+}
+
+// operationKey is the key used for per-route profile config, e.g. "GET /users/{id}"
+func operationKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// AWSIntegration is the x-amazon-apigateway-integration of one operation
+type AWSIntegration struct {
+	Type                string            // "http_proxy" (default), "aws_proxy", "http", "mock"
+	URI                 string            // Backend URI, defaults to BaseURL + path for http_proxy
+	HTTPMethod          string            // Backend method, defaults to the operation method (POST for aws_proxy)
+	ConnectionType      string            // "INTERNET" or "VPC_LINK"
+	ConnectionID        string            // VPC link id when ConnectionType is VPC_LINK
+	TimeoutMillis       int               // Integration timeout, 0 for the gateway default
+	PassthroughBehavior string            // Defaults to "when_no_match"
+	RequestParameters   map[string]string // Extra parameter mappings, path parameters are mapped automatically
+}
+
+// AWSAPIGatewayProfile adds x-amazon-apigateway-integration to every operation
+type AWSAPIGatewayProfile struct {
+	BaseURL  string                    // Backend base URL for http_proxy integrations
+	Defaults AWSIntegration            // Applied to every operation
+	Routes   map[string]AWSIntegration // Per-route overrides keyed by "METHOD /openapi/{path}"
+	Disabled map[string]bool           // Routes to leave without an integration
+}
+
+func (p AWSAPIGatewayProfile) Apply(spec *OpenAPISpec) {
+	for _, entry := range spec.operations() {
+		key := operationKey(entry.Method, entry.Path)
+		if p.Disabled[key] {
+			continue
+		}
+
+		integration := p.Defaults
+		if override, ok := p.Routes[key]; ok {
+			integration = mergeAWSIntegration(integration, override)
+		}
+
+		entry.Operation.Extensions = mergeExtensions(entry.Operation.Extensions, Extensions{
+			"x-amazon-apigateway-integration": p.integrationSpec(entry, integration),
+		})
+	}
+}
+
+func (p AWSAPIGatewayProfile) integrationSpec(entry specOperation, integration AWSIntegration) map[string]interface{} {
+	if integration.Type == "" {
+		integration.Type = "http_proxy"
+	}
+	if integration.HTTPMethod == "" {
+		integration.HTTPMethod = strings.ToUpper(entry.Method)
+		if integration.Type == "aws_proxy" {
+			integration.HTTPMethod = "POST" // Lambda proxy integrations are always invoked with POST
+		}
+	}
+	if integration.URI == "" && integration.Type != "mock" {
+		integration.URI = strings.TrimSuffix(p.BaseURL, "/") + entry.Path
+	}
+	if integration.PassthroughBehavior == "" {
+		integration.PassthroughBehavior = "when_no_match"
+	}
+
+	spec := map[string]interface{}{
+		"type":                integration.Type,
+		"httpMethod":          integration.HTTPMethod,
+		"passthroughBehavior": integration.PassthroughBehavior,
+	}
+	if integration.URI != "" {
+		spec["uri"] = integration.URI
+	}
+	if integration.ConnectionType != "" {
+		spec["connectionType"] = integration.ConnectionType
+	}
+	if integration.ConnectionID != "" {
+		spec["connectionId"] = integration.ConnectionID
+	}
+	if integration.TimeoutMillis > 0 {
+		spec["timeoutInMillis"] = integration.TimeoutMillis
+	}
+
+	// Forward path parameters to the backend
+	parameters := make(map[string]string)
+	for _, parameter := range entry.Operation.Parameters {
+		if parameter.In == "path" {
+			parameters["integration.request.path."+parameter.Name] = "method.request.path." + parameter.Name
+		}
+	}
+	for name, value := range integration.RequestParameters {
+		parameters[name] = value
+	}
+	if len(parameters) > 0 {
+		spec["requestParameters"] = parameters
+	}
+
+	return spec
+}
+
+func mergeAWSIntegration(base, override AWSIntegration) AWSIntegration {
+	if override.Type != "" {
+		base.Type = override.Type
+	}
+	if override.URI != "" {
+		base.URI = override.URI
+	}
+	if override.HTTPMethod != "" {
+		base.HTTPMethod = override.HTTPMethod
+	}
+	if override.ConnectionType != "" {
+		base.ConnectionType = override.ConnectionType
+	}
+	if override.ConnectionID != "" {
+		base.ConnectionID = override.ConnectionID
+	}
+	if override.TimeoutMillis > 0 {
+		base.TimeoutMillis = override.TimeoutMillis
+	}
+	if override.PassthroughBehavior != "" {
+		base.PassthroughBehavior = override.PassthroughBehavior
+	}
+	if len(override.RequestParameters) > 0 {
+		parameters := make(map[string]string)
+		for name, value := range base.RequestParameters {
+			parameters[name] = value
+		}
+		for name, value := range override.RequestParameters {
+			parameters[name] = value
+		}
+		base.RequestParameters = parameters
+	}
+	return base
+}
+
+// KongRoute is the Kong configuration of one operation
+type KongRoute struct {
+	Name    string                            // x-kong-name of the generated route
+	Route   map[string]interface{}            // Route attributes, e.g. "strip_path" or "protocols"
+	Plugins map[string]map[string]interface{} // Plugin config by plugin name, e.g. "rate-limiting"
+}
+
+// KongProfile adds the x-kong-* extensions read by Kong's OpenAPI converters
+// (deck file openapi2kong, inso) to the document and its operations
+type KongProfile struct {
+	ServiceName     string                            // x-kong-name of the service
+	UpstreamURL     string                            // Service url the routes proxy to
+	ServiceDefaults map[string]interface{}            // Service attributes, e.g. "retries"
+	RouteDefaults   map[string]interface{}            // Attributes applied to every route
+	Plugins         map[string]map[string]interface{} // Service-wide plugins by name
+	Routes          map[string]KongRoute              // Per-route config keyed by "METHOD /openapi/{path}"
+}
+
+func (p KongProfile) Apply(spec *OpenAPISpec) {
+	document := Extensions{}
+	if p.ServiceName != "" {
+		document["x-kong-name"] = p.ServiceName
+	}
+
+	serviceDefaults := make(map[string]interface{})
+	for name, value := range p.ServiceDefaults {
+		serviceDefaults[name] = value
+	}
+	if p.UpstreamURL != "" {
+		serviceDefaults["url"] = p.UpstreamURL
+	}
+	if len(serviceDefaults) > 0 {
+		document["x-kong-service-defaults"] = serviceDefaults
+	}
+	if len(p.RouteDefaults) > 0 {
+		document["x-kong-route-defaults"] = p.RouteDefaults
+	}
+	for name, config := range p.Plugins {
+		document["x-kong-plugin-"+name] = config
+	}
+	spec.Extensions = mergeExtensions(spec.Extensions, document)
+
+	for _, entry := range spec.operations() {
+		route, ok := p.Routes[operationKey(entry.Method, entry.Path)]
+		if !ok {
+			continue
+		}
+
+		operation := Extensions{}
+		if route.Name != "" {
+			operation["x-kong-name"] = route.Name
+		}
+		if len(route.Route) > 0 {
+			operation["x-kong-route-defaults"] = route.Route
+		}
+		for name, config := range route.Plugins {
+			operation["x-kong-plugin-"+name] = config
+		}
+		entry.Operation.Extensions = mergeExtensions(entry.Operation.Extensions, operation)
+	}
+}
@@ -3,9 +3,12 @@ module github.com/fxfn/x/schema
 go 1.24.4
 
 require (
+	github.com/fxfn/x/auth v0.0.0-00010101000000-000000000000
+	github.com/fxfn/x/crypt v0.0.0-00010101000000-000000000000
 	github.com/gin-gonic/gin v1.10.1
 	github.com/go-playground/validator/v10 v10.20.0
-	github.com/go-yaml/yaml v2.1.0+incompatible
+	golang.org/x/text v0.15.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -31,8 +34,10 @@ require (
 	golang.org/x/crypto v0.23.0 // indirect
 	golang.org/x/net v0.25.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
-	golang.org/x/text v0.15.0 // indirect
 	google.golang.org/protobuf v1.34.1 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/fxfn/x/auth => ../auth
+	github.com/fxfn/x/crypt => ../crypt
 )
@@ -0,0 +1,139 @@
+package schema
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// headerName returns the request header a field is read from: its `header`
+// tag, or the field name
+func headerName(field reflect.StructField) string {
+	if name := getTagValue(field, "header"); name != "" {
+		return http.CanonicalHeaderKey(name)
+	}
+	return http.CanonicalHeaderKey(field.Name)
+}
+
+// parseHeaders extracts request headers into the fields of a Headers section
+func parseHeaders(c *gin.Context, field reflect.Value) error {
+	fieldType := field.Type()
+
+	for i := 0; i < field.NumField(); i++ {
+		structField := field.Field(i)
+		typeField := fieldType.Field(i)
+
+		if !structField.CanSet() {
+			continue
+		}
+
+		if err := parseHeaderField(c, structField, typeField); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseHeaderField extracts a single request header into structField.
+// Slice fields collect every value of the header, including comma separated
+// ones.
+func parseHeaderField(c *gin.Context, structField reflect.Value, typeField reflect.StructField) error {
+	name := headerName(typeField)
+
+	var values []string
+	for _, value := range c.Request.Header.Values(name) {
+		if structField.Kind() != reflect.Slice {
+			values = append(values, value)
+			continue
+		}
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		// Check for default value
+		if defaultVal := getTagValue(typeField, "default"); defaultVal != "" {
+			values = []string{defaultVal}
+		} else if isRequired(typeField) {
+			return fmt.Errorf("required header '%s' is missing", name)
+		} else {
+			return nil
+		}
+	}
+
+	var err error
+	if structField.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(structField.Type(), len(values), len(values))
+		for i, value := range values {
+			if err = setFieldValue(slice.Index(i), value); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			structField.Set(slice)
+		}
+	} else {
+		err = setFieldValue(structField, values[0])
+	}
+
+	if err != nil {
+		// Conversion errors echo the raw value, so drop them for sensitive fields
+		if isSensitiveField(typeField) {
+			return fmt.Errorf("invalid header '%s'", name)
+		}
+		return fmt.Errorf("invalid header '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+func extractHeaderParameters(headersType reflect.Type, schemas map[string]*JSONSchema) []Parameter {
+	var parameters []Parameter
+
+	// Handle pointers
+	if headersType.Kind() == reflect.Ptr {
+		headersType = headersType.Elem()
+	}
+
+	// Ensure we have a struct type before calling NumField
+	if headersType.Kind() != reflect.Struct {
+		return parameters
+	}
+
+	for i := 0; i < headersType.NumField(); i++ {
+		parameters = append(parameters, headerParameter(headersType.Field(i), schemas))
+	}
+
+	return parameters
+}
+
+// headerParameter documents a header field, with comma separated values for slices
+func headerParameter(field reflect.StructField, schemas map[string]*JSONSchema) Parameter {
+	jsonSchema := generateJSONSchemaFromType(field.Type, schemas)
+
+	// Check if parameter has a default value
+	if defaultVal := getTagValue(field, "default"); defaultVal != "" {
+		jsonSchema.Default = parseDefaultValue(defaultVal, field.Type)
+	}
+
+	parameter := Parameter{
+		Name:       headerName(field),
+		In:         "header",
+		Required:   isRequired(field),
+		Schema:     jsonSchema,
+		Extensions: parseExtensionTag(field),
+	}
+	if field.Type.Kind() == reflect.Slice {
+		explode := false
+		parameter.Style = "simple"
+		parameter.Explode = &explode
+	}
+	return parameter
+}
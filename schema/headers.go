@@ -0,0 +1,189 @@
+package schema
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeadersResult wraps handler data with the response headers to send with it.
+// Headers is a struct whose fields name their header with a `header` tag:
+//
+//	type PageHeaders struct {
+//		TotalCount int        `header:"X-Total-Count" description:"Number of matching items"`
+//		NextCursor *string    `header:"X-Next-Cursor"`
+//		Expires    time.Time  `header:"Expires,omitempty"`
+//	}
+//
+// Nil pointers and, with omitempty, zero values are not sent. Fields that are
+// neither are documented as required headers of the success response.
+type HeadersResult[H, T any] struct {
+	Headers H
+	Data    T
+}
+
+// WithHeaders wraps data so the framework writes headers to the response
+// and documents them on the operation
+func WithHeaders[H, T any](headers H, data T) *HeadersResult[H, T] {
+	return &HeadersResult[H, T]{
+		Headers: headers,
+		Data:    data,
+	}
+}
+
+func (r HeadersResult[H, T]) responseData() interface{} {
+	return r.Data
+}
+
+func (r HeadersResult[H, T]) responseDataType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (r HeadersResult[H, T]) responseHeaders() interface{} {
+	return r.Headers
+}
+
+func (r HeadersResult[H, T]) responseHeadersType() reflect.Type {
+	return reflect.TypeOf((*H)(nil)).Elem()
+}
+
+type headerCarrier interface {
+	responseHeaders() interface{}
+	responseHeadersType() reflect.Type
+}
+
+// unwrapResponseHeaders returns the headers type of a WithHeaders response type
+func unwrapResponseHeaders(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+
+	carrier, ok := reflect.New(t).Elem().Interface().(headerCarrier)
+	if !ok {
+		return nil
+	}
+	headersType := carrier.responseHeadersType()
+	if headersType.Kind() == reflect.Ptr {
+		headersType = headersType.Elem()
+	}
+	return headersType
+}
+
+// responseHeaderField is a struct field declaring a response header
+type responseHeaderField struct {
+	name        string
+	index       int
+	omitEmpty   bool
+	description string
+}
+
+// responseHeaderFields lists the header fields of a headers struct
+func responseHeaderFields(t reflect.Type) []responseHeaderField {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []responseHeaderField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := getTagValue(field, "header")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, responseHeaderField{
+			name:        http.CanonicalHeaderKey(name),
+			index:       i,
+			omitEmpty:   hasTagOption(field, "header", "omitempty"),
+			description: field.Tag.Get("description"),
+		})
+	}
+	return fields
+}
+
+// writeResponseHeaders sets the headers of a WithHeaders response
+func writeResponseHeaders(c *gin.Context, headers interface{}) {
+	value := reflect.ValueOf(headers)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	for _, field := range responseHeaderFields(value.Type()) {
+		fieldValue := value.Field(field.index)
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		c.Header(field.name, formatHeaderValue(fieldValue))
+	}
+}
+
+// formatHeaderValue renders times as HTTP dates, slices comma-separated and
+// anything else with fmt
+func formatHeaderValue(value reflect.Value) string {
+	switch v := value.Interface().(type) {
+	case time.Time:
+		return v.UTC().Format(http.TimeFormat)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8 {
+		parts := make([]string, value.Len())
+		for i := range parts {
+			parts[i] = formatHeaderValue(value.Index(i))
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+// addResponseHeaders documents the fields of a headers struct on the success response
+func addResponseHeaders(operation *Operation, headersType reflect.Type, schemas map[string]*JSONSchema) {
+	fields := responseHeaderFields(headersType)
+	if len(fields) == 0 {
+		return
+	}
+
+	response := operation.Responses["200"]
+	if response.Headers == nil {
+		response.Headers = make(map[string]Header)
+	}
+	for _, field := range fields {
+		fieldType := headersType.Field(field.index).Type
+
+		var headerSchema *JSONSchema
+		if fieldType == timeType || fieldType == reflect.PointerTo(timeType) {
+			headerSchema = newJSONSchema("string", nil)
+			headerSchema.Format = "http-date"
+		} else {
+			headerSchema = generateJSONSchemaFromType(fieldType, schemas)
+		}
+
+		response.Headers[field.name] = Header{
+			Description: field.description,
+			Required:    fieldType.Kind() != reflect.Ptr && !field.omitEmpty,
+			Schema:      headerSchema,
+		}
+	}
+	operation.Responses["200"] = response
+}
@@ -0,0 +1,310 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fxfn/x/auth"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrTokenInactive          = errors.New("token is not active")
+	ErrTokenAudience          = errors.New("token is not issued for this audience")
+	ErrTokenInsufficientScope = errors.New("token lacks a required scope")
+)
+
+// IntrospectionConfig holds configuration for token introspection security schemes
+type IntrospectionConfig struct {
+	Name         string               // Name for OpenAPI documentation (e.g., "OAuth2")
+	Description  string               // Description for OpenAPI documentation (optional)
+	Auth         *auth.Auth           // Client of the authorization server introspecting tokens
+	ClientID     string               // Client ID for the introspection endpoint
+	ClientSecret string               // Client secret for the introspection endpoint
+	Audience     string               // Audience every token must be issued for (optional)
+	Scopes       []string             // Scopes every token must have (optional)
+	CacheTTL     time.Duration        // How long introspection results are reused (default 1 minute, negative disables caching)
+	CacheSize    int                  // Most tokens cached at once (default 10000)
+	BruteForce   *BruteForceProtector // Locks out callers after repeated invalid tokens (optional)
+}
+
+// IntrospectionSecurity protects routes with opaque access tokens, checking
+// each with the authorization server's introspection endpoint (RFC 7662).
+// Inactive tokens and tokens for another audience get a 401, tokens without
+// the required scopes a 403. Results are cached for CacheTTL, but never past
+// the token's expiry, so the server isn't asked on every request.
+//
+//	protected := schema.NewIntrospectionSecurity(schema.IntrospectionConfig{
+//		Name:         "OAuth2",
+//		Auth:         client,
+//		ClientID:     "orders-api",
+//		ClientSecret: secret,
+//		Audience:     "orders-api",
+//		Scopes:       []string{"orders:read"},
+//	})
+//	router.GET("/orders", protected, schema.ValidateAndHandle(ListOrders))
+//
+// Handlers find the introspected token with auth.IntrospectionFromContext on
+// the request context, or under "introspection" in the gin context.
+type IntrospectionSecurity struct {
+	Name         string
+	Description  string
+	Auth         *auth.Auth
+	ClientID     string
+	ClientSecret string
+	Audience     string
+	Scopes       []string
+	BruteForce   *BruteForceProtector
+
+	cache *introspectionCache
+}
+
+// NewIntrospectionSecurity creates a new token introspection security scheme
+func NewIntrospectionSecurity(config IntrospectionConfig) *IntrospectionSecurity {
+	if config.CacheTTL == 0 {
+		config.CacheTTL = time.Minute
+	}
+	if config.CacheSize == 0 {
+		config.CacheSize = 10000
+	}
+
+	security := &IntrospectionSecurity{
+		Name:         config.Name,
+		Description:  config.Description,
+		Auth:         config.Auth,
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Audience:     config.Audience,
+		Scopes:       config.Scopes,
+		BruteForce:   config.BruteForce,
+	}
+	if config.CacheTTL > 0 {
+		security.cache = &introspectionCache{
+			ttl:     config.CacheTTL,
+			size:    config.CacheSize,
+			entries: make(map[[sha256.Size]byte]introspectionEntry),
+		}
+	}
+	return security
+}
+
+// GetSecurityScheme returns the OpenAPI security scheme definition
+func (s *IntrospectionSecurity) GetSecurityScheme() (string, map[string]interface{}) {
+	description := s.Description
+	if description == "" {
+		description = "Opaque access token issued by the authorization server"
+		if len(s.Scopes) > 0 {
+			description += ", with the scopes " + strings.Join(s.Scopes, ", ")
+		}
+	}
+
+	return s.Name, map[string]interface{}{
+		"type":        "http",
+		"scheme":      "bearer",
+		"description": description,
+	}
+}
+
+// Middleware returns the gin.HandlerFunc introspecting bearer tokens
+func (s *IntrospectionSecurity) Middleware() gin.HandlerFunc {
+	handler := func(c *gin.Context) {
+		start := auditStart(c)
+		defer auditRequest(c, start)
+
+		if s.BruteForce.rejectIfLocked(c) {
+			return
+		}
+
+		scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !strings.EqualFold(scheme, "bearer") || token == "" {
+			s.unauthorized(c, "", "Bearer token required")
+			return
+		}
+
+		introspection, err := s.Verify(token)
+		switch {
+		case errors.Is(err, ErrTokenInactive):
+			s.BruteForce.recordFailure(c)
+			s.unauthorized(c, "invalid_token", "Token is not active")
+			return
+		case errors.Is(err, ErrTokenAudience):
+			s.unauthorized(c, "invalid_token", "Token is not issued for this API")
+			return
+		case errors.Is(err, ErrTokenInsufficientScope):
+			s.challenge(c, "insufficient_scope", strings.Join(s.Scopes, " "))
+			respondError(c, http.StatusForbidden, "ERR_FORBIDDEN", "Token lacks a required scope")
+			c.Abort()
+			return
+		case err != nil:
+			c.Error(err)
+			respondError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", debugMessage("Token could not be verified, try again later", err))
+			c.Abort()
+			return
+		}
+
+		s.BruteForce.recordSuccess(c)
+		s.attach(c, token, introspection)
+		c.Next()
+	}
+
+	// Register this handler with the security scheme
+	RegisterSecurityMiddleware(handler, s)
+	return handler
+}
+
+// Verify introspects a token and checks it is active, issued for Audience
+// and has Scopes. Other errors come from the authorization server.
+func (s *IntrospectionSecurity) Verify(token string) (*auth.IntrospectResponse, error) {
+	introspection, err := s.introspect(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !introspection.Active {
+		return nil, ErrTokenInactive
+	}
+
+	if s.Audience != "" && !introspection.Audience.Contains(s.Audience) {
+		return nil, ErrTokenAudience
+	}
+
+	scopes := introspection.Scopes()
+	for _, scope := range s.Scopes {
+		if !slices.Contains(scopes, scope) {
+			return nil, fmt.Errorf("%w: %s", ErrTokenInsufficientScope, scope)
+		}
+	}
+
+	return introspection, nil
+}
+
+// attach stores a verified token for handlers
+func (s *IntrospectionSecurity) attach(c *gin.Context, token string, introspection *auth.IntrospectResponse) {
+	c.Request = c.Request.WithContext(auth.ContextWithIntrospection(c.Request.Context(), introspection))
+	c.Set("introspection", introspection)
+	c.Set("bearer_token", token)
+	c.Set("auth_method", "introspection")
+}
+
+// introspect returns the cached introspection of a token, asking the
+// authorization server when there is none
+func (s *IntrospectionSecurity) introspect(token string) (*auth.IntrospectResponse, error) {
+	if s.Auth == nil {
+		return nil, fmt.Errorf("introspection security %q has no auth client", s.Name)
+	}
+
+	if introspection, ok := s.cache.get(token); ok {
+		return introspection, nil
+	}
+
+	introspection, err := s.Auth.Introspect(auth.IntrospectOpts{
+		Token:        token,
+		ClientId:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.put(token, introspection)
+	return introspection, nil
+}
+
+func (s *IntrospectionSecurity) unauthorized(c *gin.Context, errorCode, message string) {
+	s.challenge(c, errorCode, "")
+	respondError(c, http.StatusUnauthorized, "UNAUTHORIZED", message)
+	c.Abort()
+}
+
+// challenge sets the WWW-Authenticate header of RFC 6750
+func (s *IntrospectionSecurity) challenge(c *gin.Context, errorCode, scope string) {
+	challenge := "Bearer"
+	if errorCode != "" {
+		challenge += fmt.Sprintf(` error=%q`, errorCode)
+	}
+	if scope != "" {
+		challenge += fmt.Sprintf(` scope=%q`, scope)
+	}
+	c.Header("WWW-Authenticate", challenge)
+}
+
+// introspectionCache keeps introspection results by the hash of their token,
+// so tokens themselves aren't kept in memory
+type introspectionCache struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]introspectionEntry
+}
+
+type introspectionEntry struct {
+	introspection *auth.IntrospectResponse
+	expires       time.Time
+}
+
+func (ic *introspectionCache) get(token string) (*auth.IntrospectResponse, bool) {
+	if ic == nil {
+		return nil, false
+	}
+
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	key := sha256.Sum256([]byte(token))
+	entry, exists := ic.entries[key]
+	if !exists {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(ic.entries, key)
+		return nil, false
+	}
+	return entry.introspection, true
+}
+
+// put caches an introspection for the TTL, or until the token expires when
+// that is sooner. Inactive results are cached too, sparing the server from
+// repeated invalid tokens.
+func (ic *introspectionCache) put(token string, introspection *auth.IntrospectResponse) {
+	if ic == nil {
+		return
+	}
+
+	now := time.Now()
+	expires := now.Add(ic.ttl)
+	if introspection.ExpiresAt > 0 {
+		if tokenExpires := time.Unix(int64(introspection.ExpiresAt), 0); tokenExpires.Before(expires) {
+			expires = tokenExpires
+		}
+	}
+	if !expires.After(now) {
+		return
+	}
+
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	if len(ic.entries) >= ic.size {
+		for key, entry := range ic.entries {
+			if now.After(entry.expires) {
+				delete(ic.entries, key)
+			}
+		}
+	}
+	// Still full of live entries: evict an arbitrary one
+	if len(ic.entries) >= ic.size {
+		for key := range ic.entries {
+			delete(ic.entries, key)
+			break
+		}
+	}
+
+	ic.entries[sha256.Sum256([]byte(token))] = introspectionEntry{introspection: introspection, expires: expires}
+}
@@ -0,0 +1,405 @@
+package schema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NullMode controls how nil pointers, slices, maps and interfaces are written
+type NullMode int
+
+const (
+	NullsAsTagged NullMode = iota // encoding/json behavior, omitempty fields are dropped
+	NullsOmitted                  // Every null field and map entry is dropped
+	NullsExplicit                 // Nil values are written as null even with omitempty
+)
+
+// JSONOptions configures how responses are encoded
+type JSONOptions struct {
+	Indent            string         // Indentation per level, empty for compact output
+	DisableHTMLEscape bool           // Write <, > and & as is instead of \u003c escapes
+	TimeFormat        string         // time.Time layout, e.g. time.DateTime (default RFC 3339)
+	Nulls             NullMode       // How nil values are written
+	Naming            NamingStrategy // Field naming, defaults to the route or global strategy
+}
+
+// JSONEncoder writes response bodies
+type JSONEncoder interface {
+	EncodeJSON(w io.Writer, v interface{}) error
+}
+
+// JSONEncoderFunc adapts a function to JSONEncoder
+type JSONEncoderFunc func(w io.Writer, v interface{}) error
+
+func (f JSONEncoderFunc) EncodeJSON(w io.Writer, v interface{}) error {
+	return f(w, v)
+}
+
+// Global JSON encoder, nil writes responses with c.JSON
+var globalJSONEncoder JSONEncoder
+
+// SetJSONEncoder sets the encoder for all ValidateAndHandle responses, nil
+// restores the default
+func SetJSONEncoder(encoder JSONEncoder) {
+	globalJSONEncoder = encoder
+}
+
+// GetJSONEncoder returns the encoder set with SetJSONEncoder or SetJSONOptions
+func GetJSONEncoder() JSONEncoder {
+	return globalJSONEncoder
+}
+
+// SetJSONOptions encodes all ValidateAndHandle responses with opts
+func SetJSONOptions(opts JSONOptions) {
+	SetJSONEncoder(NewJSONEncoder(opts))
+}
+
+// WithJSONOptions returns a copy of the handler that encodes its responses with opts
+func (t TypedHandlerFunc) WithJSONOptions(opts JSONOptions) TypedHandlerFunc {
+	return t.WithJSONEncoder(NewJSONEncoder(opts))
+}
+
+// WithJSONEncoder returns a copy of the handler that encodes its responses with encoder
+func (t TypedHandlerFunc) WithJSONEncoder(encoder JSONEncoder) TypedHandlerFunc {
+	handler := t.handler
+	t.handler = func(c *gin.Context) {
+		c.Set("json_encoder", encoder)
+		handler(c)
+	}
+	return t
+}
+
+// NewJSONEncoder returns the built-in encoder for opts
+func NewJSONEncoder(opts JSONOptions) JSONEncoder {
+	return &optionsEncoder{opts: opts}
+}
+
+// optionsEncoder is the built-in JSONEncoder
+type optionsEncoder struct {
+	opts JSONOptions
+}
+
+func (e *optionsEncoder) EncodeJSON(w io.Writer, v interface{}) error {
+	opts := e.opts
+	if opts.TimeFormat == "" && opts.Nulls == NullsAsTagged && opts.Naming == nil {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(!opts.DisableHTMLEscape)
+		encoder.SetIndent("", opts.Indent)
+		return encoder.Encode(v)
+	}
+
+	jw := &jsonWriter{opts: opts}
+	if err := jw.write(reflect.ValueOf(v)); err != nil {
+		return err
+	}
+
+	out := jw.buf.Bytes()
+	if opts.Indent != "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, out, "", opts.Indent); err != nil {
+			return err
+		}
+		out = indented.Bytes()
+	}
+	_, err := w.Write(append(out, '\n'))
+	return err
+}
+
+// writeJSON writes a response with the route or global encoder
+func writeJSON(c *gin.Context, status int, v interface{}) {
+	encoder := globalJSONEncoder
+	if routeEncoder, ok := c.Get("json_encoder"); ok {
+		encoder = routeEncoder.(JSONEncoder)
+	}
+
+	// The built-in encoder applies the naming strategy unless its options set one
+	if strategy := contextNamingStrategy(c); strategy != nil {
+		switch e := encoder.(type) {
+		case nil:
+			encoder = NewJSONEncoder(JSONOptions{Naming: strategy})
+		case *optionsEncoder:
+			if e.opts.Naming == nil {
+				opts := e.opts
+				opts.Naming = strategy
+				encoder = NewJSONEncoder(opts)
+			}
+		}
+	}
+
+	if encoder == nil {
+		c.JSON(status, v)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := encoder.EncodeJSON(&buf, v); err != nil {
+		c.Error(err)
+		c.JSON(500, globalWrapper.WrapError("ERR_INTERNAL", "Failed to encode response"))
+		return
+	}
+	c.Data(status, "application/json; charset=utf-8", bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// jsonWriter encodes values like encoding/json, applying the time format and
+// null handling of JSONOptions
+type jsonWriter struct {
+	opts JSONOptions
+	buf  bytes.Buffer
+}
+
+// isNull reports whether v is written as null
+func isNull(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero() && v.Kind() != reflect.Struct
+}
+
+func (w *jsonWriter) write(v reflect.Value) error {
+	if isNull(v) {
+		w.buf.WriteString("null")
+		return nil
+	}
+
+	if v.Type() == timeType && w.opts.TimeFormat != "" {
+		return w.writeString(v.Interface().(time.Time).Format(w.opts.TimeFormat))
+	}
+
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+		if marshaler, ok := asInterface(v, jsonMarshalerType); ok {
+			data, err := marshaler.(json.Marshaler).MarshalJSON()
+			if err != nil {
+				return err
+			}
+			return json.Compact(&w.buf, data)
+		}
+		if marshaler, ok := asInterface(v, textMarshalerType); ok {
+			text, err := marshaler.(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return err
+			}
+			return w.writeString(string(text))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return w.write(v.Elem())
+	case reflect.String:
+		return w.writeString(v.String())
+	case reflect.Bool:
+		w.buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return w.writeEncoded(v.Interface())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return w.writeEncoded(v.Interface())
+		}
+		w.buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			if err := w.write(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		w.buf.WriteByte(']')
+	case reflect.Map:
+		return w.writeMap(v)
+	case reflect.Struct:
+		w.buf.WriteByte('{')
+		first := true
+		if err := w.writeFields(v, &first); err != nil {
+			return err
+		}
+		w.buf.WriteByte('}')
+	default:
+		return fmt.Errorf("json: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// asInterface returns v, or its address, as iface when it implements it
+func asInterface(v reflect.Value, iface reflect.Type) (interface{}, bool) {
+	if v.Type().Implements(iface) {
+		return v.Interface(), true
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(iface) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+func (w *jsonWriter) writeString(s string) error {
+	return w.writeEncoded(s)
+}
+
+// writeEncoded writes a leaf value with encoding/json and the HTML escaping option
+func (w *jsonWriter) writeEncoded(v interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(!w.opts.DisableHTMLEscape)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	w.buf.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return nil
+}
+
+func (w *jsonWriter) writeMember(key string, value reflect.Value, first *bool) error {
+	if !*first {
+		w.buf.WriteByte(',')
+	}
+	*first = false
+	if err := w.writeString(key); err != nil {
+		return err
+	}
+	w.buf.WriteByte(':')
+	return w.write(value)
+}
+
+func (w *jsonWriter) writeMap(v reflect.Value) error {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := jsonMapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		if w.opts.Nulls == NullsOmitted && isNull(iter.Value()) {
+			continue
+		}
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+	sort.Strings(keys)
+
+	w.buf.WriteByte('{')
+	first := true
+	for _, key := range keys {
+		if err := w.writeMember(key, values[key], &first); err != nil {
+			return err
+		}
+	}
+	w.buf.WriteByte('}')
+	return nil
+}
+
+func jsonMapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if marshaler, ok := asInterface(key, textMarshalerType); ok {
+		text, err := marshaler.(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("json: unsupported map key type %s", key.Type())
+}
+
+// writeFields writes the members of a struct, inlining untagged embedded structs
+func (w *jsonWriter) writeFields(v reflect.Value, first *bool) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		value := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if value.Kind() == reflect.Ptr {
+					if value.IsNil() {
+						continue
+					}
+					value = value.Elem()
+				}
+				if err := w.writeFields(value, first); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if w.opts.Naming != nil {
+			name = w.opts.Naming(name)
+		}
+
+		omitEmpty := hasJSONOption(options, "omitempty")
+		switch {
+		case w.opts.Nulls == NullsOmitted && isNull(value):
+			continue
+		case w.opts.Nulls == NullsExplicit && isNull(value):
+		case omitEmpty && isEmptyJSONValue(value):
+			continue
+		}
+
+		if err := w.writeMember(name, value, first); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasJSONOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
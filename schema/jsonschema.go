@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaDraft is the dialect of documents built by JSONSchemaFor
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+const jsonSchemaDefsPrefix = "#/$defs/"
+
+// JSONSchemaDocument is a self-contained JSON Schema: the root schema of a
+// type together with the named schemas it references under $defs
+type JSONSchemaDocument struct {
+	Schema string // $schema, JSONSchemaDraft
+	ID     string // $id, optional
+	Root   *JSONSchema
+	Defs   map[string]*JSONSchema // $defs, referenced as "#/$defs/Name"
+}
+
+// JSONSchemaFor builds a draft 2020-12 JSON Schema for the type of v, or for
+// v itself when it is a reflect.Type, with the same reflection used for
+// OpenAPI. Use it to validate configuration, queue messages and other
+// contracts that are not served over HTTP:
+//
+//	doc := schema.JSONSchemaFor(OrderCreated{})
+//	data, _ := json.MarshalIndent(doc, "", "  ")
+func JSONSchemaFor(v any) *JSONSchemaDocument {
+	doc := &JSONSchemaDocument{Schema: JSONSchemaDraft, Root: &JSONSchema{}}
+
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	if t == nil {
+		return doc
+	}
+
+	schemas := make(map[string]*JSONSchema)
+	doc.Root = generateJSONSchemaFromType(t, schemas)
+
+	rewrite := func(schema *JSONSchema) {
+		walkSchemas(schema, func(s *JSONSchema) {
+			if strings.HasPrefix(s.Ref, componentSchemaPrefix) {
+				s.Ref = jsonSchemaDefsPrefix + strings.TrimPrefix(s.Ref, componentSchemaPrefix)
+			}
+		})
+	}
+	rewrite(doc.Root)
+	for _, schema := range schemas {
+		rewrite(schema)
+	}
+
+	// Inline the root type unless it refers to itself
+	if name := strings.TrimPrefix(doc.Root.Ref, jsonSchemaDefsPrefix); name != doc.Root.Ref {
+		if root, exists := schemas[name]; exists && !refersTo(schemas, doc.Root.Ref) {
+			doc.Root = root
+			delete(schemas, name)
+		}
+	}
+
+	if len(schemas) > 0 {
+		doc.Defs = schemas
+	}
+	return doc
+}
+
+// refersTo reports whether any of schemas contains ref
+func refersTo(schemas map[string]*JSONSchema, ref string) bool {
+	found := false
+	for _, schema := range schemas {
+		walkSchemas(schema, func(s *JSONSchema) {
+			found = found || s.Ref == ref
+		})
+	}
+	return found
+}
+
+// MarshalJSON writes $schema and $id, the keywords of the root schema, then $defs
+func (d JSONSchemaDocument) MarshalJSON() ([]byte, error) {
+	root := d.Root
+	if root == nil {
+		root = &JSONSchema{}
+	}
+
+	data, err := json.Marshal(struct {
+		Schema string `json:"$schema"`
+		ID     string `json:"$id,omitempty"`
+	}{d.Schema, d.ID})
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSuffix(data, []byte("}"))
+	keywords = bytes.TrimPrefix(keywords, []byte("{"))
+	if !bytes.Equal(keywords, []byte("}")) {
+		data = append(data, ',')
+	}
+	data = append(data, keywords...)
+
+	if len(d.Defs) == 0 {
+		return data, nil
+	}
+	return appendExtensions(data, Extensions{"$defs": d.Defs})
+}
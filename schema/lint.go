@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LintWarning describes a quality problem found in a generated spec
+type LintWarning struct {
+	Rule     string // Identifier of the check, e.g. "operation-tags"
+	Location string // Where the problem is, e.g. "paths./users.get"
+	Message  string
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf("%s: %s (%s)", w.Location, w.Message, w.Rule)
+}
+
+const componentSchemaPrefix = "#/components/schemas/"
+
+// Lint checks the spec for common problems and returns one warning per
+// finding, in a stable order. An empty result means the spec is clean.
+func (o *OpenAPISpec) Lint() []LintWarning {
+	var warnings []LintWarning
+	warn := func(rule, location, format string, args ...interface{}) {
+		warnings = append(warnings, LintWarning{
+			Rule:     rule,
+			Location: location,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if o.Info.Description == "" {
+		warn("info-description", "info", "API has no description")
+	}
+
+	operationIDs := make(map[string]string)
+	for _, entry := range o.operations() {
+		location := "paths." + entry.Path + "." + entry.Method
+		operation := entry.Operation
+
+		if operation.Description == "" {
+			warn("operation-description", location, "operation has no description")
+		}
+		if len(operation.Tags) == 0 {
+			warn("operation-tags", location, "operation has no tags")
+		}
+
+		if operation.OperationID != "" {
+			if previous, exists := operationIDs[operation.OperationID]; exists {
+				warn("duplicate-operation-id", location, "operationId '%s' is already used by %s", operation.OperationID, previous)
+			} else {
+				operationIDs[operation.OperationID] = location
+			}
+		}
+
+		for _, status := range sortedKeys(operation.Responses) {
+			if operation.Responses[status].Description == "" {
+				warn("response-description", location+".responses."+status, "response has no description")
+			}
+		}
+	}
+
+	if o.Components != nil {
+		referenced := o.referencedSchemas()
+		for _, name := range sortedKeys(o.Components.Schemas) {
+			location := "components.schemas." + name
+			if strings.HasPrefix(name, "AnonymousStruct") {
+				warn("anonymous-schema-name", location, "schema was generated from an anonymous struct without a name")
+			}
+			if !referenced[name] {
+				warn("orphaned-schema", location, "schema is not referenced by any operation")
+			}
+		}
+	}
+
+	return warnings
+}
+
+// referencedSchemas returns the names of component schemas reachable from the paths
+func (o *OpenAPISpec) referencedSchemas() map[string]bool {
+	referenced := make(map[string]bool)
+
+	var pending []string
+	visit := func(schema *JSONSchema) {
+		walkSchemaRefs(schema, func(ref string) {
+			name := strings.TrimPrefix(ref, componentSchemaPrefix)
+			if !referenced[name] {
+				referenced[name] = true
+				pending = append(pending, name)
+			}
+		})
+	}
+
+	for _, entry := range o.operations() {
+		forEachOperationSchema(entry.Operation, visit)
+	}
+
+	// Follow references between components
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if o.Components != nil {
+			visit(o.Components.Schemas[name])
+		}
+	}
+
+	return referenced
+}
+
+// forEachOperationSchema calls fn for every top-level schema used by an operation
+func forEachOperationSchema(operation *Operation, fn func(*JSONSchema)) {
+	for _, parameter := range operation.Parameters {
+		fn(parameter.Schema)
+	}
+	if operation.RequestBody != nil {
+		for _, mediaType := range operation.RequestBody.Content {
+			fn(mediaType.Schema)
+		}
+	}
+	for _, response := range operation.Responses {
+		for _, header := range response.Headers {
+			fn(header.Schema)
+		}
+		for _, mediaType := range response.Content {
+			fn(mediaType.Schema)
+		}
+	}
+}
+
+// walkSchemaRefs calls fn for every component $ref inside schema
+func walkSchemaRefs(schema *JSONSchema, fn func(ref string)) {
+	walkSchemas(schema, func(s *JSONSchema) {
+		if strings.HasPrefix(s.Ref, componentSchemaPrefix) {
+			fn(s.Ref)
+		}
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
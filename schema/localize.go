@@ -0,0 +1,169 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+)
+
+// MessageBundle maps message keys to the messages of one language. Messages
+// are fmt format strings, see Localize.
+type MessageBundle map[string]string
+
+// Registry of message bundles, the first registered language is the default
+var (
+	messageBundlesMu sync.RWMutex
+	messageBundles   = make(map[string]MessageBundle)
+	messageTags      []language.Tag
+	languageMatcher  language.Matcher
+)
+
+const (
+	languageKey         = "schema_language"
+	validationErrorsKey = "schema_validation_errors"
+)
+
+// RegisterMessages adds messages for a BCP 47 language such as "en" or
+// "pt-BR", merging them into messages registered before for it. The first
+// registered language is used when the client's Accept-Language matches none.
+//
+// Once any bundle is registered, error responses are localized: the message
+// of a SchemaError or ErrorResult is looked up as a key, then its code, and
+// sent as is when neither is found. Validation errors are built from the keys
+// "validation.<tag>", e.g. "validation.required", formatted with the field
+// name as %[1]s and the tag parameter as %[2]s:
+//
+//	schema.RegisterMessages("en", schema.MessageBundle{
+//		"user.not_found":      "User not found",
+//		"validation.required": "%[1]s is required",
+//		"validation.min":      "%[1]s must be at least %[2]s",
+//	})
+//	schema.RegisterMessages("de", schema.MessageBundle{
+//		"user.not_found":      "Benutzer nicht gefunden",
+//		"validation.required": "%[1]s ist erforderlich",
+//	})
+//
+//	return nil, schema.NewSchemaError("ERR_USER_NOT_FOUND", "user.not_found")
+//
+// RegisterMessages panics when lang is not a valid language tag.
+func RegisterMessages(lang string, messages MessageBundle) {
+	tag := language.MustParse(lang)
+	key := tag.String()
+
+	messageBundlesMu.Lock()
+	defer messageBundlesMu.Unlock()
+
+	bundle, exists := messageBundles[key]
+	if !exists {
+		bundle = make(MessageBundle)
+		messageBundles[key] = bundle
+		messageTags = append(messageTags, tag)
+		languageMatcher = language.NewMatcher(messageTags)
+	}
+	for k, message := range messages {
+		bundle[k] = message
+	}
+}
+
+// Language returns the registered language best matching the request's
+// Accept-Language header, or "" when no messages are registered
+func Language(c *gin.Context) string {
+	if lang, ok := c.Get(languageKey); ok {
+		return lang.(string)
+	}
+
+	messageBundlesMu.RLock()
+	defer messageBundlesMu.RUnlock()
+
+	if languageMatcher == nil {
+		return ""
+	}
+
+	tags, _, _ := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	_, index, _ := languageMatcher.Match(tags...)
+	lang := messageTags[index].String()
+	c.Set(languageKey, lang)
+	return lang
+}
+
+// Localize returns the message for key in the request's language, formatted
+// with args by fmt.Sprintf. The key itself is returned when no language has
+// the message, so missing translations stay visible.
+func Localize(c *gin.Context, key string, args ...interface{}) string {
+	message, ok := lookupMessage(Language(c), key)
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// lookupMessage finds key in the bundle of lang, then of the default language
+func lookupMessage(lang, key string) (string, bool) {
+	if lang == "" {
+		return "", false
+	}
+
+	messageBundlesMu.RLock()
+	defer messageBundlesMu.RUnlock()
+
+	if message, ok := messageBundles[lang][key]; ok {
+		return message, true
+	}
+	message, ok := messageBundles[messageTags[0].String()][key]
+	return message, ok
+}
+
+// recordValidationErrors keeps validator errors on the request so
+// respondError can localize them
+func recordValidationErrors(c *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		c.Set(validationErrorsKey, validationErrors)
+	}
+}
+
+// localizeError returns the error message in the request's language
+func localizeError(c *gin.Context, code, message string) string {
+	lang := Language(c)
+	if lang == "" {
+		return message
+	}
+	c.Header("Content-Language", lang)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+
+	if value, ok := c.Get(validationErrorsKey); ok {
+		if localized, ok := localizeValidationErrors(lang, value.(validator.ValidationErrors)); ok {
+			return localized
+		}
+	}
+
+	if localized, ok := lookupMessage(lang, message); ok {
+		return localized
+	}
+	if localized, ok := lookupMessage(lang, code); ok {
+		return localized
+	}
+	return message
+}
+
+// localizeValidationErrors formats every field error with its
+// "validation.<tag>" message, failing when one has no message
+func localizeValidationErrors(lang string, validationErrors validator.ValidationErrors) (string, bool) {
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		format, ok := lookupMessage(lang, "validation."+fieldError.Tag())
+		if !ok {
+			return "", false
+		}
+		messages = append(messages, fmt.Sprintf(format, fieldError.Field(), fieldError.Param()))
+	}
+	return strings.Join(messages, "; "), true
+}
@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// TopLevelFieldMode controls where auto-detected top-level primitive fields
+// (fields outside of Params/Query/Headers/Cookies/Body) are read from
+type TopLevelFieldMode int
+
+const (
+	// TopLevelFieldsAsQuery treats auto-detected fields as query parameters for every method
+	TopLevelFieldsAsQuery TopLevelFieldMode = iota
+	// TopLevelFieldsAsBodyForWrites treats auto-detected fields as JSON body
+	// properties for POST, PUT and PATCH, and as query parameters otherwise
+	TopLevelFieldsAsBodyForWrites
+)
+
+const (
+	fieldLocationQuery  = "query"
+	fieldLocationBody   = "body"
+	fieldLocationHeader = "header"
+	fieldLocationCookie = "cookie"
+)
+
+// Global top-level field mode configuration
+var topLevelFieldMode = TopLevelFieldsAsQuery
+
+// SetTopLevelFieldMode sets how auto-detected top-level fields are handled.
+// Fields tagged with `in:"query"`, `in:"body"`, `in:"header"` or `in:"cookie"`
+// are not affected.
+func SetTopLevelFieldMode(mode TopLevelFieldMode) {
+	topLevelFieldMode = mode
+}
+
+// GetTopLevelFieldMode returns the current top-level field mode
+func GetTopLevelFieldMode() TopLevelFieldMode {
+	return topLevelFieldMode
+}
+
+// isSectionField reports whether field is one of the Params, Query, Headers,
+// Cookies or Body sections of a schema, as opposed to a top-level field with
+// the same name
+func isSectionField(field reflect.StructField) bool {
+	switch strings.ToLower(field.Name) {
+	case "params", "query", "headers", "cookies", "body":
+	default:
+		return false
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// topLevelFieldLocation returns where a top-level schema field is read from
+// for the given method, or "" if it is not a request field
+func topLevelFieldLocation(field reflect.StructField, method string) string {
+	switch field.Tag.Get("in") {
+	case fieldLocationQuery:
+		return fieldLocationQuery
+	case fieldLocationBody:
+		return fieldLocationBody
+	case fieldLocationHeader:
+		return fieldLocationHeader
+	case fieldLocationCookie:
+		return fieldLocationCookie
+	}
+
+	if getTagValue(field, "header") != "" {
+		return fieldLocationHeader
+	}
+	if getTagValue(field, "cookie") != "" {
+		return fieldLocationCookie
+	}
+	if getTagValue(field, "query") != "" {
+		return fieldLocationQuery
+	}
+
+	if !isQueryParameter(field) {
+		return ""
+	}
+
+	if topLevelFieldMode == TopLevelFieldsAsBodyForWrites && isWriteMethod(method) {
+		return fieldLocationBody
+	}
+
+	return fieldLocationQuery
+}
+
+func isWriteMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case "POST", "PUT", "PATCH":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errLongPollEmpty tells ValidateAndHandle that a long poll ended without data
+var errLongPollEmpty = errors.New("long poll ended without data")
+
+// LongPoll wraps a handler that waits for data, such as a notification
+// channel, for at most maxWait. The handler should wait on
+// c.Request.Context(), which is cancelled when maxWait elapses or the client
+// disconnects. When the wait times out, or the handler returns a nil result
+// without an error, the client gets 204 No Content. When the client has gone
+// away nothing is written.
+func LongPoll[T Schema, R any](handler HandlerFunc[T, R], maxWait time.Duration) TypedHandlerFunc {
+	typed := ValidateAndHandle(func(c *gin.Context, schema T) (*R, error) {
+		parent := c.Request.Context()
+		ctx, cancel := context.WithTimeout(parent, maxWait)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		defer func() {
+			c.Request = c.Request.WithContext(parent)
+		}()
+
+		result, err := handler(c, schema)
+		if parent.Err() != nil {
+			return nil, errLongPollEmpty
+		}
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if result == nil || err != nil {
+			return nil, errLongPollEmpty
+		}
+		return result, nil
+	})
+
+	typed.longPoll = maxWait
+	return typed
+}
+
+// GetLongPollTimeout returns the maximum wait of a LongPoll handler, or zero
+func (t TypedHandlerFunc) GetLongPollTimeout() time.Duration {
+	return t.longPoll
+}
+
+// respondLongPollEmpty answers a long poll that produced no data
+func respondLongPollEmpty(c *gin.Context) {
+	if c.Request.Context().Err() != nil {
+		// The client disconnected, there is nobody to answer
+		c.Abort()
+		return
+	}
+	c.Status(204)
+}
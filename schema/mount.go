@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mountableRoute is a route registered through a RouterHelper with its full handler chain
+type mountableRoute struct {
+	method   string
+	path     string
+	handlers gin.HandlersChain
+}
+
+// recordRoute remembers a route so the helper can later be mounted into another router
+func (r *RouterHelper) recordRoute(method, path string, groupHandlers gin.HandlersChain, handlers []gin.HandlerFunc) {
+	chain := make(gin.HandlersChain, 0, len(groupHandlers)+len(handlers))
+	chain = append(chain, groupHandlers...)
+	chain = append(chain, handlers...)
+
+	r.routes = append(r.routes, mountableRoute{
+		method:   method,
+		path:     path,
+		handlers: chain,
+	})
+}
+
+// Mount registers every route of child under prefix, together with the
+// middleware it had in child, and copies its typed handler, security, public,
+// concurrency limit, caching, content type, CSRF and tag registrations to the
+// prefixed paths.
+// Only routes added through child's RouterHelper or RouterGroup methods are
+// mounted.
+func (r *RouterHelper) Mount(prefix string, child *RouterHelper) {
+	for _, route := range child.routes {
+		fullPath := joinRoutePath(prefix, route.path)
+
+		if typedHandler, exists := child.Registry().GetTypedHandler(route.method, route.path); exists {
+			r.Registry().RegisterTypedHandler(route.method, fullPath, typedHandler)
+		}
+		if schemes := child.Registry().GetSecuritySchemes(route.method, route.path); len(schemes) > 0 {
+			r.Registry().RegisterSecurityScheme(route.method, fullPath, schemes...)
+		}
+		if child.Registry().IsPublicRoute(route.method, route.path) {
+			r.Registry().RegisterPublicRoute(route.method, fullPath)
+			// The child's shared security middleware checks its own registry
+			child.Registry().RegisterPublicRoute(route.method, fullPath)
+		}
+		if limit, exists := child.Registry().GetConcurrencyLimit(route.method, route.path); exists {
+			r.Registry().RegisterConcurrencyLimit(route.method, fullPath, limit)
+		}
+		if cacheControl, exists := child.Registry().GetCacheControl(route.method, route.path); exists {
+			r.Registry().RegisterCacheControl(route.method, fullPath, cacheControl)
+		}
+		if contentTypes, exists := child.Registry().GetContentTypes(route.method, route.path); exists {
+			r.Registry().RegisterContentTypes(route.method, fullPath, contentTypes)
+		}
+		if csrf, exists := child.Registry().GetCSRFProtection(route.method, route.path); exists {
+			r.Registry().RegisterCSRFProtection(route.method, fullPath, csrf)
+		}
+		if tags := child.Registry().GetRouteTags(route.method, route.path); len(tags) > 0 {
+			r.Registry().RegisterRouteTags(route.method, fullPath, tags...)
+			for _, tag := range tags {
+				if description := child.Registry().tagDescription(tag); description != "" {
+					r.Registry().RegisterTagDescription(tag, description)
+				}
+			}
+		}
+
+		r.Engine.Handle(route.method, fullPath, route.handlers...)
+		r.recordRoute(route.method, fullPath, r.Engine.Handlers, route.handlers)
+	}
+}
+
+// joinRoutePath joins a mount prefix and a route path with a single slash
+func joinRoutePath(prefix, path string) string {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		return path
+	}
+	if path == "" || path == "/" {
+		return prefix
+	}
+	return prefix + "/" + strings.TrimPrefix(path, "/")
+}
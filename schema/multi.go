@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIMulti generates one spec for the routes of several engines, for
+// binaries that serve e.g. a public and an admin API on separate engines.
+// Paths and component schemas are merged; when engines serve the same method
+// and path, the first engine's route is documented.
+//
+// Route registrations are global and keyed by method and path, so a route
+// served by two engines with different handlers is documented with the one
+// registered last.
+func OpenAPIMulti(engines []*gin.Engine, opts *OpenAPIOpts) *OpenAPISpec {
+	spec := generateOpenAPISpec(mergeRoutes(engines), opts)
+	writeOutputFile(spec, opts.OutputFile)
+	return spec
+}
+
+// OpenAPIPerEngine generates a separate spec for every named engine, sharing
+// opts. OutputFile, when set, gets the engine name inserted before its
+// extension, so "openapi.json" becomes "openapi.admin.json" for the engine
+// named "admin".
+func OpenAPIPerEngine(engines map[string]*gin.Engine, opts *OpenAPIOpts) map[string]*OpenAPISpec {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	specs := make(map[string]*OpenAPISpec, len(engines))
+	for _, name := range names {
+		spec := generateOpenAPISpec(engines[name].Routes(), opts)
+		if opts.OutputFile != "" {
+			writeOutputFile(spec, engineOutputFile(opts.OutputFile, name))
+		}
+		specs[name] = spec
+	}
+	return specs
+}
+
+// mergeRoutes returns the routes of all engines, keeping the first route for
+// each method and path
+func mergeRoutes(engines []*gin.Engine) gin.RoutesInfo {
+	var routes gin.RoutesInfo
+	seen := make(map[string]bool)
+	for _, engine := range engines {
+		for _, route := range engine.Routes() {
+			key := routeKey(route.Method, route.Path)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// engineOutputFile inserts the engine name before the file extension
+func engineOutputFile(filename, name string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "." + name + ext
+}
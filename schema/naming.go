@@ -0,0 +1,172 @@
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NamingStrategy rewrites the JSON name of a struct field in responses
+type NamingStrategy func(name string) string
+
+// CamelCase names fields like userId, for JavaScript clients
+func CamelCase(name string) string {
+	words := splitWords(name)
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		words[i] = word
+	}
+	return strings.Join(words, "")
+}
+
+// SnakeCase names fields like user_id
+func SnakeCase(name string) string {
+	words := splitWords(name)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// splitWords splits Go and JSON identifiers into words: "UserID", "user_id"
+// and "userId" all give [user id], "HTTPServer" gives [HTTP Server]
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+
+		prev := runes[i-1]
+		boundary := unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) ||
+			unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// Global naming strategy, nil keeps the json tag or Go field name
+var globalNamingStrategy NamingStrategy
+
+// SetNamingStrategy rewrites the field names of every response and of the
+// component schemas in the generated spec, e.g. SetNamingStrategy(CamelCase)
+func SetNamingStrategy(strategy NamingStrategy) {
+	globalNamingStrategy = strategy
+}
+
+// GetNamingStrategy returns the strategy set with SetNamingStrategy
+func GetNamingStrategy() NamingStrategy {
+	return globalNamingStrategy
+}
+
+// WithNamingStrategy returns a copy of the handler whose responses use strategy
+// instead of the global one. The spec documents its response data with
+// variants of the component schemas, named after the strategy (UserCamelCase).
+func (t TypedHandlerFunc) WithNamingStrategy(strategy NamingStrategy) TypedHandlerFunc {
+	handler := t.handler
+	t.naming = strategy
+	t.handler = func(c *gin.Context) {
+		c.Set("naming_strategy", strategy)
+		handler(c)
+	}
+	return t
+}
+
+// GetNamingStrategy returns the strategy set with WithNamingStrategy, if any
+func (t TypedHandlerFunc) GetNamingStrategy() NamingStrategy {
+	return t.naming
+}
+
+// contextNamingStrategy returns the naming strategy for the current request
+func contextNamingStrategy(c *gin.Context) NamingStrategy {
+	if value, ok := c.Get("naming_strategy"); ok {
+		if strategy, ok := value.(NamingStrategy); ok && strategy != nil {
+			return strategy
+		}
+	}
+	return globalNamingStrategy
+}
+
+// schemaFieldName returns the documented name of a field under the global strategy
+func schemaFieldName(field reflect.StructField) string {
+	return namedFieldName(field, globalNamingStrategy)
+}
+
+// namedFieldName returns the name of a field under strategy
+func namedFieldName(field reflect.StructField, strategy NamingStrategy) string {
+	name := getJSONFieldName(field)
+	if strategy == nil || name == "-" {
+		return name
+	}
+	if tagName, _, _ := strings.Cut(field.Tag.Get("json"), ","); tagName == "" {
+		// getJSONFieldName lowercases untagged names, which loses word boundaries
+		name = field.Name
+	}
+	return strategy(name)
+}
+
+// sameNamingStrategy reports whether two strategies are the same function
+func sameNamingStrategy(a, b NamingStrategy) bool {
+	return namingStrategyID(a) == namingStrategyID(b)
+}
+
+func namingStrategyID(strategy NamingStrategy) uintptr {
+	if strategy == nil {
+		return 0
+	}
+	return reflect.ValueOf(strategy).Pointer()
+}
+
+var (
+	namingVariantsMu sync.Mutex
+	namingVariants   = make(map[uintptr]string)
+)
+
+// namingVariant returns the suffix of the component schemas documented under
+// strategy: none for the global strategy, CamelCase and SnakeCase for those,
+// and Naming1, Naming2... for others in the order they are documented
+func namingVariant(strategy NamingStrategy) string {
+	if strategy == nil || sameNamingStrategy(strategy, globalNamingStrategy) {
+		return ""
+	}
+
+	switch {
+	case sameNamingStrategy(strategy, CamelCase):
+		return "CamelCase"
+	case sameNamingStrategy(strategy, SnakeCase):
+		return "SnakeCase"
+	}
+
+	namingVariantsMu.Lock()
+	defer namingVariantsMu.Unlock()
+	id := namingStrategyID(strategy)
+	if variant, exists := namingVariants[id]; exists {
+		return variant
+	}
+	variant := "Naming" + strconv.Itoa(len(namingVariants)+1)
+	namingVariants[id] = variant
+	return variant
+}
@@ -3,14 +3,16 @@ package schema
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-yaml/yaml"
 )
 
 type OpenAPIOpts struct {
@@ -19,23 +21,39 @@ type OpenAPIOpts struct {
 	Version     string
 	Contact     string
 	License     string
-	OutputFile  string // Path to output swagger.json file
+	OutputFile  string                 // Path to output swagger.json file
+	Extensions  map[string]interface{} // Vendor extensions added to info, e.g. "x-logo"
+
+	Servers       []Server // Servers listed in the spec
+	RequestServer bool     // HandleGetSwagger lists the server the request reached first
+	UseBuildInfo  bool     // Empty Title and Version come from the binary's build info
+
+	DocumentFallbacks bool      // Every operation documents the 404 and 405 responses of NoRoute and NoMethod
+	ErrorCodes        bool      // Error responses refer to an ErrorCode enum of the codes from ErrorCodes
+	Registry          *Registry // Typed handlers and route options to document, the global registry when nil
 }
 
 // OpenAPI 3.1 specification structures
 type OpenAPISpec struct {
 	OpenAPI    string              `json:"openapi" yaml:"openapi"`
 	Info       Info                `json:"info" yaml:"info"`
+	Servers    []Server            `json:"servers,omitempty" yaml:"servers,omitempty"`
 	Paths      map[string]PathItem `json:"paths" yaml:"paths"`
 	Components *Components         `json:"components,omitempty" yaml:"components,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Extensions Extensions          `json:"-" yaml:",inline"`
+
+	requestServer bool
+	snapshots     map[OutputFormat]*specSnapshot // Serialized once for HandleGetSwagger, see Invalidate
 }
 
 type Info struct {
-	Title       string   `json:"title" yaml:"title"`
-	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
-	Version     string   `json:"version" yaml:"version"`
-	Contact     *Contact `json:"contact,omitempty" yaml:"contact,omitempty"`
-	License     *License `json:"license,omitempty" yaml:"license,omitempty"`
+	Title       string     `json:"title" yaml:"title"`
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string     `json:"version" yaml:"version"`
+	Contact     *Contact   `json:"contact,omitempty" yaml:"contact,omitempty"`
+	License     *License   `json:"license,omitempty" yaml:"license,omitempty"`
+	Extensions  Extensions `json:"-" yaml:",inline"`
 }
 
 type Contact struct {
@@ -55,6 +73,7 @@ type PathItem struct {
 }
 
 type Operation struct {
+	OperationID string                `json:"operationId,omitempty" yaml:"operationId,omitempty"`
 	Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
 	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
 	Parameters  []Parameter           `json:"parameters,omitempty" yaml:"parameters,omitempty"`
@@ -62,6 +81,59 @@ type Operation struct {
 	Responses   map[string]Response   `json:"responses" yaml:"responses"`
 	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
 	Security    []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+	Extensions  Extensions            `json:"-" yaml:",inline"`
+}
+
+// specOperation is an operation with its location in the spec
+type specOperation struct {
+	Path      string
+	Method    string
+	Operation *Operation
+}
+
+// operations returns every operation sorted by path, then method
+func (o *OpenAPISpec) operations() []specOperation {
+	paths := make([]string, 0, len(o.Paths))
+	for path := range o.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var operations []specOperation
+	for _, path := range paths {
+		item := o.Paths[path]
+		for _, entry := range []struct {
+			method    string
+			operation *Operation
+		}{
+			{"get", item.Get},
+			{"post", item.Post},
+			{"put", item.Put},
+			{"delete", item.Delete},
+			{"patch", item.Patch},
+		} {
+			if entry.operation != nil {
+				operations = append(operations, specOperation{Path: path, Method: entry.method, Operation: entry.operation})
+			}
+		}
+	}
+	return operations
+}
+
+// parameterLocations orders parameters by where they are sent
+var parameterLocations = map[string]int{"path": 0, "query": 1, "header": 2, "cookie": 3}
+
+// sortParameters lists the parameters of every operation by location, path
+// parameters first, keeping the declaration order within a location. Paths,
+// responses and component schemas are maps, which both encoders write sorted
+// by key, so the spec serializes the same way on every run.
+func (o *OpenAPISpec) sortParameters() {
+	for _, entry := range o.operations() {
+		parameters := entry.Operation.Parameters
+		sort.SliceStable(parameters, func(i, j int) bool {
+			return parameterLocations[parameters[i].In] < parameterLocations[parameters[j].In]
+		})
+	}
 }
 
 type Parameter struct {
@@ -69,7 +141,10 @@ type Parameter struct {
 	In          string      `json:"in" yaml:"in"` // "query", "header", "path", "cookie"
 	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
 	Required    bool        `json:"required,omitempty" yaml:"required,omitempty"`
+	Style       string      `json:"style,omitempty" yaml:"style,omitempty"`
+	Explode     *bool       `json:"explode,omitempty" yaml:"explode,omitempty"`
 	Schema      *JSONSchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Extensions  Extensions  `json:"-" yaml:",inline"`
 }
 
 type RequestBody struct {
@@ -80,11 +155,25 @@ type RequestBody struct {
 
 type Response struct {
 	Description string               `json:"description" yaml:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty" yaml:"headers,omitempty"`
 	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
 }
 
+type Header struct {
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool        `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *JSONSchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
 type MediaType struct {
-	Schema *JSONSchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Schema   *JSONSchema              `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Examples map[string]ExampleObject `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+type ExampleObject struct {
+	Summary     string      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Value       interface{} `json:"value,omitempty" yaml:"value,omitempty"`
 }
 
 type Components struct {
@@ -104,10 +193,21 @@ type JSONSchema struct {
 	Maximum              *float64               `json:"maximum,omitempty" yaml:"maximum,omitempty"`
 	MinLength            *int                   `json:"minLength,omitempty" yaml:"minLength,omitempty"`
 	MaxLength            *int                   `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty" yaml:"uniqueItems,omitempty"`
+	MinProperties        *int                   `json:"minProperties,omitempty" yaml:"minProperties,omitempty"`
+	MaxProperties        *int                   `json:"maxProperties,omitempty" yaml:"maxProperties,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty" yaml:"enum,omitempty"`
 	Pattern              string                 `json:"pattern,omitempty" yaml:"pattern,omitempty"`
 	Format               string                 `json:"format,omitempty" yaml:"format,omitempty"`
 	Ref                  string                 `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 	AdditionalProperties interface{}            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Extensions           Extensions             `json:"-" yaml:",inline"`
+
+	propertyOrder []string // Declaration order of Properties, see orderedProperties
 }
 
 // HandlerInfo stores information about a handler function
@@ -117,6 +217,21 @@ type HandlerInfo struct {
 	Method          string
 	Path            string
 	SecuritySchemes []SecurityScheme
+	Tags            []string
+	Examples        []Example
+	Conditional     bool
+	Limited         bool     // Route has a concurrency limit and may answer 503
+	CacheControl    string   // Cache-Control header of successful responses, see WithCacheControl
+	ContentTypes    []string // Accepted request body media types, see AcceptContentTypes
+	CSRFHeader      string   // Header carrying the CSRF token, see NewCSRFProtection
+	Extensions      Extensions
+	ResponseHeaders reflect.Type        // Headers struct of a WithHeaders response
+	View            ResponseView        // Reduces the documented response type, see WithView
+	Naming          NamingStrategy      // Names the response data fields, see WithNamingStrategy
+	LongPoll        time.Duration       // Maximum wait of a LongPoll handler
+	OperationID     string              // Overrides the generated operationId
+	RequestSchema   *JSONSchema         // Overrides the generated request body schema
+	ResponseSchemas map[int]*JSONSchema // Overrides generated response schemas by status
 }
 
 // Legacy HandlerTypeInfo for backward compatibility
@@ -133,25 +248,30 @@ const (
 )
 
 func OpenAPI(router *gin.Engine, opts *OpenAPIOpts) *OpenAPISpec {
-	spec := generateOpenAPISpec(router, opts)
-
-	// Write to file if specified
-	if opts.OutputFile != "" {
-		var format OutputFormat
-		if strings.Contains(opts.OutputFile, "json") {
-			format = OutputFormatJSON
-		} else {
-			format = OutputFormatYAML
-		}
+	spec := generateOpenAPISpec(router.Routes(), opts)
+	writeOutputFile(spec, opts.OutputFile)
+	return spec
+}
 
-		if err := writeSwaggerFile(spec, opts.OutputFile, format); err != nil {
-			fmt.Printf("Error writing swagger file: %v\n", err)
-		} else {
-			fmt.Printf("Swagger specification written to %s\n", opts.OutputFile)
-		}
+// writeOutputFile writes the spec to filename, if specified
+func writeOutputFile(spec *OpenAPISpec, filename string) {
+	if filename == "" {
+		return
+	}
+	if err := spec.WriteFile(filename); err != nil {
+		fmt.Printf("Error writing swagger file: %v\n", err)
+	} else {
+		fmt.Printf("Swagger specification written to %s\n", filename)
 	}
+}
 
-	return spec
+// WriteFile writes the spec as JSON when the file name contains "json", YAML otherwise
+func (o *OpenAPISpec) WriteFile(filename string) error {
+	format := OutputFormatYAML
+	if strings.Contains(filename, "json") {
+		format = OutputFormatJSON
+	}
+	return writeSwaggerFile(o, filename, format)
 }
 
 func (o *OpenAPISpec) toJSON() string {
@@ -163,22 +283,33 @@ func (o *OpenAPISpec) toJSON() string {
 }
 
 func (o *OpenAPISpec) toYAML() string {
-	yaml, err := yaml.Marshal(o)
+	yaml, err := marshalYAML(o)
 	if err != nil {
 		return ""
 	}
 	return string(yaml)
 }
 
+// HandleGetSwagger serves the spec as JSON when the path contains "json",
+// YAML otherwise. Each format is serialized on the first request and served
+// from memory with an ETag afterwards, answering If-None-Match with 304.
+// Specs listing the request's server are serialized per request.
 func (o *OpenAPISpec) HandleGetSwagger(c *gin.Context) {
+	recordServedSpec(o)
+
+	format, contentType := OutputFormatYAML, "text/vnd.yaml"
 	if strings.Contains(c.Request.URL.Path, "json") {
-		c.Data(200, "application/json", []byte(o.toJSON()))
-	} else {
-		c.Data(200, "text/vnd.yaml", []byte(o.toYAML()))
+		format, contentType = OutputFormatJSON, "application/json"
 	}
+
+	if o.requestServer {
+		serveSnapshot(c, newSpecSnapshot(o.withRequestServer(c), format), contentType)
+		return
+	}
+	serveSnapshot(c, o.snapshot(format), contentType)
 }
 
-func generateOpenAPISpec(router *gin.Engine, opts *OpenAPIOpts) *OpenAPISpec {
+func generateOpenAPISpec(routes gin.RoutesInfo, opts *OpenAPIOpts) *OpenAPISpec {
 	spec := &OpenAPISpec{
 		OpenAPI: "3.1.1",
 		Info: Info{
@@ -199,12 +330,22 @@ func generateOpenAPISpec(router *gin.Engine, opts *OpenAPIOpts) *OpenAPISpec {
 	if opts.License != "" {
 		spec.Info.License = &License{Name: opts.License}
 	}
+	spec.Info.Extensions = normalizeExtensions(opts.Extensions)
+	if opts.UseBuildInfo {
+		applyBuildInfo(&spec.Info)
+	}
+	spec.Servers = opts.Servers
+	spec.requestServer = opts.RequestServer
 
-	// Get all routes and analyze them
-	routes := router.Routes()
-	handlerInfos := extractHandlerInfos(routes)
+	// Analyze all routes
+	registry := opts.Registry
+	if registry == nil {
+		registry = defaultRegistry
+	}
+	handlerInfos := extractHandlerInfos(registry, routes)
 
 	// Generate paths and schemas
+	explicitOperationIDs := make(map[*Operation]bool)
 	for _, info := range handlerInfos {
 		// Convert Gin path format (:param) to OpenAPI format ({param})
 		openAPIPath := convertGinPathToOpenAPI(info.Path)
@@ -216,6 +357,12 @@ func generateOpenAPISpec(router *gin.Engine, opts *OpenAPIOpts) *OpenAPISpec {
 		}
 
 		operation := generateOperation(info, spec.Components.Schemas, spec.Components.SecuritySchemes)
+		if opts.DocumentFallbacks {
+			addFallbackResponses(operation, spec.Components.Schemas)
+		}
+		if info.OperationID != "" {
+			explicitOperationIDs[operation] = true
+		}
 
 		switch strings.ToUpper(info.Method) {
 		case "GET":
@@ -233,14 +380,22 @@ func generateOpenAPISpec(router *gin.Engine, opts *OpenAPIOpts) *OpenAPISpec {
 		spec.Paths[openAPIPath] = pathItem
 	}
 
+	spec.uniqueOperationIDs(explicitOperationIDs)
+	if opts.ErrorCodes {
+		spec.documentErrorCodes()
+	}
+	spec.Compact()
+	spec.sortParameters()
+	spec.Tags = spec.specTags(registry)
+
 	return spec
 }
 
-func extractHandlerInfos(routes gin.RoutesInfo) []HandlerInfo {
+func extractHandlerInfos(registry *Registry, routes gin.RoutesInfo) []HandlerInfo {
 	var handlerInfos []HandlerInfo
 
 	for _, route := range routes {
-		info := analyzeHandler(route)
+		info := analyzeHandler(registry, route)
 		if info != nil {
 			handlerInfos = append(handlerInfos, *info)
 		}
@@ -249,9 +404,9 @@ func extractHandlerInfos(routes gin.RoutesInfo) []HandlerInfo {
 	return handlerInfos
 }
 
-func analyzeHandler(route gin.RouteInfo) *HandlerInfo {
+func analyzeHandler(registry *Registry, route gin.RouteInfo) *HandlerInfo {
 	// Look up handler type information in the typed handlers registry
-	typedHandler, exists := GetTypedHandler(route.Method, route.Path)
+	typedHandler, exists := registry.GetTypedHandler(route.Method, route.Path)
 
 	if !exists {
 		// If handler is not registered, skip this route
@@ -259,7 +414,20 @@ func analyzeHandler(route gin.RouteInfo) *HandlerInfo {
 	}
 
 	// Get security schemes for this route
-	securitySchemes := GetSecuritySchemes(route.Method, route.Path)
+	securitySchemes := registry.GetSecuritySchemes(route.Method, route.Path)
+	_, limited := registry.GetConcurrencyLimit(route.Method, route.Path)
+	var cacheControl string
+	if cc, exists := registry.GetCacheControl(route.Method, route.Path); exists {
+		cacheControl = cc.value
+	}
+	var contentTypes []string
+	if ct, exists := registry.GetContentTypes(route.Method, route.Path); exists {
+		contentTypes = ct.types
+	}
+	var csrfHeader string
+	if csrf, exists := registry.GetCSRFProtection(route.Method, route.Path); exists {
+		csrfHeader = csrf.config.HeaderName
+	}
 
 	return &HandlerInfo{
 		SchemaType:      typedHandler.GetSchemaType(),
@@ -267,13 +435,33 @@ func analyzeHandler(route gin.RouteInfo) *HandlerInfo {
 		Method:          route.Method,
 		Path:            route.Path,
 		SecuritySchemes: securitySchemes,
+		Tags:            registry.GetRouteTags(route.Method, route.Path),
+		Examples:        typedHandler.GetExamples(),
+		Conditional:     typedHandler.IsConditional(),
+		ResponseHeaders: typedHandler.GetResponseHeaders(),
+		Limited:         limited,
+		CacheControl:    cacheControl,
+		ContentTypes:    contentTypes,
+		CSRFHeader:      csrfHeader,
+		Extensions:      typedHandler.GetExtensions(),
+		View:            typedHandler.GetView(),
+		Naming:          typedHandler.GetNamingStrategy(),
+		LongPoll:        typedHandler.GetLongPollTimeout(),
+		OperationID:     typedHandler.GetOperationID(),
+		RequestSchema:   typedHandler.GetRequestSchemaOverride(),
+		ResponseSchemas: typedHandler.GetResponseSchemaOverrides(),
 	}
 }
 
 func generateOperation(info HandlerInfo, schemas map[string]*JSONSchema, securitySchemes map[string]map[string]interface{}) *Operation {
 	operation := &Operation{
-		Summary:   generateSummary(info.Method, info.Path),
-		Responses: make(map[string]Response),
+		OperationID: info.OperationID,
+		Summary:     generateSummary(info.Method, info.Path),
+		Responses:   make(map[string]Response),
+		Tags:        info.Tags,
+	}
+	if operation.OperationID == "" {
+		operation.OperationID = generateOperationID(info.Method, info.Path)
 	}
 
 	// Add security schemes to components and operation
@@ -319,25 +507,125 @@ func generateOperation(info HandlerInfo, schemas map[string]*JSONSchema, securit
 		operation.Security = security
 	}
 
-	// Generate parameters from schema
-	if info.SchemaType != nil {
-		parameters := extractParameters(info.SchemaType, schemas)
-		operation.Parameters = parameters
+	// Generate parameters, request body and response data from the handler types
+	var data *JSONSchema
+	operation.Parameters, operation.RequestBody, data = reflectTypeDoc(info.SchemaType, info.ResponseType, info.Method, info.Naming, schemas)
 
-		// Check for request body
-		if requestBody := extractRequestBody(info.SchemaType, schemas); requestBody != nil {
-			operation.RequestBody = requestBody
-		}
-	}
+	documentWildcardParam(operation, info.Path)
 
 	// Generate responses
-	operation.Responses["200"] = generateSuccessResponse(info.ResponseType, schemas)
+	if rowType, ok := isTabularType(info.ResponseType); ok {
+		operation.Responses["200"] = generateTabularResponse(rowType)
+	} else {
+		operation.Responses["200"] = generateSuccessResponse(data)
+		if info.View != nil {
+			applyViewSchema(operation.Responses["200"], info.ResponseType, info.View, info.Naming, schemas)
+		}
+	}
 	operation.Responses["400"] = generateErrorResponse(schemas)
+	if info.SchemaType != nil && validationStatus != http.StatusBadRequest {
+		invalid := generateErrorResponse(schemas)
+		invalid.Description = "Validation failed"
+		operation.Responses[strconv.Itoa(validationStatus)] = invalid
+	}
+
+	if info.Conditional {
+		addConditionalGet(operation)
+	}
+
+	if info.ResponseHeaders != nil {
+		addResponseHeaders(operation, info.ResponseHeaders, schemas)
+	}
+
+	if info.LongPoll > 0 {
+		operation.Responses["204"] = Response{
+			Description: fmt.Sprintf("No data within %s, poll again", info.LongPoll),
+		}
+	}
+
+	if info.Limited {
+		unavailable := generateErrorResponse(schemas)
+		unavailable.Description = "Too many concurrent requests"
+		operation.Responses["503"] = unavailable
+	}
+
+	if info.CacheControl != "" {
+		addCacheControl(operation, info.CacheControl)
+	}
+
+	if info.CSRFHeader != "" {
+		addCSRFHeader(operation, info.Method, info.CSRFHeader, schemas)
+	}
+
+	applySchemaOverrides(operation, info.RequestSchema, info.ResponseSchemas)
+
+	if len(info.ContentTypes) > 0 {
+		addContentTypes(operation, info.ContentTypes, schemas)
+	}
+
+	addExamples(operation, info.Examples)
+	operation.Extensions = mergeExtensions(operation.Extensions, info.Extensions)
 
 	return operation
 }
 
-func extractParameters(schemaType reflect.Type, schemas map[string]*JSONSchema) []Parameter {
+// addConditionalGet documents Last-Modified / If-Modified-Since handling
+func addConditionalGet(operation *Operation) {
+	httpDate := newJSONSchema("string", nil)
+	httpDate.Format = "http-date"
+
+	operation.Parameters = append(operation.Parameters, Parameter{
+		Name:        "If-Modified-Since",
+		In:          "header",
+		Description: "Return 304 Not Modified if the resource has not changed since this date",
+		Schema:      httpDate,
+	})
+
+	response := operation.Responses["200"]
+	if response.Headers == nil {
+		response.Headers = make(map[string]Header)
+	}
+	response.Headers["Last-Modified"] = Header{
+		Description: "Time the resource was last modified",
+		Schema:      httpDate,
+	}
+	operation.Responses["200"] = response
+
+	operation.Responses["304"] = Response{
+		Description: "Not Modified",
+	}
+}
+
+// addExamples adds registered examples to the request body and success response
+func addExamples(operation *Operation, examples []Example) {
+	for _, example := range examples {
+		if example.Request != nil && operation.RequestBody != nil {
+			addMediaTypeExample(operation.RequestBody.Content, example, example.Request)
+		}
+
+		if example.Response != nil {
+			if response, ok := operation.Responses["200"]; ok && response.Content != nil {
+				addMediaTypeExample(response.Content, example, globalWrapper.WrapSuccess(example.Response))
+			}
+		}
+	}
+}
+
+func addMediaTypeExample(content map[string]MediaType, example Example, value interface{}) {
+	for contentType, mediaType := range content {
+		if mediaType.Examples == nil {
+			mediaType.Examples = make(map[string]ExampleObject)
+		}
+		mediaType.Examples[example.Name] = ExampleObject{
+			Summary:     example.Summary,
+			Description: example.Description,
+			Value:       value,
+		}
+		content[contentType] = mediaType
+	}
+}
+
+func extractParameters(schemaType reflect.Type, method string, schemas map[string]*JSONSchema) []Parameter {
 	var parameters []Parameter
 
 	// Handle pointers
@@ -355,57 +643,77 @@ func extractParameters(schemaType reflect.Type, schemas map[string]*JSONSchema)
 		field := schemaType.Field(i)
 		fieldName := strings.ToLower(field.Name)
 
-		switch fieldName {
-		case "params":
-			// Extract path parameters
-			pathParams := extractPathParameters(field.Type, schemas)
-			parameters = append(parameters, pathParams...)
-		case "query":
-			// Extract query parameters
-			queryParams := extractQueryParameters(field.Type, schemas)
-			parameters = append(parameters, queryParams...)
-		default:
-			// Check if this field has query tags - treat it as a query parameter
-			if queryTag := getTagValue(field, "query"); queryTag != "" {
-				paramName := queryTag
-
-				jsonSchema := generateJSONSchemaFromType(field.Type, schemas)
+		if isSectionField(field) {
+			switch fieldName {
+			case "params":
+				// Extract path parameters
+				pathParams := extractPathParameters(field.Type, schemas)
+				parameters = append(parameters, pathParams...)
+			case "query":
+				// Extract query parameters
+				queryParams := extractQueryParameters(field.Type, schemas)
+				parameters = append(parameters, queryParams...)
+			case "headers":
+				// Extract header parameters
+				headerParams := extractHeaderParameters(field.Type, schemas)
+				parameters = append(parameters, headerParams...)
+			case "cookies":
+				// Extract cookie parameters
+				cookieParams := extractCookieParameters(field.Type, schemas)
+				parameters = append(parameters, cookieParams...)
+			}
+			continue
+		}
 
-				// Check if parameter has a default value
-				if defaultVal := getTagValue(field, "default"); defaultVal != "" {
-					jsonSchema.Default = parseDefaultValue(defaultVal, field.Type)
-				}
+		// Top-level fields are query parameters unless they are headers, cookies or belong in the body
+		switch topLevelFieldLocation(field, method) {
+		case fieldLocationHeader:
+			parameters = append(parameters, headerParameter(field, schemas))
+			continue
+		case fieldLocationCookie:
+			parameters = append(parameters, cookieParameter(field, schemas))
+			continue
+		case fieldLocationQuery:
+		default:
+			continue
+		}
 
-				parameters = append(parameters, Parameter{
-					Name:     paramName,
-					In:       "query",
-					Required: isRequired(field),
-					Schema:   jsonSchema,
-				})
-			} else if isQueryParameter(field) {
-				// Auto-detect query parameters based on field characteristics
-				paramName := getQueryParameterName(field)
-
-				jsonSchema := generateJSONSchemaFromType(field.Type, schemas)
-
-				// Check if parameter has a default value
-				if defaultVal := getTagValue(field, "default"); defaultVal != "" {
-					jsonSchema.Default = parseDefaultValue(defaultVal, field.Type)
-				}
+		jsonSchema := generateJSONSchemaFromType(field.Type, schemas)
 
-				parameters = append(parameters, Parameter{
-					Name:     paramName,
-					In:       "query",
-					Required: isRequired(field),
-					Schema:   jsonSchema,
-				})
-			}
+		// Check if parameter has a default value
+		if defaultVal := getTagValue(field, "default"); defaultVal != "" {
+			jsonSchema.Default = parseDefaultValue(defaultVal, field.Type)
 		}
+
+		parameters = append(parameters, queryParameter(getQueryParameterName(field), field, jsonSchema))
 	}
 
 	return parameters
 }
 
+// queryParameter documents a query field, using the deepObject style for
+// structs and maps so clients send filter[status]=active
+func queryParameter(name string, field reflect.StructField, jsonSchema *JSONSchema) Parameter {
+	parameter := Parameter{
+		Name:       name,
+		In:         "query",
+		Required:   isRequired(field),
+		Schema:     jsonSchema,
+		Extensions: parseExtensionTag(field),
+	}
+
+	explode := true
+	switch {
+	case isDeepObjectType(field.Type):
+		parameter.Style = "deepObject"
+		parameter.Explode = &explode
+	case isQueryArrayType(field.Type):
+		parameter.Style = "form"
+		parameter.Explode = &explode
+	}
+	return parameter
+}
+
 func extractPathParameters(paramType reflect.Type, schemas map[string]*JSONSchema) []Parameter {
 	var parameters []Parameter
 
@@ -430,10 +738,11 @@ func extractPathParameters(paramType reflect.Type, schemas map[string]*JSONSchem
 		jsonSchema := generateJSONSchemaFromType(field.Type, schemas)
 
 		parameters = append(parameters, Parameter{
-			Name:     paramName,
-			In:       "path",
-			Required: true, // Path parameters are always required
-			Schema:   jsonSchema,
+			Name:       paramName,
+			In:         "path",
+			Required:   true, // Path parameters are always required
+			Schema:     jsonSchema,
+			Extensions: parseExtensionTag(field),
 		})
 	}
 
@@ -468,18 +777,13 @@ func extractQueryParameters(queryType reflect.Type, schemas map[string]*JSONSche
 			jsonSchema.Default = parseDefaultValue(defaultVal, field.Type)
 		}
 
-		parameters = append(parameters, Parameter{
-			Name:     paramName,
-			In:       "query",
-			Required: isRequired(field),
-			Schema:   jsonSchema,
-		})
+		parameters = append(parameters, queryParameter(paramName, field, jsonSchema))
 	}
 
 	return parameters
 }
 
-func extractRequestBody(schemaType reflect.Type, schemas map[string]*JSONSchema) *RequestBody {
+func extractRequestBody(schemaType reflect.Type, method string, schemas map[string]*JSONSchema) *RequestBody {
 	// Handle pointers
 	if schemaType.Kind() == reflect.Ptr {
 		schemaType = schemaType.Elem()
@@ -493,26 +797,72 @@ func extractRequestBody(schemaType reflect.Type, schemas map[string]*JSONSchema)
 	// Look for a "Body" field in the schema
 	for i := 0; i < schemaType.NumField(); i++ {
 		field := schemaType.Field(i)
-		if strings.ToLower(field.Name) == "body" {
+		if strings.ToLower(field.Name) == "body" && isSectionField(field) {
 			jsonSchema := generateJSONSchemaFromType(field.Type, schemas)
 
-			return &RequestBody{
+			requestBody := &RequestBody{
 				Description: "Request body",
 				Content: map[string]MediaType{
 					"application/json": {
 						Schema: jsonSchema,
 					},
 				},
-				Required: hasRequiredFields(field.Type),
+				Required: hasRequiredFields(field.Type) || isStreamBodyType(field.Type),
 			}
+			// Bodies with `form` tags are also bound from URL-encoded forms
+			if hasFormTags(field.Type) {
+				requestBody.Content[formMediaType] = MediaType{Schema: formBodySchema(field.Type, schemas)}
+			}
+			return requestBody
 		}
 	}
 
-	return nil
+	// Otherwise build the body from top-level fields that belong in it
+	properties := make(map[string]*JSONSchema)
+	var required, order []string
+	for i := 0; i < schemaType.NumField(); i++ {
+		field := schemaType.Field(i)
+		if isSectionField(field) || topLevelFieldLocation(field, method) != fieldLocationBody {
+			continue
+		}
+
+		jsonName := getJSONFieldName(field)
+		fieldSchema := generateJSONSchemaFromType(field.Type, schemas)
+		addValidationConstraints(fieldSchema, field)
+		fieldSchema.Extensions = mergeExtensions(fieldSchema.Extensions, parseExtensionTag(field))
+		if defaultVal := getTagValue(field, "default"); defaultVal != "" {
+			fieldSchema.Default = parseDefaultValue(defaultVal, field.Type)
+		}
+		properties[jsonName] = fieldSchema
+		order = append(order, jsonName)
+
+		if isRequired(field) {
+			required = append(required, jsonName)
+		}
+	}
+
+	if len(properties) == 0 {
+		return nil
+	}
+
+	bodySchema := newJSONSchema("object", properties)
+	bodySchema.Required = required
+	bodySchema.propertyOrder = order
+
+	return &RequestBody{
+		Description: "Request body",
+		Content: map[string]MediaType{
+			"application/json": {
+				Schema: bodySchema,
+			},
+		},
+		Required: len(required) > 0,
+	}
 }
 
-func generateSuccessResponse(responseType reflect.Type, schemas map[string]*JSONSchema) Response {
-	if responseType == nil {
+// generateSuccessResponse wraps the schema of the response data, nil for handlers without one
+func generateSuccessResponse(data *JSONSchema) Response {
+	if data == nil {
 		return Response{
 			Description: "Success",
 		}
@@ -524,7 +874,7 @@ func generateSuccessResponse(responseType reflect.Type, schemas map[string]*JSON
 			Type:    "boolean",
 			Default: true,
 		},
-		"data": generateJSONSchemaFromType(responseType, schemas),
+		"data": data,
 		"error": {
 			Type:    "null",
 			Default: nil,
@@ -533,6 +883,7 @@ func generateSuccessResponse(responseType reflect.Type, schemas map[string]*JSON
 
 	successSchema := newJSONSchema("object", properties)
 	successSchema.Required = []string{"success", "data", "error"}
+	successSchema.propertyOrder = successSchema.Required
 
 	return Response{
 		Description: "Success",
@@ -544,6 +895,33 @@ func generateSuccessResponse(responseType reflect.Type, schemas map[string]*JSON
 	}
 }
 
+// generateTabularResponse documents a Tabular export as a CSV or XLSX download
+func generateTabularResponse(rowType reflect.Type) Response {
+	columns := tabularColumns(rowType)
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+	}
+
+	return Response{
+		Description: "Export with columns: " + strings.Join(names, ", "),
+		Headers: map[string]Header{
+			"Content-Disposition": {
+				Description: "attachment; filename of the export",
+				Schema:      &JSONSchema{Type: "string"},
+			},
+		},
+		Content: map[string]MediaType{
+			csvContentType: {
+				Schema: &JSONSchema{Type: "string"},
+			},
+			xlsxContentType: {
+				Schema: &JSONSchema{Type: "string", Format: "binary"},
+			},
+		},
+	}
+}
+
 func generateErrorResponse(schemas map[string]*JSONSchema) Response {
 	// Generate schema for error object
 	errorObjProperties := map[string]*JSONSchema{
@@ -556,6 +934,7 @@ func generateErrorResponse(schemas map[string]*JSONSchema) Response {
 	}
 	errorObj := newJSONSchema("object", errorObjProperties)
 	errorObj.Required = []string{"code", "message"}
+	errorObj.propertyOrder = errorObj.Required
 
 	// Generate schema for error result wrapper
 	properties := map[string]*JSONSchema{
@@ -572,6 +951,7 @@ func generateErrorResponse(schemas map[string]*JSONSchema) Response {
 
 	errorSchema := newJSONSchema("object", properties)
 	errorSchema.Required = []string{"success", "error", "data"}
+	errorSchema.propertyOrder = errorSchema.Required
 
 	return Response{
 		Description: "Error",
@@ -584,15 +964,28 @@ func generateErrorResponse(schemas map[string]*JSONSchema) Response {
 }
 
 func generateJSONSchemaFromType(t reflect.Type, schemas map[string]*JSONSchema) *JSONSchema {
-	return generateJSONSchemaFromTypeWithContext(t, schemas, "")
+	return generateJSONSchemaFromTypeWithContext(t, schemas, "", globalNamingStrategy)
 }
 
-func generateJSONSchemaFromTypeWithContext(t reflect.Type, schemas map[string]*JSONSchema, contextName string) *JSONSchema {
+// generateJSONSchemaWithNaming documents t with field names under naming,
+// in component schema variants when it isn't the global strategy
+func generateJSONSchemaWithNaming(t reflect.Type, schemas map[string]*JSONSchema, naming NamingStrategy) *JSONSchema {
+	return generateJSONSchemaFromTypeWithContext(t, schemas, "", naming)
+}
+
+func generateJSONSchemaFromTypeWithContext(t reflect.Type, schemas map[string]*JSONSchema, contextName string, naming NamingStrategy) *JSONSchema {
 	// Handle pointers
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
+	// StreamBody is documented as the array it reads
+	if elementType, ok := isStreamBody(t); ok {
+		schema := newJSONSchema("array", nil)
+		schema.Items = generateJSONSchemaFromTypeWithContext(elementType, schemas, contextName+"Item", naming)
+		return schema
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		return newJSONSchema("string", nil)
@@ -608,10 +1001,10 @@ func generateJSONSchemaFromTypeWithContext(t reflect.Type, schemas map[string]*J
 		return newJSONSchema("boolean", nil)
 	case reflect.Slice, reflect.Array:
 		schema := newJSONSchema("array", nil)
-		schema.Items = generateJSONSchemaFromTypeWithContext(t.Elem(), schemas, contextName+"Item")
+		schema.Items = generateJSONSchemaFromTypeWithContext(t.Elem(), schemas, contextName+"Item", naming)
 		return schema
 	case reflect.Struct:
-		return generateStructSchemaWithContext(t, schemas, contextName)
+		return generateStructSchemaWithContext(t, schemas, contextName, naming)
 	default:
 		return newJSONSchema("object", nil)
 	}
@@ -629,10 +1022,10 @@ func newJSONSchema(schemaType string, properties map[string]*JSONSchema) *JSONSc
 }
 
 func generateStructSchema(t reflect.Type, schemas map[string]*JSONSchema) *JSONSchema {
-	return generateStructSchemaWithContext(t, schemas, "")
+	return generateStructSchemaWithContext(t, schemas, "", globalNamingStrategy)
 }
 
-func generateStructSchemaWithContext(t reflect.Type, schemas map[string]*JSONSchema, contextName string) *JSONSchema {
+func generateStructSchemaWithContext(t reflect.Type, schemas map[string]*JSONSchema, contextName string, naming NamingStrategy) *JSONSchema {
 	// Handle pointers
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -650,8 +1043,10 @@ func generateStructSchemaWithContext(t reflect.Type, schemas map[string]*JSONSch
 			// Use context name for anonymous structs
 			schemaName = contextName
 		} else {
-			schemaName = "AnonymousStruct"
+			schemaName = "AnonymousStruct" + namingVariant(naming)
 		}
+	} else {
+		schemaName += namingVariant(naming)
 	}
 
 	// Check if we already have this schema
@@ -659,9 +1054,13 @@ func generateStructSchemaWithContext(t reflect.Type, schemas map[string]*JSONSch
 		return &JSONSchema{Ref: "#/components/schemas/" + schemaName}
 	}
 
+	// Reserve the name first so recursive types refer back to it
+	schema := &JSONSchema{}
+	schemas[schemaName] = schema
+
 	// Create the schema
 	properties := make(map[string]*JSONSchema)
-	var required []string
+	var required, order []string
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
@@ -682,35 +1081,36 @@ func generateStructSchemaWithContext(t reflect.Type, schemas map[string]*JSONSch
 		if field.Type.Kind() == reflect.Struct && field.Type.Name() == "" {
 			// For anonymous structs, create a name based on the parent schema and field name
 			parentName := schemaName
-			if parentName == "AnonymousStruct" {
+			if parentName == "AnonymousStruct"+namingVariant(naming) {
 				parentName = contextName
 			}
 			// Capitalize the first letter of the field name for proper schema naming
 			capitalizedJsonName := strings.ToUpper(jsonName[:1]) + jsonName[1:]
 			fieldContextName = parentName + capitalizedJsonName
 		}
-		fieldSchema := generateJSONSchemaFromTypeWithContext(field.Type, schemas, fieldContextName)
+		fieldSchema := generateJSONSchemaFromTypeWithContext(field.Type, schemas, fieldContextName, naming)
 
 		// Add validation constraints from tags
 		addValidationConstraints(fieldSchema, field)
+		fieldSchema.Extensions = mergeExtensions(fieldSchema.Extensions, parseExtensionTag(field))
 
-		properties[jsonName] = fieldSchema
+		propertyName := namedFieldName(field, naming)
+		properties[propertyName] = fieldSchema
+		order = append(order, propertyName)
 
 		// Check if field is required
 		if isRequired(field) {
-			required = append(required, jsonName)
+			required = append(required, propertyName)
 		}
 	}
 
-	// Create the schema with only necessary fields
-	schema := newJSONSchema("object", properties)
+	// Fill in the reserved schema with only necessary fields
+	*schema = *newJSONSchema("object", properties)
+	schema.propertyOrder = order
 	if len(required) > 0 {
 		schema.Required = required
 	}
 
-	// Store the schema in components
-	schemas[schemaName] = schema
-
 	// Return a reference to the schema
 	return &JSONSchema{Ref: "#/components/schemas/" + schemaName}
 }
@@ -735,36 +1135,160 @@ func addValidationConstraints(schema *JSONSchema, field reflect.StructField) {
 		return
 	}
 
-	// Parse validation constraints
-	constraints := strings.Split(validateTag, ",")
-	for _, constraint := range constraints {
-		constraint = strings.TrimSpace(constraint)
+	applyValidationRules(schema, strings.Split(validateTag, ","))
+}
 
-		if strings.HasPrefix(constraint, "min=") {
-			if val, err := strconv.ParseFloat(constraint[4:], 64); err == nil {
-				if schema.Type == "string" {
-					schema.MinLength = intPtr(int(val))
-				} else {
-					schema.Minimum = &val
-				}
+// applyValidationRules translates validator rules to schema constraints. Rules
+// after "dive" apply to the items of an array, and map key rules between
+// "keys" and "endkeys" are skipped.
+func applyValidationRules(schema *JSONSchema, rules []string) {
+	for i := 0; i < len(rules); i++ {
+		rule := strings.TrimSpace(rules[i])
+
+		switch rule {
+		case "dive":
+			if schema.Items != nil {
+				applyValidationRules(schema.Items, rules[i+1:])
 			}
-		} else if strings.HasPrefix(constraint, "max=") {
-			if val, err := strconv.ParseFloat(constraint[4:], 64); err == nil {
-				if schema.Type == "string" {
-					schema.MaxLength = intPtr(int(val))
-				} else {
-					schema.Maximum = &val
-				}
+			return
+		case "keys":
+			for i < len(rules) && strings.TrimSpace(rules[i]) != "endkeys" {
+				i++
 			}
-		} else if constraint == "email" {
-			schema.Format = "email"
+			continue
 		}
+
+		name, param, _ := strings.Cut(rule, "=")
+		applyValidationRule(schema, name, param)
 	}
 }
 
+// validationFormats maps validator rules to OpenAPI string formats
+var validationFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+	"datetime": "date-time",
+}
+
+func applyValidationRule(schema *JSONSchema, name, param string) {
+	if format, ok := validationFormats[name]; ok {
+		schema.Format = format
+		return
+	}
+
+	switch name {
+	case "unique":
+		if schema.Type == "array" {
+			schema.UniqueItems = true
+		}
+		return
+	case "regexp":
+		// validator escapes commas and pipes in parameters
+		schema.Pattern = strings.NewReplacer("0x2C", ",", "0x7C", "|").Replace(param)
+		return
+	case "oneof":
+		schema.Enum = nil
+		for _, value := range strings.Fields(param) {
+			schema.Enum = append(schema.Enum, parseEnumValue(value, schema.Type))
+		}
+		return
+	}
+
+	val, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	// Strings, arrays and maps are constrained by length, numbers by value
+	switch name {
+	case "min", "gte":
+		setLowerBound(schema, val, false)
+	case "max", "lte":
+		setUpperBound(schema, val, false)
+	case "gt":
+		setLowerBound(schema, val, true)
+	case "lt":
+		setUpperBound(schema, val, true)
+	case "len", "eq":
+		setLowerBound(schema, val, false)
+		setUpperBound(schema, val, false)
+	}
+}
+
+func setLowerBound(schema *JSONSchema, val float64, exclusive bool) {
+	length := int(val)
+	if exclusive {
+		length++
+	}
+
+	switch schema.Type {
+	case "string":
+		schema.MinLength = intPtr(length)
+	case "array":
+		schema.MinItems = intPtr(length)
+	case "object":
+		schema.MinProperties = intPtr(length)
+	default:
+		if exclusive {
+			schema.ExclusiveMinimum = &val
+		} else {
+			schema.Minimum = &val
+		}
+	}
+}
+
+func setUpperBound(schema *JSONSchema, val float64, exclusive bool) {
+	length := int(val)
+	if exclusive {
+		length--
+	}
+
+	switch schema.Type {
+	case "string":
+		schema.MaxLength = intPtr(length)
+	case "array":
+		schema.MaxItems = intPtr(length)
+	case "object":
+		schema.MaxProperties = intPtr(length)
+	default:
+		if exclusive {
+			schema.ExclusiveMaximum = &val
+		} else {
+			schema.Maximum = &val
+		}
+	}
+}
+
+// parseEnumValue converts a oneof value to the JSON type of a schema
+func parseEnumValue(value, schemaType string) interface{} {
+	switch schemaType {
+	case "integer":
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+	case "number":
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	case "boolean":
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return value
+}
+
+var summaryParamPattern = regexp.MustCompile(`[:*{][^/}]+[}]?`)
+
 func generateSummary(method, path string) string {
 	// Convert path parameters to readable format (handle both :param and {param} formats)
-	readablePath := regexp.MustCompile(`[:{][^/}]+[}]?`).ReplaceAllString(path, "by ID")
+	readablePath := summaryParamPattern.ReplaceAllString(path, "by ID")
 
 	switch strings.ToUpper(method) {
 	case "GET":
@@ -808,7 +1332,7 @@ func writeSwaggerFile(spec *OpenAPISpec, filename string, format OutputFormat) e
 	if format == OutputFormatJSON {
 		data, err = json.MarshalIndent(spec, "", "  ")
 	} else {
-		data, err = yaml.Marshal(spec)
+		data, err = marshalYAML(spec)
 	}
 	if err != nil {
 		return fmt.Errorf("failed to marshal OpenAPI spec: %w", err)
@@ -882,9 +1406,46 @@ func getQueryParameterName(field reflect.StructField) string {
 	return strings.ToLower(field.Name)
 }
 
-// convertGinPathToOpenAPI converts Gin path format (:param) to OpenAPI format ({param})
+// convertGinPathToOpenAPI converts Gin path format (:param and *wildcard) to OpenAPI format ({param})
+var ginParamPattern = regexp.MustCompile(`[:*]([^/]+)`)
+
 func convertGinPathToOpenAPI(ginPath string) string {
-	// Use regex to replace :param with {param}
-	re := regexp.MustCompile(`:([^/]+)`)
-	return re.ReplaceAllString(ginPath, "{$1}")
+	// Use regex to replace :param and *wildcard with {param}
+	return ginParamPattern.ReplaceAllString(ginPath, "{$1}")
+}
+
+// wildcardParamName returns the name of the catch-all parameter of a Gin path, if any
+func wildcardParamName(ginPath string) string {
+	idx := strings.LastIndex(ginPath, "/*")
+	if idx == -1 {
+		return ""
+	}
+	return ginPath[idx+2:]
+}
+
+// documentWildcardParam makes sure the catch-all parameter of a route is
+// documented, since OpenAPI has no native representation for it
+func documentWildcardParam(operation *Operation, ginPath string) {
+	name := wildcardParamName(ginPath)
+	if name == "" {
+		return
+	}
+
+	description := "Wildcard path segment, may contain '/'"
+	for i := range operation.Parameters {
+		if operation.Parameters[i].In == "path" && operation.Parameters[i].Name == name {
+			if operation.Parameters[i].Description == "" {
+				operation.Parameters[i].Description = description
+			}
+			return
+		}
+	}
+
+	operation.Parameters = append(operation.Parameters, Parameter{
+		Name:        name,
+		In:          "path",
+		Description: description,
+		Required:    true,
+		Schema:      newJSONSchema("string", nil),
+	})
 }
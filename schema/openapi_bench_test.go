@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Spec generation for a 5k-route router. Routes share their types, as in
+// large CRUD APIs, so after the first generation the reflected types come
+// from typeDocCache:
+//
+//	go test -run '^$' -bench OpenAPI -benchmem
+type benchAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip"`
+}
+
+type benchRequest struct {
+	Params struct {
+		ID string `param:"id"`
+	}
+	Query struct {
+		Search string `query:"search"`
+		Page   int    `query:"page" validate:"min=1"`
+	}
+	Body struct {
+		Name    string       `json:"name" validate:"required,min=2"`
+		Address benchAddress `json:"address"`
+	}
+}
+
+type benchResponse struct {
+	ID        string         `json:"id"`
+	Addresses []benchAddress `json:"addresses"`
+}
+
+const benchRoutes = 5000
+
+func benchRouter(b *testing.B) *RouterHelper {
+	b.Helper()
+	gin.SetMode(gin.ReleaseMode)
+
+	handler := ValidateAndHandle(func(c *gin.Context, req benchRequest) (*benchResponse, error) {
+		return &benchResponse{}, nil
+	})
+	router := WrapRouter(gin.New()).WithRegistry(NewRegistry())
+	for i := 0; i < benchRoutes; i++ {
+		router.POST(fmt.Sprintf("/resources%d/:id", i), handler)
+	}
+	return router
+}
+
+func BenchmarkOpenAPI(b *testing.B) {
+	router := benchRouter(b)
+	opts := &OpenAPIOpts{Title: "Bench", Registry: router.Registry()}
+	OpenAPI(router.Engine, opts)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		OpenAPI(router.Engine, opts)
+	}
+}
+
+func BenchmarkOpenAPIUncached(b *testing.B) {
+	router := benchRouter(b)
+	opts := &OpenAPIOpts{Title: "Bench", Registry: router.Registry()}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		typeDocCacheMu.Lock()
+		typeDocCache = make(map[typeDocKey]*typeDoc)
+		typeDocCacheMu.Unlock()
+
+		OpenAPI(router.Engine, opts)
+	}
+}
+
+func BenchmarkCachedSpec(b *testing.B) {
+	router := benchRouter(b)
+	docs := NewCachedSpec(router.Engine, &OpenAPIOpts{Title: "Bench", Registry: router.Registry()})
+	docs.Pregenerate()
+	docs.Spec()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		docs.Spec()
+	}
+}
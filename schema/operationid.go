@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WithOperationID returns a copy of the handler with a fixed operationId, for
+// keeping the id of a route whose path changes
+func (t TypedHandlerFunc) WithOperationID(id string) TypedHandlerFunc {
+	t.operationID = id
+	return t
+}
+
+// GetOperationID returns the operationId set with WithOperationID
+func (t TypedHandlerFunc) GetOperationID() string {
+	return t.operationID
+}
+
+// generateOperationID derives an operationId from the method and route
+// template only, so it stays the same across generations:
+// GET /users/:id/orders gives getUsersByIdOrders
+func generateOperationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+
+		prefix := ""
+		switch {
+		case strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*"):
+			prefix, segment = "By", segment[1:]
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			prefix, segment = "By", segment[1:len(segment)-1]
+		}
+
+		b.WriteString(prefix)
+		for _, word := range splitWords(segment) {
+			word = strings.Map(func(r rune) rune {
+				if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+					return r
+				}
+				return -1
+			}, word)
+			if word == "" {
+				continue
+			}
+			b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+		}
+	}
+
+	return b.String()
+}
+
+// uniqueOperationIDs suffixes generated operationIds that collide, e.g.
+// /users-list and /users/list, with 2, 3, ... in path order. Ids set with
+// WithOperationID are left alone.
+func (o *OpenAPISpec) uniqueOperationIDs(explicit map[*Operation]bool) {
+	used := make(map[string]bool)
+	for operation := range explicit {
+		used[operation.OperationID] = true
+	}
+
+	for _, entry := range o.operations() {
+		operation := entry.Operation
+		if explicit[operation] {
+			continue
+		}
+
+		id := operation.OperationID
+		for n := 2; used[id]; n++ {
+			id = operation.OperationID + strconv.Itoa(n)
+		}
+		operation.OperationID = id
+		used[id] = true
+	}
+}
@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// OverrideRequestSchema returns a copy of the handler whose request body is
+// documented with schema instead of the one generated from its Go type, for
+// wire contracts the type cannot express. Parameters stay generated.
+func (t TypedHandlerFunc) OverrideRequestSchema(schema *JSONSchema) TypedHandlerFunc {
+	t.requestSchema = schema
+	return t
+}
+
+// OverrideResponseSchema returns a copy of the handler whose JSON response
+// for status is documented with schema. The schema describes the whole body
+// as sent, including the success or error wrapper.
+func (t TypedHandlerFunc) OverrideResponseSchema(status int, schema *JSONSchema) TypedHandlerFunc {
+	overrides := make(map[int]*JSONSchema, len(t.responseSchemas)+1)
+	for code, existing := range t.responseSchemas {
+		overrides[code] = existing
+	}
+	overrides[status] = schema
+	t.responseSchemas = overrides
+	return t
+}
+
+// GetRequestSchemaOverride returns the schema set with OverrideRequestSchema, or nil
+func (t TypedHandlerFunc) GetRequestSchemaOverride() *JSONSchema {
+	return t.requestSchema
+}
+
+// GetResponseSchemaOverrides returns the schemas set with OverrideResponseSchema by status
+func (t TypedHandlerFunc) GetResponseSchemaOverrides() map[int]*JSONSchema {
+	return t.responseSchemas
+}
+
+// applySchemaOverrides replaces generated request and response schemas with
+// the hand-written ones of the handler, keeping descriptions and examples
+func applySchemaOverrides(operation *Operation, requestSchema *JSONSchema, responseSchemas map[int]*JSONSchema) {
+	if requestSchema != nil {
+		if operation.RequestBody == nil {
+			operation.RequestBody = &RequestBody{Description: "Request body", Required: true}
+		}
+		operation.RequestBody.Content = overrideMediaType(operation.RequestBody.Content, requestSchema)
+	}
+
+	for status, schema := range responseSchemas {
+		code := strconv.Itoa(status)
+		response, exists := operation.Responses[code]
+		if !exists {
+			response = Response{Description: http.StatusText(status)}
+		}
+		response.Content = overrideMediaType(response.Content, schema)
+		operation.Responses[code] = response
+	}
+}
+
+// overrideMediaType sets the application/json schema of content
+func overrideMediaType(content map[string]MediaType, schema *JSONSchema) map[string]MediaType {
+	if content == nil {
+		content = make(map[string]MediaType)
+	}
+	mediaType := content["application/json"]
+	mediaType.Schema = schema
+	content["application/json"] = mediaType
+	return content
+}
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// PublicRoute marks a route as public, see Public
+type PublicRoute struct{}
+
+// Public returns a route option that exempts the route from group-level and
+// router-level security (added with RouterGroup.Use or UseSecurity) and omits
+// those requirements from the spec. Security schemes passed to the route
+// itself still apply.
+//
+//	api := router.Group("/api")
+//	api.Use(bearer.Middleware())
+//	api.POST("/login", schema.Public(), schema.ValidateAndHandle(Login))
+func Public() *PublicRoute {
+	return &PublicRoute{}
+}
+
+// RegisterPublicRoute marks a route as public
+func (r *Registry) RegisterPublicRoute(method, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.public[routeKey(method, path)] = true
+}
+
+// IsPublicRoute reports whether a route was registered with Public
+func (r *Registry) IsPublicRoute(method, path string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.public[routeKey(method, path)]
+}
+
+// RegisterPublicRoute marks a route of the global registry as public
+func RegisterPublicRoute(method, path string) {
+	defaultRegistry.RegisterPublicRoute(method, path)
+}
+
+// IsPublicRoute reports whether a route of the global registry was registered with Public
+func IsPublicRoute(method, path string) bool {
+	return defaultRegistry.IsPublicRoute(method, path)
+}
+
+// skipOnPublicRoutes wraps shared security middleware so it lets the public
+// routes of registry through
+func skipOnPublicRoutes(registry *Registry, middleware gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if registry.IsPublicRoute(c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+		middleware(c)
+	}
+}
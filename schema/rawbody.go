@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const rawBodyKey = "schema_raw_body"
+
+// BufferBody returns middleware that reads the request body into memory, up
+// to maxBytes, so several consumers can read it: signature verification,
+// audit logging and the schema binding of ValidateAndHandle all see the same
+// bytes. Read them with RawBody; larger bodies are rejected with a wrapped
+// 413. Only use it on routes with bounded bodies, StreamBody routes lose
+// their streaming.
+//
+//	router.POST("/payments", schema.BufferBody(64<<10), audit, schema.ValidateAndHandle(CreatePayment))
+func BufferBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := bufferBody(c, maxBytes); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respondError(c, http.StatusRequestEntityTooLarge, "ERR_BODY_TOO_LARGE", "Request body too large")
+			} else {
+				respondError(c, http.StatusBadRequest, "ERR_INVALID_BODY", "Failed to read request body")
+			}
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// UseBodyBuffer adds BufferBody to every route of the router
+func (r *RouterHelper) UseBodyBuffer(maxBytes int64) gin.IRoutes {
+	return r.Engine.Use(BufferBody(maxBytes))
+}
+
+// RawBody returns the request body as received, when BufferBody or a
+// middleware using it, such as HMACSignatureSecurity, buffered it
+func RawBody(c *gin.Context) ([]byte, bool) {
+	value, ok := c.Get(rawBodyKey)
+	if !ok {
+		return nil, false
+	}
+	body, ok := value.([]byte)
+	return body, ok
+}
+
+// bufferBody reads the request body up to maxBytes once and returns the
+// buffered bytes on later calls
+func bufferBody(c *gin.Context, maxBytes int64) ([]byte, error) {
+	if body, ok := RawBody(c); ok {
+		rewindBody(c)
+		return body, nil
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.Set(rawBodyKey, body)
+	// ShouldBindBodyWith reuses the buffered bytes
+	c.Set(gin.BodyBytesKey, body)
+	rewindBody(c)
+	return body, nil
+}
+
+// rewindBody resets the request body to the start of the buffered bytes, so
+// the next consumer reading c.Request.Body sees all of it
+func rewindBody(c *gin.Context) {
+	if body, ok := RawBody(c); ok {
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+}
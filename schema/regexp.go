@@ -0,0 +1,28 @@
+package schema
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Compiled patterns of regexp rules, keyed by pattern
+var validationPatterns sync.Map
+
+// validateRegexp implements the regexp=<pattern> rule for string fields. Write
+// commas in the pattern as 0x2C and pipes as 0x7C, as validator splits on them.
+func validateRegexp(fl validator.FieldLevel) bool {
+	pattern := fl.Param()
+
+	compiled, ok := validationPatterns.Load(pattern)
+	if !ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false
+		}
+		compiled, _ = validationPatterns.LoadOrStore(pattern, re)
+	}
+
+	return compiled.(*regexp.Regexp).MatchString(fl.Field().String())
+}
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry stores what routes were registered with for OpenAPI generation:
+// their typed handlers, security schemes and route options. Every
+// RouterHelper writes to one; by default that is the global registry behind
+// the package-level functions such as RegisterTypedHandler and
+// GetSecuritySchemes. Give routers their own registry to keep tests and
+// servers in one process apart:
+//
+//	router := schema.NewRouter().WithRegistry(schema.NewRegistry())
+//	router.GET("/users/:id", schema.ValidateAndHandle(GetUser))
+//
+//	spec := schema.OpenAPI(router.Engine, &schema.OpenAPIOpts{Title: "Users", Registry: router.Registry()})
+type Registry struct {
+	mu              sync.RWMutex
+	handlers        map[string]TypedHandlerFunc
+	securitySchemes map[string][]SecurityScheme
+	public          map[string]bool
+	cacheControl    map[string]*CacheControl
+	concurrency     map[string]*ConcurrencyLimit
+	contentTypes    map[string]*ContentTypes
+	tags            map[string][]string
+	tagDescriptions map[string]string // By tag name
+	csrf            map[string]*CSRFProtection
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		handlers:        make(map[string]TypedHandlerFunc),
+		securitySchemes: make(map[string][]SecurityScheme),
+		public:          make(map[string]bool),
+		cacheControl:    make(map[string]*CacheControl),
+		concurrency:     make(map[string]*ConcurrencyLimit),
+		contentTypes:    make(map[string]*ContentTypes),
+		tags:            make(map[string][]string),
+		tagDescriptions: make(map[string]string),
+		csrf:            make(map[string]*CSRFProtection),
+	}
+}
+
+// Global registry used by the package-level functions and routers without
+// their own registry
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the global registry
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// RegisterTypedHandler stores a typed handler for OpenAPI generation
+func (r *Registry) RegisterTypedHandler(method, path string, handler TypedHandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[routeKey(method, path)] = handler
+}
+
+// GetTypedHandler retrieves a typed handler by method and path
+func (r *Registry) GetTypedHandler(method, path string) (TypedHandlerFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handler, exists := r.handlers[routeKey(method, path)]
+	return handler, exists
+}
+
+// keys returns the sorted route keys of all typed handlers
+func (r *Registry) keys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0, len(r.handlers))
+	for key := range r.handlers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RegisterTypedHandler stores a typed handler in the global registry.
+//
+// Deprecated: Register on the registry of the router instead, with
+// router.Registry().RegisterTypedHandler. The global function keeps working
+// for routers using the default registry.
+func RegisterTypedHandler(method, path string, handler TypedHandlerFunc) {
+	defaultRegistry.RegisterTypedHandler(method, path, handler)
+}
+
+// GetTypedHandler retrieves a typed handler from the global registry.
+//
+// Deprecated: Use router.Registry().GetTypedHandler. The global function
+// keeps working for routers using the default registry.
+func GetTypedHandler(method, path string) (TypedHandlerFunc, bool) {
+	return defaultRegistry.GetTypedHandler(method, path)
+}
@@ -1,6 +1,11 @@
 package schema
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
 
 type Error struct {
 	Code    string `json:"code"`
@@ -11,6 +16,7 @@ type Error struct {
 type SchemaError struct {
 	Code    string
 	Message string
+	Status  int // Response status, 400 when not set
 }
 
 func (e SchemaError) Error() string {
@@ -25,16 +31,94 @@ func NewSchemaError(code, message string) SchemaError {
 	}
 }
 
+// NewSchemaErrorWithStatus creates a new SchemaError answered with status
+// instead of 400:
+//
+//	return nil, schema.NewSchemaErrorWithStatus(http.StatusConflict, "ERR_EMAIL_TAKEN", "Email already registered")
+func NewSchemaErrorWithStatus(status int, code, message string) SchemaError {
+	return SchemaError{
+		Code:    code,
+		Message: message,
+		Status:  status,
+	}
+}
+
+// WithStatus sets the response status, 400 when not set
+func (e SchemaError) WithStatus(status int) SchemaError {
+	e.Status = status
+	return e
+}
+
 type SuccessResult[T any] struct {
 	Success bool        `json:"success" default:"true"`
 	Data    T           `json:"data"`
 	Error   interface{} `json:"error" default:"null"`
+	meta    *resultMeta // Status and headers, see WithStatus and WithHeader
 }
 
 type ErrorResult struct {
 	Success   bool        `json:"success" default:"false"`
 	ErrorInfo Error       `json:"error"`
 	Data      interface{} `json:"data" default:"null"`
+	meta      *resultMeta // Status and headers, see WithStatus and WithHeader
+}
+
+// resultMeta is the response status and headers a result asks for. It is a
+// pointer so results stay comparable.
+type resultMeta struct {
+	status int
+	header http.Header
+}
+
+// with returns a copy of m changed by fn, leaving results sharing m alone
+func (m *resultMeta) with(fn func(*resultMeta)) *resultMeta {
+	copied := &resultMeta{header: http.Header{}}
+	if m != nil {
+		copied.status = m.status
+		copied.header = m.header.Clone()
+	}
+	fn(copied)
+	return copied
+}
+
+// apply sets the headers on the response and returns the status, or fallback
+func (m *resultMeta) apply(c *gin.Context, fallback int) int {
+	if m == nil {
+		return fallback
+	}
+	for key, values := range m.header {
+		for _, value := range values {
+			c.Writer.Header().Add(key, value)
+		}
+	}
+	if m.status == 0 {
+		return fallback
+	}
+	return m.status
+}
+
+// WithStatus sets the response status, 200 when not set
+func (s SuccessResult[T]) WithStatus(status int) SuccessResult[T] {
+	s.meta = s.meta.with(func(m *resultMeta) { m.status = status })
+	return s
+}
+
+// WithHeader adds a response header
+func (s SuccessResult[T]) WithHeader(key, value string) SuccessResult[T] {
+	s.meta = s.meta.with(func(m *resultMeta) { m.header.Add(key, value) })
+	return s
+}
+
+// WithStatus sets the response status, 400 when not set
+func (er ErrorResult) WithStatus(status int) ErrorResult {
+	er.meta = er.meta.with(func(m *resultMeta) { m.status = status })
+	return er
+}
+
+// WithHeader adds a response header
+func (er ErrorResult) WithHeader(key, value string) ErrorResult {
+	er.meta = er.meta.with(func(m *resultMeta) { m.header.Add(key, value) })
+	return er
 }
 
 // Implement error interface so ErrorResult can be returned as an error
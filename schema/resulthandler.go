@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResultHandlerFunc is a handler returning the Result union instead of (*R, error)
+type ResultHandlerFunc[T Schema, R any] func(c *gin.Context, schema T) Result[R]
+
+const resultStatusKey = "schema_result_status"
+
+// ValidateAndHandleResult wraps a handler returning Result[R] like
+// ValidateAndHandle, so the status, headers and error of a response are one
+// value the handler returns from each branch:
+//
+//	func CreateUser(c *gin.Context, req CreateUserRequest) schema.Result[User] {
+//		if exists(req.Body.Email) {
+//			return schema.NotOk("ERR_EMAIL_TAKEN", "Email already registered").WithStatus(409)
+//		}
+//		user := create(req.Body)
+//		return schema.Ok(user).WithStatus(201).WithHeader("Location", "/users/"+user.ID)
+//	}
+//
+// SuccessResult[R] is sent as ValidateAndHandle sends *R, with status 200
+// unless set; ErrorResult is sent as a wrapped error with status 400 unless
+// set. A nil result is answered with a 500.
+func ValidateAndHandleResult[T Schema, R any](handler ResultHandlerFunc[T, R]) TypedHandlerFunc {
+	return ValidateAndHandle(func(c *gin.Context, schema T) (*R, error) {
+		switch result := handler(c, schema).(type) {
+		case SuccessResult[R]:
+			c.Set(resultStatusKey, result.meta.apply(c, http.StatusOK))
+			return &result.Data, nil
+		case ErrorResult:
+			return nil, result
+		default:
+			return nil, NotOk("ERR_INTERNAL", "Handler returned no result").WithStatus(http.StatusInternalServerError)
+		}
+	})
+}
+
+// successStatus returns the status a handler result asked for, 200 by default
+func successStatus(c *gin.Context) int {
+	if status := c.GetInt(resultStatusKey); status != 0 {
+		return status
+	}
+	return http.StatusOK
+}
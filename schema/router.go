@@ -1,28 +1,49 @@
 package schema
 
 import (
+	"reflect"
+
 	"github.com/gin-gonic/gin"
 )
 
 // RouterHelper provides methods to register routes with automatic type registration
 type RouterHelper struct {
 	*gin.Engine
+	routes   []mountableRoute // Routes registered through the helper, see Mount
+	registry *Registry        // Typed handlers and options of the routes, the global registry when nil
 }
 
 // RouterGroup provides methods to register routes with automatic type registration within a group
 type RouterGroup struct {
 	*gin.RouterGroup
 	groupSecuritySchemes []SecurityScheme
+	helper               *RouterHelper
+	tag                  string // Tag of the group's operations, see Tag
 }
 
 // NewRouter creates a new RouterHelper that wraps gin.Engine
 func NewRouter() *RouterHelper {
-	return &RouterHelper{gin.Default()}
+	return &RouterHelper{Engine: gin.Default()}
 }
 
 // WrapRouter wraps an existing gin.Engine with RouterHelper functionality
 func WrapRouter(engine *gin.Engine) *RouterHelper {
-	return &RouterHelper{engine}
+	return &RouterHelper{Engine: engine}
+}
+
+// WithRegistry makes the router store typed handlers and route options in
+// registry instead of the global registry. Call it before registering routes.
+func (r *RouterHelper) WithRegistry(registry *Registry) *RouterHelper {
+	r.registry = registry
+	return r
+}
+
+// Registry returns the registry the router stores typed handlers and route options in
+func (r *RouterHelper) Registry() *Registry {
+	if r == nil || r.registry == nil {
+		return defaultRegistry
+	}
+	return r.registry
 }
 
 // Use adds middleware to the router
@@ -34,7 +55,7 @@ func (r *RouterHelper) Use(middleware ...gin.HandlerFunc) gin.IRoutes {
 func (r *RouterHelper) UseSecurity(schemes ...SecurityScheme) gin.IRoutes {
 	var middlewares []gin.HandlerFunc
 	for _, scheme := range schemes {
-		middlewares = append(middlewares, scheme.Middleware())
+		middlewares = append(middlewares, skipOnPublicRoutes(r.Registry(), scheme.Middleware()))
 	}
 	return r.Engine.Use(middlewares...)
 }
@@ -44,27 +65,49 @@ func (r *RouterHelper) Group(relativePath string, handlers ...gin.HandlerFunc) *
 	return &RouterGroup{
 		RouterGroup:          r.Engine.Group(relativePath, handlers...),
 		groupSecuritySchemes: []SecurityScheme{},
+		helper:               r,
+		tag:                  inferTag(relativePath),
+	}
+}
+
+// Group creates a nested route group that keeps the group's security
+// schemes. Its tag is inferred from its own prefix, falling back to the
+// group's tag.
+func (rg *RouterGroup) Group(relativePath string, handlers ...gin.HandlerFunc) *RouterGroup {
+	tag := inferTag(relativePath)
+	if tag == "" {
+		tag = rg.tag
+	}
+	return &RouterGroup{
+		RouterGroup:          rg.RouterGroup.Group(relativePath, handlers...),
+		groupSecuritySchemes: append([]SecurityScheme(nil), rg.groupSecuritySchemes...),
+		helper:               rg.helper,
+		tag:                  tag,
 	}
 }
 
 // Use adds middleware to the route group with automatic security detection
 func (rg *RouterGroup) Use(middleware ...gin.HandlerFunc) gin.IRoutes {
 	// Scan middleware for security schemes using reflection
-	for _, handler := range middleware {
+	handlers := make([]gin.HandlerFunc, len(middleware))
+	for i, handler := range middleware {
+		handlers[i] = handler
 		if scheme, isSecurityMiddleware := IsSecurityMiddleware(handler); isSecurityMiddleware {
 			rg.groupSecuritySchemes = append(rg.groupSecuritySchemes, scheme)
+			handlers[i] = skipOnPublicRoutes(rg.helper.Registry(), handler)
 		}
 	}
 
-	return rg.RouterGroup.Use(middleware...)
+	return rg.RouterGroup.Use(handlers...)
 }
 
 // processHandlers processes a list of handlers and separates them by type
-func processHandlers(method, path string, handlers []interface{}) ([]gin.HandlerFunc, TypedHandlerFunc, bool) {
+func processHandlers(registry *Registry, method, path string, handlers []interface{}) ([]gin.HandlerFunc, TypedHandlerFunc, bool) {
 	var middlewares []gin.HandlerFunc
 	var securitySchemes []SecurityScheme
 	var typedHandler TypedHandlerFunc
 	var hasTypedHandler bool
+	var validatorSchemaType reflect.Type
 
 	// Process all handlers to separate middleware and typed handlers
 	for _, h := range handlers {
@@ -72,25 +115,47 @@ func processHandlers(method, path string, handlers []interface{}) ([]gin.Handler
 		case SecurityScheme:
 			securitySchemes = append(securitySchemes, v)
 			middlewares = append(middlewares, v.Middleware())
+		case *PublicRoute:
+			registry.RegisterPublicRoute(method, path)
+		case *ConcurrencyLimit:
+			registry.RegisterConcurrencyLimit(method, path, v)
+			middlewares = append(middlewares, v.Middleware())
+		case *CacheControl:
+			registry.RegisterCacheControl(method, path, v)
+			middlewares = append(middlewares, v.Middleware())
+		case *ContentTypes:
+			registry.RegisterContentTypes(method, path, v)
+			middlewares = append(middlewares, v.Middleware())
+		case *CSRFProtection:
+			registry.RegisterCSRFProtection(method, path, v)
+			middlewares = append(middlewares, v.Middleware())
 		case TypedHandlerFunc:
 			typedHandler = v
 			hasTypedHandler = true
 			middlewares = append(middlewares, v.HandlerFunc())
 		case gin.HandlerFunc:
 			middlewares = append(middlewares, v)
+			if schemaType, ok := IsValidatorMiddleware(v); ok {
+				validatorSchemaType = schemaType
+			}
 		case func(*gin.Context):
 			middlewares = append(middlewares, gin.HandlerFunc(v))
+			if schemaType, ok := IsValidatorMiddleware(v); ok {
+				validatorSchemaType = schemaType
+			}
 		}
 	}
 
-	// Register typed handler if present
+	// Register typed handler if present, otherwise document a Validator's schema
 	if hasTypedHandler {
-		RegisterTypedHandler(method, path, typedHandler)
+		registry.RegisterTypedHandler(method, path, typedHandler)
+	} else if validatorSchemaType != nil {
+		registry.RegisterTypedHandler(method, path, TypedHandlerFunc{schemaType: validatorSchemaType})
 	}
 
 	// Register security schemes
 	if len(securitySchemes) > 0 {
-		RegisterSecurityScheme(method, path, securitySchemes...)
+		registry.RegisterSecurityScheme(method, path, securitySchemes...)
 	}
 
 	return middlewares, typedHandler, hasTypedHandler
@@ -98,32 +163,37 @@ func processHandlers(method, path string, handlers []interface{}) ([]gin.Handler
 
 // GET registers a GET route with automatic type registration
 func (r *RouterHelper) GET(path string, handlers ...interface{}) {
-	middlewares, _, _ := processHandlers("GET", path, handlers)
+	middlewares, _, _ := processHandlers(r.Registry(), "GET", path, handlers)
 	r.Engine.GET(path, middlewares...)
+	r.recordRoute("GET", path, r.Engine.Handlers, middlewares)
 }
 
 // POST registers a POST route with automatic type registration
 func (r *RouterHelper) POST(path string, handlers ...interface{}) {
-	middlewares, _, _ := processHandlers("POST", path, handlers)
+	middlewares, _, _ := processHandlers(r.Registry(), "POST", path, handlers)
 	r.Engine.POST(path, middlewares...)
+	r.recordRoute("POST", path, r.Engine.Handlers, middlewares)
 }
 
 // PUT registers a PUT route with automatic type registration
 func (r *RouterHelper) PUT(path string, handlers ...interface{}) {
-	middlewares, _, _ := processHandlers("PUT", path, handlers)
+	middlewares, _, _ := processHandlers(r.Registry(), "PUT", path, handlers)
 	r.Engine.PUT(path, middlewares...)
+	r.recordRoute("PUT", path, r.Engine.Handlers, middlewares)
 }
 
 // DELETE registers a DELETE route with automatic type registration
 func (r *RouterHelper) DELETE(path string, handlers ...interface{}) {
-	middlewares, _, _ := processHandlers("DELETE", path, handlers)
+	middlewares, _, _ := processHandlers(r.Registry(), "DELETE", path, handlers)
 	r.Engine.DELETE(path, middlewares...)
+	r.recordRoute("DELETE", path, r.Engine.Handlers, middlewares)
 }
 
 // PATCH registers a PATCH route with automatic type registration
 func (r *RouterHelper) PATCH(path string, handlers ...interface{}) {
-	middlewares, _, _ := processHandlers("PATCH", path, handlers)
+	middlewares, _, _ := processHandlers(r.Registry(), "PATCH", path, handlers)
 	r.Engine.PATCH(path, middlewares...)
+	r.recordRoute("PATCH", path, r.Engine.Handlers, middlewares)
 }
 
 // RouterGroup HTTP method handlers
@@ -131,11 +201,20 @@ func (r *RouterHelper) PATCH(path string, handlers ...interface{}) {
 // processGroupHandlers processes handlers for a route group
 func (rg *RouterGroup) processGroupHandlers(method, path string, handlers []interface{}) []gin.HandlerFunc {
 	fullPath := rg.RouterGroup.BasePath() + path
-	middlewares, _, _ := processHandlers(method, fullPath, handlers)
+	registry := rg.helper.Registry()
+	middlewares, _, _ := processHandlers(registry, method, fullPath, handlers)
+
+	// Register group-level security schemes for this route unless it is public
+	if len(rg.groupSecuritySchemes) > 0 && !registry.IsPublicRoute(method, fullPath) {
+		registry.RegisterSecurityScheme(method, fullPath, rg.groupSecuritySchemes...)
+	}
+
+	if rg.tag != "" {
+		registry.RegisterRouteTags(method, fullPath, rg.tag)
+	}
 
-	// Register group-level security schemes for this route
-	if len(rg.groupSecuritySchemes) > 0 {
-		RegisterSecurityScheme(method, fullPath, rg.groupSecuritySchemes...)
+	if rg.helper != nil {
+		rg.helper.recordRoute(method, fullPath, rg.RouterGroup.Handlers, middlewares)
 	}
 
 	return middlewares
@@ -0,0 +1,129 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Sanitizer transforms a string value before validation
+type Sanitizer func(value string) string
+
+var (
+	sanitizersMu sync.RWMutex
+	sanitizers   = map[string]Sanitizer{
+		"trim":      strings.TrimSpace,
+		"ltrim":     func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) },
+		"rtrim":     func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) },
+		"lowercase": strings.ToLower,
+		"uppercase": strings.ToUpper,
+		"title":     func(s string) string { return cases.Title(language.Und).String(s) },
+		"squash":    func(s string) string { return strings.Join(strings.Fields(s), " ") },
+		"nfc":       norm.NFC.String,
+		"nfkc":      norm.NFKC.String,
+		"email": func(s string) string {
+			return strings.ToLower(strings.TrimSpace(s))
+		},
+	}
+)
+
+// RegisterSanitizer adds or replaces a sanitizer usable in `mod` tags
+func RegisterSanitizer(name string, sanitizer Sanitizer) {
+	sanitizersMu.Lock()
+	defer sanitizersMu.Unlock()
+	sanitizers[name] = sanitizer
+}
+
+func getSanitizer(name string) (Sanitizer, bool) {
+	sanitizersMu.RLock()
+	defer sanitizersMu.RUnlock()
+	sanitizer, ok := sanitizers[name]
+	return sanitizer, ok
+}
+
+// sanitizeFields applies `mod:"trim,lowercase"` tags to string fields in place.
+// Sanitizers run in the order they are listed.
+func sanitizeFields(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			return sanitizeFields(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := sanitizeFields(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			typeField := t.Field(i)
+			if !typeField.IsExported() || !field.CanSet() {
+				continue
+			}
+
+			tag := typeField.Tag.Get("mod")
+			if tag == "" {
+				if err := sanitizeFields(field); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := applySanitizers(field, tag); err != nil {
+				return fmt.Errorf("field '%s': %w", typeField.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// applySanitizers runs the sanitizers in tag over a string, *string or []string field
+func applySanitizers(field reflect.Value, tag string) error {
+	var chain []Sanitizer
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		sanitizer, ok := getSanitizer(name)
+		if !ok {
+			return fmt.Errorf("unknown sanitizer '%s'", name)
+		}
+		chain = append(chain, sanitizer)
+	}
+
+	apply := func(value reflect.Value) {
+		s := value.String()
+		for _, sanitizer := range chain {
+			s = sanitizer(s)
+		}
+		value.SetString(s)
+	}
+
+	switch {
+	case field.Kind() == reflect.String:
+		apply(field)
+	case field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.String:
+		if !field.IsNil() {
+			apply(field.Elem())
+		}
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < field.Len(); i++ {
+			apply(field.Index(i))
+		}
+	default:
+		return fmt.Errorf("mod tag is only supported on string fields")
+	}
+
+	return nil
+}
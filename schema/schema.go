@@ -1,12 +1,17 @@
 package schema
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -18,11 +23,12 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	validate.RegisterValidation("regexp", validateRegexp)
 }
 
 // HandlerFunc represents a schema-validated handler function that can return either:
 // - (*result, nil) for success
-// - (nil, SchemaError) for explicit errors
+// - (nil, SchemaError) for explicit errors, answered with their Status or 400
 // - (nil, ErrorResult) for direct error result control
 type HandlerFunc[T Schema, R any] func(c *gin.Context, schema T) (*R, error)
 
@@ -38,6 +44,26 @@ type TypedHandlerFunc struct {
 	handler      gin.HandlerFunc
 	schemaType   reflect.Type
 	responseType reflect.Type
+	examples     []Example
+	conditional  bool
+	headers      reflect.Type
+	extensions   Extensions
+	view         ResponseView
+	naming       NamingStrategy
+	longPoll     time.Duration
+	operationID  string
+
+	requestSchema   *JSONSchema         // See OverrideRequestSchema
+	responseSchemas map[int]*JSONSchema // See OverrideResponseSchema
+}
+
+// Example is a named request/response pair documented on an operation
+type Example struct {
+	Name        string
+	Summary     string
+	Description string
+	Request     interface{} // Request body, omitted from the spec when nil
+	Response    interface{} // Response data before wrapping, omitted when nil
 }
 
 func (t TypedHandlerFunc) GetSchemaType() reflect.Type {
@@ -52,25 +78,83 @@ func (t TypedHandlerFunc) ServeHTTP(c *gin.Context) {
 	t.handler(c)
 }
 
+// WithExample returns a copy of the handler with the given examples added
+func (t TypedHandlerFunc) WithExample(examples ...Example) TypedHandlerFunc {
+	t.examples = append(append([]Example{}, t.examples...), examples...)
+	return t
+}
+
+// WithExtension returns a copy of the handler with a vendor extension added to its operation
+func (t TypedHandlerFunc) WithExtension(key string, value interface{}) TypedHandlerFunc {
+	t.extensions = mergeExtensions(Extensions{}, t.extensions)
+	t.extensions[extensionKey(key)] = value
+	return t
+}
+
+// GetExtensions returns the vendor extensions registered with WithExtension
+func (t TypedHandlerFunc) GetExtensions() Extensions {
+	return t.extensions
+}
+
+// IsConditional reports whether the handler returns a WithLastModified response
+func (t TypedHandlerFunc) IsConditional() bool {
+	return t.conditional
+}
+
+// GetResponseHeaders returns the headers struct of a WithHeaders response, or nil
+func (t TypedHandlerFunc) GetResponseHeaders() reflect.Type {
+	return t.headers
+}
+
+// GetExamples returns the examples registered with WithExample
+func (t TypedHandlerFunc) GetExamples() []Example {
+	return t.examples
+}
+
 // Convert TypedHandlerFunc to gin.HandlerFunc
 func (t TypedHandlerFunc) HandlerFunc() gin.HandlerFunc {
 	return t.handler
 }
 
-// Global registry to store typed handlers for OpenAPI generation
-var typedHandlers = make(map[string]TypedHandlerFunc)
+// RouteMatchOptions controls how registry keys are normalized. Duplicate
+// slashes are always collapsed and methods are always upper-cased.
+type RouteMatchOptions struct {
+	IgnoreTrailingSlash bool // "/users/" and "/users" share a key
+	CaseInsensitive     bool // "/Users" and "/users" share a key
+}
+
+// Global route match configuration
+var routeMatchOptions RouteMatchOptions
 
-// RegisterTypedHandler stores a typed handler for OpenAPI generation
-func RegisterTypedHandler(method, path string, handler TypedHandlerFunc) {
-	key := method + " " + path
-	typedHandlers[key] = handler
+// SetRouteMatchOptions sets how method and path are matched in the handler
+// and security registries. Call it before registering routes.
+func SetRouteMatchOptions(opts RouteMatchOptions) {
+	routeMatchOptions = opts
 }
 
-// GetTypedHandler retrieves a typed handler by method and path
-func GetTypedHandler(method, path string) (TypedHandlerFunc, bool) {
-	key := method + " " + path
-	handler, exists := typedHandlers[key]
-	return handler, exists
+// GetRouteMatchOptions returns the current route match options
+func GetRouteMatchOptions() RouteMatchOptions {
+	return routeMatchOptions
+}
+
+var duplicateSlashes = regexp.MustCompile(`/{2,}`)
+
+// routeKey builds the normalized registry key for a method and path
+func routeKey(method, path string) string {
+	path = duplicateSlashes.ReplaceAllString(path, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	if routeMatchOptions.IgnoreTrailingSlash && len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	if routeMatchOptions.CaseInsensitive {
+		path = strings.ToLower(path)
+	}
+
+	return strings.ToUpper(method) + " " + path
 }
 
 // ValidateAndHandle wraps a handler function with schema validation and type information
@@ -82,63 +166,148 @@ func ValidateAndHandle[T Schema, R any](handler HandlerFunc[T, R]) TypedHandlerF
 	responseType := reflect.TypeOf(response)
 
 	// Remove pointer if it's a pointer type
-	if responseType.Kind() == reflect.Ptr {
+	if responseType != nil && responseType.Kind() == reflect.Ptr {
 		responseType = responseType.Elem()
 	}
 
+	// Document the wrapped data for responses such as WithLastModified
+	headersType := unwrapResponseHeaders(responseType)
+	responseType, conditional := unwrapResponseType(responseType)
+
 	ginHandler := func(c *gin.Context) {
+		start := auditStart(c)
+		defer auditRequest(c, start)
+
 		var schema T
 
 		// Parse and validate the schema
 		if err := parseSchema(c, &schema); err != nil {
 			errorResult := convertToErrorResult(err)
-			wrappedError := globalWrapper.WrapError(errorResult.ErrorInfo.Code, errorResult.ErrorInfo.Message)
-			c.JSON(400, wrappedError)
+			respondError(c, parseErrorStatus(errorResult.ErrorInfo.Code), errorResult.ErrorInfo.Code, errorResult.ErrorInfo.Message)
 			return
 		}
 
+		// Run the handler inside the transaction of a WithTransaction handler
+		tx, err := beginTransaction(c)
+		if err != nil {
+			c.Error(err)
+			respondError(c, 500, "ERR_INTERNAL", "Failed to begin transaction")
+			return
+		}
+		committed := false
+		if tx != nil {
+			// Roll back on errors and panics
+			defer func() {
+				if !committed {
+					tx.Rollback()
+				}
+			}()
+		}
+
 		// Call the handler with validated schema
 		result, err := handler(c, schema)
+		if tx != nil && err == nil && result != nil {
+			committed = true
+			if err := tx.Commit(); err != nil {
+				c.Error(err)
+				respondError(c, 500, "ERR_INTERNAL", "Failed to commit transaction")
+				return
+			}
+		}
+		if errors.Is(err, errLongPollEmpty) {
+			respondLongPollEmpty(c)
+			return
+		}
 		if err != nil {
 			// Check if the error is actually an ErrorResult (user wants direct control)
 			if errorResult, ok := err.(ErrorResult); ok {
-				wrappedError := globalWrapper.WrapError(errorResult.ErrorInfo.Code, errorResult.ErrorInfo.Message)
-				c.JSON(400, wrappedError)
+				respondError(c, errorResult.meta.apply(c, 400), errorResult.ErrorInfo.Code, errorResult.ErrorInfo.Message)
+				return
+			}
+
+			// Keep the cause of a WrapError error for logs, answer with its public message
+			var wrapped WrappedError
+			if errors.As(err, &wrapped) {
+				c.Error(err)
+				respondError(c, wrapped.Status(), wrapped.Code, debugMessage(wrapped.Message, wrapped.cause))
 				return
 			}
 
 			// Otherwise convert the error to an ErrorResult
 			errorResult := convertToErrorResult(err)
-			wrappedError := globalWrapper.WrapError(errorResult.ErrorInfo.Code, errorResult.ErrorInfo.Message)
-			c.JSON(400, wrappedError)
+			message := errorResult.ErrorInfo.Message
+			if errorResult.ErrorInfo.Code == "ERR_NOT_SPECIFIED" {
+				message = debugMessage(message, err)
+			}
+			respondError(c, errorResult.meta.apply(c, 400), errorResult.ErrorInfo.Code, message)
 			return
 		}
 
 		// Check if result is nil (shouldn't happen with proper error handling)
 		if result == nil {
-			wrappedError := globalWrapper.WrapError("ERR_INTERNAL", "Handler returned nil result without error")
-			c.JSON(500, wrappedError)
+			respondError(c, 500, "ERR_INTERNAL", "Handler returned nil result without error")
+			return
+		}
+
+		var data interface{} = *result
+		if tabular, ok := data.(tabularResponder); ok {
+			if err := tabular.writeResponse(c); err != nil {
+				c.Error(err)
+			}
+			return
+		}
+
+		if envelope, ok := data.(responseEnvelope); ok {
+			if carrier, ok := data.(headerCarrier); ok {
+				writeResponseHeaders(c, carrier.responseHeaders())
+			}
+			if modifier, ok := data.(lastModifier); ok && handleLastModified(c, modifier.lastModified()) {
+				return
+			}
+			data = envelope.responseData()
+		}
+
+		// Encrypt `encrypt:"true"` fields before they leave the handler
+		data, err = encryptFields(data)
+		if err != nil {
+			respondError(c, 500, "ERR_INTERNAL", "Failed to encrypt response")
 			return
 		}
 
+		// Reduce the data to the fields of a WithView view
+		data = applyView(c, data)
+
 		// Wrap the result using the configured wrapper (dereference the pointer)
-		wrappedResult := globalWrapper.WrapSuccess(*result)
-		c.JSON(200, wrappedResult)
+		wrappedResult := globalWrapper.WrapSuccess(data)
+		writeJSON(c, successStatus(c), wrappedResult)
 	}
 
 	return TypedHandlerFunc{
 		handler:      ginHandler,
 		schemaType:   schemaType,
 		responseType: responseType,
+		conditional:  conditional,
+		headers:      headersType,
 	}
 }
 
+// respondError writes a wrapped error response and records its code for auditing
+func respondError(c *gin.Context, status int, code, message string) {
+	c.Set("error_code", code)
+	writeJSON(c, status, globalWrapper.WrapError(code, localizeError(c, code, message)))
+}
+
 // parseSchema extracts and validates data from the request into the schema
 func parseSchema(c *gin.Context, schema any) error {
 	schemaValue := reflect.ValueOf(schema).Elem()
 	schemaType := schemaValue.Type()
 
+	// Let the body be read again when a middleware buffered it
+	rewindBody(c)
+
 	// First pass: parse and set values (including defaults)
+	var bodyFields []int
+	hasBodySection := false
 	for i := 0; i < schemaValue.NumField(); i++ {
 		field := schemaValue.Field(i)
 		fieldType := schemaType.Field(i)
@@ -148,6 +317,26 @@ func parseSchema(c *gin.Context, schema any) error {
 			continue
 		}
 
+		if !isSectionField(fieldType) {
+			switch topLevelFieldLocation(fieldType, c.Request.Method) {
+			case fieldLocationQuery:
+				if err := parseQueryField(c, field, fieldType); err != nil {
+					return fmt.Errorf("query validation failed: %w", err)
+				}
+			case fieldLocationHeader:
+				if err := parseHeaderField(c, field, fieldType); err != nil {
+					return fmt.Errorf("headers validation failed: %w", err)
+				}
+			case fieldLocationCookie:
+				if err := parseCookieField(c, field, fieldType); err != nil {
+					return fmt.Errorf("cookies validation failed: %w", err)
+				}
+			case fieldLocationBody:
+				bodyFields = append(bodyFields, i)
+			}
+			continue
+		}
+
 		switch fieldName {
 		case "params":
 			if err := parseParams(c, field); err != nil {
@@ -157,15 +346,37 @@ func parseSchema(c *gin.Context, schema any) error {
 			if err := parseQuery(c, field); err != nil {
 				return fmt.Errorf("query validation failed: %w", err)
 			}
+		case "headers":
+			if err := parseHeaders(c, field); err != nil {
+				return fmt.Errorf("headers validation failed: %w", err)
+			}
+		case "cookies":
+			if err := parseCookies(c, field); err != nil {
+				return fmt.Errorf("cookies validation failed: %w", err)
+			}
 		case "body":
+			hasBodySection = true
 			if err := parseBody(c, field); err != nil {
 				return fmt.Errorf("body validation failed: %w", err)
 			}
 		}
 	}
 
+	// Top-level body fields are only used when there is no Body section
+	if len(bodyFields) > 0 && !hasBodySection {
+		if err := parseBodyFields(c, schemaValue, bodyFields); err != nil {
+			return fmt.Errorf("body validation failed: %w", err)
+		}
+	}
+
+	// Apply `mod` sanitizers so validation sees the normalized values
+	if err := sanitizeFields(schemaValue); err != nil {
+		return fmt.Errorf("sanitization failed: %w", err)
+	}
+
 	// Second pass: validate the entire schema after all values are set
 	if err := validate.Struct(schema); err != nil {
+		recordValidationErrors(c, err)
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -175,7 +386,11 @@ func parseSchema(c *gin.Context, schema any) error {
 // convertToErrorResult converts any error to an ErrorResult
 func convertToErrorResult(err error) ErrorResult {
 	// Check if it's a SchemaError (explicit error from handler)
-	if schemaErr, ok := err.(SchemaError); ok {
+	var schemaErr SchemaError
+	if errors.As(err, &schemaErr) {
+		if schemaErr.Status != 0 {
+			return NotOk(schemaErr.Code, schemaErr.Message).WithStatus(schemaErr.Status)
+		}
 		return NotOk(schemaErr.Code, schemaErr.Message)
 	}
 
@@ -186,6 +401,10 @@ func convertToErrorResult(err error) ErrorResult {
 		return NotOk("ERR_INVALID_PARAMS", extractValidationMessage(errMsg))
 	case strings.Contains(errMsg, "query validation failed"):
 		return NotOk("ERR_INVALID_QUERY", extractValidationMessage(errMsg))
+	case strings.Contains(errMsg, "headers validation failed"):
+		return NotOk("ERR_INVALID_HEADERS", extractValidationMessage(errMsg))
+	case strings.Contains(errMsg, "cookies validation failed"):
+		return NotOk("ERR_INVALID_COOKIES", extractValidationMessage(errMsg))
 	case strings.Contains(errMsg, "body validation failed"):
 		return NotOk("ERR_INVALID_BODY", extractValidationMessage(errMsg))
 	case strings.Contains(errMsg, "validation failed"):
@@ -215,9 +434,11 @@ func extractValidationMessage(errMsg string) string {
 	return errMsg
 }
 
-// parseParams extracts URL parameters and maps them to the schema
+// parseParams extracts URL parameters and maps them to the schema.
+// Wildcard parameters (*name) are captured without their leading slash.
 func parseParams(c *gin.Context, field reflect.Value) error {
 	fieldType := field.Type()
+	wildcard := wildcardParamName(c.FullPath())
 
 	for i := 0; i < field.NumField(); i++ {
 		structField := field.Field(i)
@@ -234,6 +455,10 @@ func parseParams(c *gin.Context, field reflect.Value) error {
 		}
 
 		paramValue := c.Param(paramName)
+		if paramName == wildcard {
+			paramValue = strings.TrimPrefix(paramValue, "/")
+		}
+
 		if paramValue == "" {
 			// Check if field is required
 			if isRequired(typeField) {
@@ -243,6 +468,10 @@ func parseParams(c *gin.Context, field reflect.Value) error {
 		}
 
 		if err := setFieldValue(structField, paramValue); err != nil {
+			// Conversion errors echo the raw value, so drop them for sensitive fields
+			if isSensitiveField(typeField) {
+				return fmt.Errorf("invalid param '%s'", paramName)
+			}
 			return fmt.Errorf("invalid param '%s': %w", paramName, err)
 		}
 	}
@@ -262,41 +491,64 @@ func parseQuery(c *gin.Context, field reflect.Value) error {
 			continue
 		}
 
-		// Get query name from tag or use field name
-		queryName := getTagValue(typeField, "query")
-		if queryName == "" {
-			// Try exact field name first, then lowercase
-			queryName = typeField.Name
+		if err := parseQueryField(c, structField, typeField); err != nil {
+			return err
 		}
+	}
 
-		queryValue := c.Query(queryName)
+	return nil
+}
 
-		// If query tag exists but no value found, also try field name variants
-		if queryValue == "" {
-			// Try exact field name
-			if fieldQueryValue := c.Query(typeField.Name); fieldQueryValue != "" {
-				queryValue = fieldQueryValue
-				queryName = typeField.Name
-			} else if lowercaseQueryValue := c.Query(strings.ToLower(typeField.Name)); lowercaseQueryValue != "" {
-				queryValue = lowercaseQueryValue
-				queryName = strings.ToLower(typeField.Name)
+// parseQueryField extracts a single query parameter into structField
+func parseQueryField(c *gin.Context, structField reflect.Value, typeField reflect.StructField) error {
+	if isQueryArrayType(typeField.Type) || isDeepObjectType(typeField.Type) {
+		return parseQueryCollection(c, structField, typeField)
+	}
+
+	// Get query name from tag or use field name
+	queryName := getTagValue(typeField, "query")
+	if queryName == "" {
+		// Try exact field name first, then lowercase
+		queryName = typeField.Name
+	}
+
+	queryValue := c.Query(queryName)
+
+	// If query tag exists but no value found, also try field name variants
+	if queryValue == "" {
+		// Try exact field name
+		if fieldQueryValue := c.Query(typeField.Name); fieldQueryValue != "" {
+			queryValue = fieldQueryValue
+			queryName = typeField.Name
+		} else if lowercaseQueryValue := c.Query(strings.ToLower(typeField.Name)); lowercaseQueryValue != "" {
+			queryValue = lowercaseQueryValue
+			queryName = strings.ToLower(typeField.Name)
+		} else if jsonName := getJSONFieldName(typeField); jsonName != "-" {
+			// Top-level fields are documented under their json name
+			if jsonQueryValue := c.Query(jsonName); jsonQueryValue != "" {
+				queryValue = jsonQueryValue
+				queryName = jsonName
 			}
 		}
+	}
 
-		if queryValue == "" {
-			// Check for default value
-			if defaultVal := getTagValue(typeField, "default"); defaultVal != "" {
-				queryValue = defaultVal
-			} else if isRequired(typeField) {
-				return fmt.Errorf("required query param '%s' is missing", queryName)
-			} else {
-				continue
-			}
+	if queryValue == "" {
+		// Check for default value
+		if defaultVal := getTagValue(typeField, "default"); defaultVal != "" {
+			queryValue = defaultVal
+		} else if isRequired(typeField) {
+			return fmt.Errorf("required query param '%s' is missing", queryName)
+		} else {
+			return nil
 		}
+	}
 
-		if err := setFieldValue(structField, queryValue); err != nil {
-			return fmt.Errorf("invalid query param '%s': %w", queryName, err)
+	if err := setFieldValue(structField, queryValue); err != nil {
+		// Conversion errors echo the raw value, so drop them for sensitive fields
+		if isSensitiveField(typeField) {
+			return fmt.Errorf("invalid query param '%s'", queryName)
 		}
+		return fmt.Errorf("invalid query param '%s': %w", queryName, err)
 	}
 
 	return nil
@@ -312,18 +564,78 @@ func parseBody(c *gin.Context, field reflect.Value) error {
 		return nil
 	}
 
-	// Create a pointer to the field for JSON unmarshaling
+	// StreamBody reads the array itself while the handler runs
+	if binder, ok := field.Addr().Interface().(streamBinder); ok {
+		binder.bindStream(limitedBody(c, globalBodyLimits), globalBodyLimits.MaxItems)
+		return nil
+	}
+
+	// Create a pointer to the field for JSON or form binding
 	bodyPtr := reflect.New(field.Type())
 	bodyPtr.Elem().Set(field)
 
-	if err := c.ShouldBindJSON(bodyPtr.Interface()); err != nil {
-		return fmt.Errorf("invalid JSON body: %w", err)
+	if isFormRequest(c) {
+		if err := parseFormBody(c, bodyPtr); err != nil {
+			return err
+		}
+	} else if globalBodyLimits.Stream {
+		if err := decodeBodyStream(c, bodyPtr.Interface()); err != nil {
+			return fmt.Errorf("invalid JSON body: %w", redactJSONError(err, field.Type()))
+		}
+	} else if err := c.ShouldBindJSON(bodyPtr.Interface()); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", redactJSONError(err, field.Type()))
+	}
+
+	// Decrypt `encrypt:"true"` fields so validation sees the plaintext
+	if err := decryptFields(bodyPtr.Elem()); err != nil {
+		return err
 	}
 
 	field.Set(bodyPtr.Elem())
 	return nil
 }
 
+// parseBodyFields decodes top-level fields that belong in the body from a JSON object
+func parseBodyFields(c *gin.Context, schemaValue reflect.Value, indexes []int) error {
+	schemaType := schemaValue.Type()
+
+	var raw map[string]json.RawMessage
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindBodyWith(&raw, binding.JSON); err != nil {
+			return fmt.Errorf("invalid JSON body: %w", err)
+		}
+	}
+
+	for _, i := range indexes {
+		typeField := schemaType.Field(i)
+		jsonName := getJSONFieldName(typeField)
+
+		value, ok := raw[jsonName]
+		if !ok {
+			if defaultVal := getTagValue(typeField, "default"); defaultVal != "" {
+				if err := setFieldValue(schemaValue.Field(i), defaultVal); err != nil {
+					return fmt.Errorf("invalid default for '%s': %w", jsonName, err)
+				}
+			}
+			continue
+		}
+
+		if err := json.Unmarshal(value, schemaValue.Field(i).Addr().Interface()); err != nil {
+			if isSensitiveField(typeField) {
+				return fmt.Errorf("invalid JSON body field '%s'", jsonName)
+			}
+			return fmt.Errorf("invalid JSON body field '%s': %w", jsonName, redactJSONError(err, typeField.Type))
+		}
+
+		// Decrypt `encrypt:"true"` fields so validation sees the plaintext
+		if err := decryptBodyField(schemaValue.Field(i), typeField); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Helper functions
 
 func getTagValue(field reflect.StructField, tagName string) string {
@@ -337,12 +649,57 @@ func getTagValue(field reflect.StructField, tagName string) string {
 	return parts[0]
 }
 
+// isRequired reports whether a field must be present in the request.
+// A "required" validate/binding rule is enforced by the validator and always
+// wins, while an "omitempty" rule makes the field optional. Otherwise a
+// "required" option on the query, param or json tag counts, unless the field
+// is a pointer or has json omitempty.
 func isRequired(field reflect.StructField) bool {
-	// Check for required in various tags
-	tags := []string{"validate", "binding", "query", "param", "json"}
-	for _, tagName := range tags {
-		tag := field.Tag.Get(tagName)
-		if strings.Contains(tag, "required") {
+	for _, tagName := range []string{"validate", "binding"} {
+		if required, decided := validationRequirement(field.Tag.Get(tagName)); decided {
+			return required
+		}
+	}
+
+	if field.Type.Kind() == reflect.Ptr || hasTagOption(field, "json", "omitempty") {
+		return false
+	}
+
+	for _, tagName := range []string{"query", "param", "header", "cookie", "json"} {
+		if hasTagOption(field, tagName, "required") {
+			return true
+		}
+	}
+	return false
+}
+
+// validationRequirement finds the first required or omitempty rule in a
+// validator tag. Rules after "dive" apply to elements and are ignored, and
+// conditional rules such as required_if never make a field required.
+func validationRequirement(tag string) (required bool, decided bool) {
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		switch rule {
+		case "dive", "keys":
+			return false, false
+		case "omitempty":
+			return false, true
+		}
+
+		for _, alternative := range strings.Split(rule, "|") {
+			if alternative == "required" {
+				return true, true
+			}
+		}
+	}
+	return false, false
+}
+
+// hasTagOption reports whether a `name,option` style tag lists option after the name
+func hasTagOption(field reflect.StructField, tagName, option string) bool {
+	parts := strings.Split(field.Tag.Get(tagName), ",")
+	for _, part := range parts[1:] {
+		if strings.TrimSpace(part) == option {
 			return true
 		}
 	}
@@ -386,6 +743,12 @@ func setFieldValue(field reflect.Value, value string) error {
 			return err
 		}
 		field.SetBool(boolVal)
+	case reflect.Ptr:
+		target := reflect.New(field.Type().Elem())
+		if err := setFieldValue(target.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(target)
 	default:
 		return fmt.Errorf("unsupported field type: %s", field.Kind())
 	}
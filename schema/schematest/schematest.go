@@ -0,0 +1,160 @@
+// Package schematest runs typed handlers through the full parse, validate and
+// wrap pipeline without a server, for handler unit tests.
+package schematest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/fxfn/x/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// Request describes the request passed to the handler
+type Request struct {
+	Method  string                 // Defaults to POST when Body is set, GET otherwise
+	Path    string                 // Route pattern such as "/users/:id", defaults to "/"
+	Params  map[string]string      // Values for the path parameters in Path
+	Query   url.Values             // Query string
+	Body    interface{}            // Marshaled to JSON, string and []byte are sent as is
+	Headers map[string]string      // Request headers
+	Values  map[string]interface{} // Set on the gin context before the handler runs, e.g. "api_key"
+}
+
+// Response is the recorded response with the default envelope decoded
+type Response struct {
+	Status  int
+	Header  http.Header
+	Body    []byte
+	Success bool
+	Data    json.RawMessage
+	Error   *schema.Error
+}
+
+// Decode unmarshals the envelope data into v
+func (r *Response) Decode(v interface{}) error {
+	if len(r.Data) == 0 {
+		return fmt.Errorf("response has no data")
+	}
+	return json.Unmarshal(r.Data, v)
+}
+
+// Call runs handler for req and returns the recorded response
+func Call(handler schema.TypedHandlerFunc, req Request) (*Response, error) {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+		if req.Body != nil {
+			method = http.MethodPost
+		}
+	}
+
+	pattern := req.Path
+	if pattern == "" {
+		pattern = "/"
+	}
+
+	target, err := buildURL(pattern, req.Params, req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := encodeBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq := httptest.NewRequest(method, target, body)
+	if req.Body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	engine := gin.New()
+	engine.Handle(method, pattern, func(c *gin.Context) {
+		for key, value := range req.Values {
+			c.Set(key, value)
+		}
+		c.Next()
+	}, handler.HandlerFunc())
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httpReq)
+
+	response := &Response{
+		Status: recorder.Code,
+		Header: recorder.Header(),
+		Body:   recorder.Body.Bytes(),
+	}
+
+	// Decode the default envelope, custom wrappers can use Body directly
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   *schema.Error   `json:"error"`
+	}
+	if json.Unmarshal(response.Body, &envelope) == nil {
+		response.Success = envelope.Success
+		response.Data = envelope.Data
+		response.Error = envelope.Error
+	}
+
+	return response, nil
+}
+
+// buildURL substitutes params into the route pattern and appends the query
+func buildURL(pattern string, params map[string]string, query url.Values) (string, error) {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+
+		name := segment[1:]
+		value, ok := params[name]
+		if !ok {
+			if segment[0] == '*' {
+				segments[i] = ""
+				continue
+			}
+			return "", fmt.Errorf("missing value for path parameter '%s'", name)
+		}
+
+		if segment[0] == '*' {
+			segments[i] = strings.TrimPrefix(value, "/")
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+
+	target := strings.Join(segments, "/")
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	return target, nil
+}
+
+func encodeBody(body interface{}) (io.Reader, error) {
+	switch v := body.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.NewReader(v), nil
+	case []byte:
+		return bytes.NewReader(v), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		return bytes.NewReader(data), nil
+	}
+}
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 
@@ -17,27 +18,39 @@ type SecurityScheme interface {
 	Middleware() gin.HandlerFunc
 }
 
-// Global registry to track security middleware used in routes
-var securitySchemeRegistry = make(map[string][]SecurityScheme)
-
 // Registry to map gin.HandlerFunc to their SecurityScheme origin
 var middlewareRegistry = make(map[uintptr]SecurityScheme)
 
 // RegisterSecurityScheme registers security schemes for a route
-func RegisterSecurityScheme(method, path string, schemes ...SecurityScheme) {
-	key := method + " " + path
-	securitySchemeRegistry[key] = append(securitySchemeRegistry[key], schemes...)
+func (r *Registry) RegisterSecurityScheme(method, path string, schemes ...SecurityScheme) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := routeKey(method, path)
+	r.securitySchemes[key] = append(r.securitySchemes[key], schemes...)
 }
 
 // GetSecuritySchemes retrieves security schemes for a route
+func (r *Registry) GetSecuritySchemes(method, path string) []SecurityScheme {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.securitySchemes[routeKey(method, path)]
+}
+
+// RegisterSecurityScheme registers security schemes for a route in the global registry
+func RegisterSecurityScheme(method, path string, schemes ...SecurityScheme) {
+	defaultRegistry.RegisterSecurityScheme(method, path, schemes...)
+}
+
+// GetSecuritySchemes retrieves security schemes for a route from the global registry
 func GetSecuritySchemes(method, path string) []SecurityScheme {
-	key := method + " " + path
-	return securitySchemeRegistry[key]
+	return defaultRegistry.GetSecuritySchemes(method, path)
 }
 
-// ClearSecuritySchemes clears all registered security schemes (useful for testing)
+// ClearSecuritySchemes clears all security schemes of the global registry (useful for testing)
 func ClearSecuritySchemes() {
-	securitySchemeRegistry = make(map[string][]SecurityScheme)
+	defaultRegistry.mu.Lock()
+	defaultRegistry.securitySchemes = make(map[string][]SecurityScheme)
+	defaultRegistry.mu.Unlock()
 	middlewareRegistry = make(map[uintptr]SecurityScheme)
 }
 
@@ -78,6 +91,8 @@ type APIKeyConfig struct {
 	In          APIKeyLocation                           // Location: "header", "query", or "cookie"
 	KeyName     string                                   // The name of the header, query parameter, or cookie
 	ValidateKey func(c *gin.Context, apiKey string) bool // Function to validate the API key
+	Store       APIKeyStore                              // Hashed key lookup, checked before ValidateKey (optional)
+	BruteForce  *BruteForceProtector                     // Locks out callers after repeated invalid keys (optional)
 }
 
 // BearerConfig holds configuration for Bearer token security schemes
@@ -86,6 +101,7 @@ type BearerConfig struct {
 	Description   string                                  // Description for OpenAPI documentation (optional)
 	BearerFormat  string                                  // Bearer format (e.g., "JWT") (optional)
 	ValidateToken func(c *gin.Context, token string) bool // Function to validate the bearer token
+	BruteForce    *BruteForceProtector                    // Locks out callers after repeated invalid tokens (optional)
 }
 
 // APIKeySecurity implements API key authentication
@@ -96,6 +112,8 @@ type APIKeySecurity struct {
 	In          APIKeyLocation                           // "header", "query", or "cookie"
 	KeyName     string                                   // The name of the header, query parameter, or cookie
 	ValidateKey func(c *gin.Context, apiKey string) bool // Function to validate the API key
+	Store       APIKeyStore                              // Hashed key lookup, checked before ValidateKey (optional)
+	BruteForce  *BruteForceProtector                     // Locks out callers after repeated invalid keys (optional)
 }
 
 // BearerSecurity implements Bearer token authentication
@@ -105,6 +123,7 @@ type BearerSecurity struct {
 	Description   string                                  // Description for OpenAPI documentation
 	BearerFormat  string                                  // Bearer format (e.g., "JWT")
 	ValidateToken func(c *gin.Context, token string) bool // Function to validate the bearer token
+	BruteForce    *BruteForceProtector                    // Locks out callers after repeated invalid tokens (optional)
 }
 
 // GetSecurityScheme returns the OpenAPI security scheme definition
@@ -125,6 +144,13 @@ func (a *APIKeySecurity) GetSecurityScheme() (string, map[string]interface{}) {
 // Middleware returns the gin.HandlerFunc for API key authentication
 func (a *APIKeySecurity) Middleware() gin.HandlerFunc {
 	handler := func(c *gin.Context) {
+		start := auditStart(c)
+		defer auditRequest(c, start)
+
+		if a.BruteForce.rejectIfLocked(c) {
+			return
+		}
+
 		var apiKey string
 
 		switch a.In {
@@ -161,7 +187,8 @@ func (a *APIKeySecurity) Middleware() gin.HandlerFunc {
 		}
 
 		// Validate the API key
-		if a.ValidateKey != nil && !a.ValidateKey(c, apiKey) {
+		if !a.validateAPIKey(c, apiKey) {
+			a.BruteForce.recordFailure(c)
 			c.JSON(401, ErrorResult{
 				Success: false,
 				ErrorInfo: Error{
@@ -174,8 +201,11 @@ func (a *APIKeySecurity) Middleware() gin.HandlerFunc {
 			return
 		}
 
+		a.BruteForce.recordSuccess(c)
+
 		// Store API key for handler use
 		c.Set("api_key", apiKey)
+		c.Set("auth_method", "api_key")
 		c.Next()
 	}
 
@@ -205,6 +235,13 @@ func (b *BearerSecurity) GetSecurityScheme() (string, map[string]interface{}) {
 // Middleware returns the gin.HandlerFunc for Bearer token authentication
 func (b *BearerSecurity) Middleware() gin.HandlerFunc {
 	handler := func(c *gin.Context) {
+		start := auditStart(c)
+		defer auditRequest(c, start)
+
+		if b.BruteForce.rejectIfLocked(c) {
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(401, ErrorResult{
@@ -249,6 +286,7 @@ func (b *BearerSecurity) Middleware() gin.HandlerFunc {
 
 		// Validate the token
 		if b.ValidateToken != nil && !b.ValidateToken(c, token) {
+			b.BruteForce.recordFailure(c)
 			c.JSON(401, ErrorResult{
 				Success: false,
 				ErrorInfo: Error{
@@ -261,8 +299,11 @@ func (b *BearerSecurity) Middleware() gin.HandlerFunc {
 			return
 		}
 
+		b.BruteForce.recordSuccess(c)
+
 		// Store token for handler use
 		c.Set("bearer_token", token)
+		c.Set("auth_method", "bearer")
 		c.Next()
 	}
 
@@ -279,6 +320,8 @@ func NewAPIKeySecurity(config APIKeyConfig) *APIKeySecurity {
 		In:          config.In,
 		KeyName:     config.KeyName,
 		ValidateKey: config.ValidateKey,
+		Store:       config.Store,
+		BruteForce:  config.BruteForce,
 	}
 }
 
@@ -289,6 +332,7 @@ func NewBearerSecurity(config BearerConfig) *BearerSecurity {
 		Description:   config.Description,
 		BearerFormat:  config.BearerFormat,
 		ValidateToken: config.ValidateToken,
+		BruteForce:    config.BruteForce,
 	}
 }
 
@@ -319,6 +363,9 @@ func (m *MultiSecurity) GetComponentSchemes() []SecurityScheme {
 // Middleware returns a gin.HandlerFunc that tries each security scheme in order
 func (m *MultiSecurity) Middleware() gin.HandlerFunc {
 	handler := func(c *gin.Context) {
+		start := auditStart(c)
+		defer auditRequest(c, start)
+
 		// Try each security scheme in order
 		for _, scheme := range m.Schemes {
 			// Try this scheme's middleware directly on the context
@@ -327,6 +374,10 @@ func (m *MultiSecurity) Middleware() gin.HandlerFunc {
 				c.Next()
 				return
 			}
+			// A locked out caller has been answered with 429
+			if c.IsAborted() {
+				return
+			}
 		}
 
 		// None of the schemes worked
@@ -353,6 +404,37 @@ func (m *MultiSecurity) tryScheme(scheme SecurityScheme, c *gin.Context) bool {
 		return m.tryAPIKey(s, c)
 	case *BearerSecurity:
 		return m.tryBearer(s, c)
+	case *SignedURLSecurity:
+		if s.Verify(c.Request.Method, c.Request.URL) != nil {
+			return false
+		}
+		c.Set("auth_method", "signed_url")
+		return true
+	case *IntrospectionSecurity:
+		if s.BruteForce.rejectIfLocked(c) {
+			return false
+		}
+		scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !strings.EqualFold(scheme, "bearer") || token == "" {
+			return false
+		}
+		introspection, err := s.Verify(token)
+		if err != nil {
+			if errors.Is(err, ErrTokenInactive) {
+				s.BruteForce.recordFailure(c)
+			}
+			return false
+		}
+		s.BruteForce.recordSuccess(c)
+		s.attach(c, token, introspection)
+		return true
+	case *HMACSignatureSecurity:
+		body, err := bufferBody(c, s.MaxBodySize)
+		if err != nil || s.Verify(body, c.GetHeader(s.SignatureHeader), c.GetHeader(s.TimestampHeader)) != nil {
+			return false
+		}
+		c.Set("auth_method", "hmac_signature")
+		return true
 	default:
 		// For custom security schemes, we'd need a different approach
 		// For now, return false for unknown types
@@ -362,6 +444,10 @@ func (m *MultiSecurity) tryScheme(scheme SecurityScheme, c *gin.Context) bool {
 
 // tryAPIKey attempts API key authentication
 func (m *MultiSecurity) tryAPIKey(apiKey *APIKeySecurity, c *gin.Context) bool {
+	if apiKey.BruteForce.rejectIfLocked(c) {
+		return false
+	}
+
 	var key string
 
 	switch apiKey.In {
@@ -379,10 +465,13 @@ func (m *MultiSecurity) tryAPIKey(apiKey *APIKeySecurity, c *gin.Context) bool {
 		return false
 	}
 
-	if apiKey.ValidateKey != nil && !apiKey.ValidateKey(c, key) {
+	if !apiKey.validateAPIKey(c, key) {
+		apiKey.BruteForce.recordFailure(c)
 		return false
 	}
 
+	apiKey.BruteForce.recordSuccess(c)
+
 	// Store the API key for handler use
 	c.Set("api_key", key)
 	c.Set("auth_method", "api_key")
@@ -391,6 +480,10 @@ func (m *MultiSecurity) tryAPIKey(apiKey *APIKeySecurity, c *gin.Context) bool {
 
 // tryBearer attempts Bearer token authentication
 func (m *MultiSecurity) tryBearer(bearer *BearerSecurity, c *gin.Context) bool {
+	if bearer.BruteForce.rejectIfLocked(c) {
+		return false
+	}
+
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
 		return false
@@ -406,9 +499,12 @@ func (m *MultiSecurity) tryBearer(bearer *BearerSecurity, c *gin.Context) bool {
 	}
 
 	if bearer.ValidateToken != nil && !bearer.ValidateToken(c, token) {
+		bearer.BruteForce.recordFailure(c)
 		return false
 	}
 
+	bearer.BruteForce.recordSuccess(c)
+
 	// Store the token for handler use
 	c.Set("bearer_token", token)
 	c.Set("auth_method", "bearer")
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces `sensitive:"true"` string fields in redacted output
+const RedactedValue = "[REDACTED]"
+
+func isSensitiveField(field reflect.StructField) bool {
+	return field.Tag.Get("sensitive") == "true"
+}
+
+// Redact returns a copy of v with every `sensitive:"true"` field replaced:
+// strings become RedactedValue and other kinds become their zero value.
+// Use it before logging schemas or responses. v itself is never modified.
+func Redact(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	copied := deepCopyValue(reflect.ValueOf(v))
+	redactValue(copied)
+	return copied.Interface()
+}
+
+func redactValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			redactValue(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			redactValue(v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !t.Field(i).IsExported() || !field.CanSet() {
+				continue
+			}
+
+			if !isSensitiveField(t.Field(i)) {
+				redactValue(field)
+				continue
+			}
+
+			if field.Kind() == reflect.String {
+				field.SetString(RedactedValue)
+			} else {
+				field.Set(reflect.Zero(field.Type()))
+			}
+		}
+	}
+}
+
+// sensitiveJSONFields returns the json names of all sensitive fields reachable from t
+func sensitiveJSONFields(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	collectSensitiveJSONFields(t, names, map[reflect.Type]bool{})
+	return names
+}
+
+func collectSensitiveJSONFields(t reflect.Type, names map[string]bool, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if isSensitiveField(field) {
+			names[getJSONFieldName(field)] = true
+		}
+		collectSensitiveJSONFields(field.Type, names, seen)
+	}
+}
+
+// redactJSONError drops the offending value from JSON decoding errors on sensitive fields
+func redactJSONError(err error, t reflect.Type) error {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field == "" {
+		return err
+	}
+
+	path := strings.Split(typeErr.Field, ".")
+	name := path[len(path)-1]
+	if !sensitiveJSONFields(t)[name] {
+		return err
+	}
+
+	return fmt.Errorf("field '%s' has an invalid type", name)
+}
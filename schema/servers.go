@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"path"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server is an entry of the spec's servers list
+type Server struct {
+	URL         string `json:"url" yaml:"url"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// requestServer returns the server the request reached, honoring
+// X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Prefix from proxies
+func requestServer(c *gin.Context) Server {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := firstHeaderValue(c.GetHeader("X-Forwarded-Proto")); proto != "" {
+		scheme = proto
+	}
+
+	host := c.Request.Host
+	if forwarded := firstHeaderValue(c.GetHeader("X-Forwarded-Host")); forwarded != "" {
+		host = forwarded
+	}
+
+	prefix := strings.TrimSuffix(firstHeaderValue(c.GetHeader("X-Forwarded-Prefix")), "/")
+
+	return Server{
+		URL:         scheme + "://" + host + prefix,
+		Description: "Current server",
+	}
+}
+
+// firstHeaderValue returns the first entry of a comma separated proxy header
+func firstHeaderValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(first)
+}
+
+// withRequestServer returns a copy of the spec with the request's server listed first
+func (o *OpenAPISpec) withRequestServer(c *gin.Context) *OpenAPISpec {
+	current := requestServer(c)
+
+	spec := *o
+	spec.snapshots = nil
+	spec.Servers = []Server{current}
+	for _, server := range o.Servers {
+		if server.URL != current.URL {
+			spec.Servers = append(spec.Servers, server)
+		}
+	}
+	return &spec
+}
+
+// applyBuildInfo fills an empty title and version from the binary's build
+// information and records the VCS revision as info extensions
+func applyBuildInfo(info *Info) {
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	if info.Title == "" && build.Main.Path != "" {
+		info.Title = path.Base(build.Main.Path)
+	}
+
+	settings := make(map[string]string)
+	for _, setting := range build.Settings {
+		settings[setting.Key] = setting.Value
+	}
+
+	if info.Version == "" {
+		info.Version = buildVersion(build.Main.Version, settings)
+	}
+
+	if info.Extensions == nil {
+		info.Extensions = Extensions{}
+	}
+	if revision := settings["vcs.revision"]; revision != "" {
+		if _, exists := info.Extensions["x-vcs-revision"]; !exists {
+			info.Extensions["x-vcs-revision"] = revision
+		}
+	}
+	if vcsTime := settings["vcs.time"]; vcsTime != "" {
+		if _, exists := info.Extensions["x-vcs-time"]; !exists {
+			info.Extensions["x-vcs-time"] = vcsTime
+		}
+	}
+}
+
+// buildVersion prefers the module version, then the short VCS revision
+func buildVersion(moduleVersion string, settings map[string]string) string {
+	if moduleVersion != "" && moduleVersion != "(devel)" {
+		return moduleVersion
+	}
+
+	revision := settings["vcs.revision"]
+	if revision == "" {
+		return "dev"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if settings["vcs.modified"] == "true" {
+		revision += "-dirty"
+	}
+	return revision
+}
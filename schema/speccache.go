@@ -0,0 +1,204 @@
+package schema
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// typeDocKey identifies the reflected documentation of a handler's types.
+// The global and route naming strategies and the top-level field mode
+// change the result, so they are part of the key.
+type typeDocKey struct {
+	schemaType   reflect.Type
+	responseType reflect.Type
+	method       string
+	fieldMode    TopLevelFieldMode
+	naming       uintptr
+	routeNaming  uintptr
+}
+
+// typeDoc is what reflection yields for a handler's types: its parameters,
+// request body and response data, and the component schemas they refer to
+type typeDoc struct {
+	parameters  []Parameter
+	requestBody *RequestBody
+	data        *JSONSchema
+	schemas     map[string]*JSONSchema
+}
+
+var (
+	typeDocCacheMu sync.Mutex
+	typeDocCache   = make(map[typeDocKey]*typeDoc)
+)
+
+// reflectTypeDoc documents the schema and response types of a route,
+// reflecting each combination once per process. Routes sharing their types
+// reuse the result, which keeps generation fast for routers with thousands
+// of routes. The returned values are copies the caller may modify, and the
+// component schemas are added to schemas unless a schema of the same name
+// is already there. The response data is documented under naming, the
+// route's strategy, when set.
+func reflectTypeDoc(schemaType, responseType reflect.Type, method string, naming NamingStrategy, schemas map[string]*JSONSchema) ([]Parameter, *RequestBody, *JSONSchema) {
+	key := typeDocKey{
+		schemaType:   schemaType,
+		responseType: responseType,
+		method:       method,
+		fieldMode:    topLevelFieldMode,
+		naming:       namingStrategyID(globalNamingStrategy),
+		routeNaming:  namingStrategyID(naming),
+	}
+	if naming == nil {
+		naming = globalNamingStrategy
+	}
+
+	typeDocCacheMu.Lock()
+	doc, exists := typeDocCache[key]
+	if !exists {
+		doc = &typeDoc{schemas: make(map[string]*JSONSchema)}
+		if schemaType != nil {
+			doc.parameters = extractParameters(schemaType, method, doc.schemas)
+			doc.requestBody = extractRequestBody(schemaType, method, doc.schemas)
+		}
+		if responseType != nil {
+			doc.data = generateJSONSchemaWithNaming(responseType, doc.schemas, naming)
+		}
+		typeDocCache[key] = doc
+	}
+	typeDocCacheMu.Unlock()
+
+	for name, schema := range doc.schemas {
+		if _, exists := schemas[name]; !exists {
+			schemas[name] = cloneSchema(schema)
+		}
+	}
+
+	var parameters []Parameter
+	for _, parameter := range doc.parameters {
+		parameter.Schema = cloneSchema(parameter.Schema)
+		parameter.Extensions = mergeExtensions(nil, parameter.Extensions)
+		parameters = append(parameters, parameter)
+	}
+
+	var requestBody *RequestBody
+	if doc.requestBody != nil {
+		body := *doc.requestBody
+		body.Content = cloneContent(body.Content)
+		requestBody = &body
+	}
+
+	return parameters, requestBody, cloneSchema(doc.data)
+}
+
+// cloneSchema deep-copies a schema so later edits, such as the $ref
+// rewrites of Compact, leave the cached original alone
+func cloneSchema(schema *JSONSchema) *JSONSchema {
+	if schema == nil {
+		return nil
+	}
+
+	clone := *schema
+	if schema.Properties != nil {
+		clone.Properties = make(map[string]*JSONSchema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			clone.Properties[name] = cloneSchema(property)
+		}
+	}
+	clone.Items = cloneSchema(schema.Items)
+	if additional, ok := schema.AdditionalProperties.(*JSONSchema); ok {
+		clone.AdditionalProperties = cloneSchema(additional)
+	}
+	clone.Required = append([]string(nil), schema.Required...)
+	clone.Enum = append([]interface{}(nil), schema.Enum...)
+	clone.Extensions = mergeExtensions(nil, schema.Extensions)
+	return &clone
+}
+
+func cloneContent(content map[string]MediaType) map[string]MediaType {
+	if content == nil {
+		return nil
+	}
+
+	clone := make(map[string]MediaType, len(content))
+	for contentType, mediaType := range content {
+		mediaType.Schema = cloneSchema(mediaType.Schema)
+		if mediaType.Examples != nil {
+			examples := make(map[string]ExampleObject, len(mediaType.Examples))
+			for name, example := range mediaType.Examples {
+				examples[name] = example
+			}
+			mediaType.Examples = examples
+		}
+		clone[contentType] = mediaType
+	}
+	return clone
+}
+
+// CachedSpec generates the spec of an engine once and serves it until
+// Invalidate is called, so large routers are not reflected on every request
+// for the documentation:
+//
+//	docs := schema.NewCachedSpec(router.Engine, &schema.OpenAPIOpts{Title: "My API"})
+//	docs.Pregenerate()
+//	router.GET("/swagger.json", docs.HandleGetSwagger)
+type CachedSpec struct {
+	engine *gin.Engine
+	opts   *OpenAPIOpts
+
+	mu      sync.Mutex
+	current *specGeneration
+}
+
+// specGeneration is one generation of a CachedSpec, ready when its spec is set
+type specGeneration struct {
+	ready chan struct{}
+	spec  *OpenAPISpec
+}
+
+// NewCachedSpec returns a spec of engine that is generated on first use
+func NewCachedSpec(engine *gin.Engine, opts *OpenAPIOpts) *CachedSpec {
+	return &CachedSpec{engine: engine, opts: opts}
+}
+
+// Pregenerate starts generating the spec in the background, typically
+// after all routes are registered, so the first request does not wait
+func (s *CachedSpec) Pregenerate() {
+	s.generation()
+}
+
+// Spec returns the generated spec, waiting for a generation in progress
+func (s *CachedSpec) Spec() *OpenAPISpec {
+	generation := s.generation()
+	<-generation.ready
+	return generation.spec
+}
+
+// Invalidate discards the spec, so the next Spec or Pregenerate generates
+// it again. Call it after adding routes.
+func (s *CachedSpec) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = nil
+}
+
+// HandleGetSwagger serves the cached spec like OpenAPISpec.HandleGetSwagger
+func (s *CachedSpec) HandleGetSwagger(c *gin.Context) {
+	s.Spec().HandleGetSwagger(c)
+}
+
+// generation returns the current generation, starting one when there is none
+func (s *CachedSpec) generation() *specGeneration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		generation := &specGeneration{ready: make(chan struct{})}
+		go func() {
+			defer close(generation.ready)
+			generation.spec = OpenAPI(s.engine, s.opts)
+		}()
+		s.current = generation
+	}
+	return s.current
+}
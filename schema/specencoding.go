@@ -0,0 +1,150 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Global YAML indentation of written and served specs
+var yamlIndent = 4
+
+// SetYAMLIndent sets the number of spaces per level in YAML specs
+func SetYAMLIndent(spaces int) {
+	yamlIndent = spaces
+}
+
+// GetYAMLIndent returns the number of spaces per level in YAML specs
+func GetYAMLIndent() int {
+	return yamlIndent
+}
+
+// marshalYAML encodes a spec with the configured indentation
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(yamlIndent)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// orderedProperties writes schema properties in declaration order, so
+// generated specs list fields as the Go struct does. Keys without a
+// recorded position follow in alphabetical order.
+type orderedProperties struct {
+	keys       []string
+	properties map[string]*JSONSchema
+}
+
+// orderedProperties returns the properties of s in output order, or nil when it has none
+func (s JSONSchema) orderedProperties() *orderedProperties {
+	if len(s.Properties) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(s.Properties))
+	placed := make(map[string]bool, len(s.Properties))
+	for _, key := range s.propertyOrder {
+		if _, exists := s.Properties[key]; exists && !placed[key] {
+			keys = append(keys, key)
+			placed[key] = true
+		}
+	}
+
+	var rest []string
+	for key := range s.Properties {
+		if !placed[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return &orderedProperties{keys: append(keys, rest...), properties: s.Properties}
+}
+
+func (p orderedProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range p.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.properties[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (p orderedProperties) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range p.keys {
+		value := &yaml.Node{}
+		if err := value.Encode(p.properties[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	return node, nil
+}
+
+func (s JSONSchema) MarshalYAML() (interface{}, error) {
+	type jsonSchema JSONSchema
+	schema := jsonSchema(s)
+	schema.Properties = nil
+
+	node := &yaml.Node{}
+	if err := node.Encode(schema); err != nil {
+		return nil, err
+	}
+
+	properties := s.orderedProperties()
+	if properties == nil {
+		return node, nil
+	}
+
+	value := &yaml.Node{}
+	if err := value.Encode(properties); err != nil {
+		return nil, err
+	}
+
+	// Properties follow type, as declared on JSONSchema
+	at := 0
+	if s.Type != "" {
+		at = 2
+	}
+	content := append([]*yaml.Node{}, node.Content[:at]...)
+	content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Value: "properties"}, value)
+	node.Content = append(content, node.Content[at:]...)
+	return node, nil
+}
+
+// canonicalJSON encodes v with object keys sorted, for comparing schemas
+// regardless of property order
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
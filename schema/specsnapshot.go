@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// specSnapshot is a spec serialized once for HandleGetSwagger
+type specSnapshot struct {
+	body []byte
+	etag string
+}
+
+// specSnapshotsMu guards the snapshots of every spec
+var specSnapshotsMu sync.Mutex
+
+// snapshot returns the spec serialized in format, marshaling it on first use
+func (o *OpenAPISpec) snapshot(format OutputFormat) *specSnapshot {
+	specSnapshotsMu.Lock()
+	defer specSnapshotsMu.Unlock()
+
+	if snapshot, exists := o.snapshots[format]; exists {
+		return snapshot
+	}
+	if o.snapshots == nil {
+		o.snapshots = make(map[OutputFormat]*specSnapshot)
+	}
+	snapshot := newSpecSnapshot(o, format)
+	o.snapshots[format] = snapshot
+	return snapshot
+}
+
+func newSpecSnapshot(o *OpenAPISpec, format OutputFormat) *specSnapshot {
+	var body []byte
+	if format == OutputFormatJSON {
+		body = []byte(o.toJSON())
+	} else {
+		body = []byte(o.toYAML())
+	}
+
+	sum := sha256.Sum256(body)
+	return &specSnapshot{body: body, etag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+}
+
+// Invalidate discards the serialized JSON and YAML that HandleGetSwagger
+// serves, so changes made to the spec after it was first served are picked
+// up. The spec is serialized again on the next request.
+func (o *OpenAPISpec) Invalidate() {
+	specSnapshotsMu.Lock()
+	defer specSnapshotsMu.Unlock()
+	o.snapshots = nil
+}
+
+// serveSnapshot writes a serialized spec with its ETag, or 304 Not Modified
+// when the client already has it
+func serveSnapshot(c *gin.Context, snapshot *specSnapshot, contentType string) {
+	c.Header("ETag", snapshot.etag)
+	if etagMatches(c.GetHeader("If-None-Match"), snapshot.etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, snapshot.body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires for this header
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrBodyTooDeep      = errors.New("request body is nested too deeply")
+	ErrBodyTooManyItems = errors.New("request body has too many items")
+)
+
+// BodyLimits configures how request bodies are decoded
+type BodyLimits struct {
+	MaxBytes int64 // Maximum body size in bytes, 0 for unlimited
+	MaxDepth int   // Maximum nesting of objects and arrays, 0 for unlimited
+	MaxItems int   // Maximum number of elements in a StreamBody, 0 for unlimited
+	Stream   bool  // Decode Body sections with a json.Decoder instead of buffering the whole body
+}
+
+// Global body decoding configuration
+var globalBodyLimits BodyLimits
+
+// SetBodyLimits sets the global body decoding limits.
+// MaxBytes and MaxDepth apply to Body sections when Stream is set, and always to StreamBody.
+func SetBodyLimits(limits BodyLimits) {
+	globalBodyLimits = limits
+}
+
+// GetBodyLimits returns the current body decoding limits
+func GetBodyLimits() BodyLimits {
+	return globalBodyLimits
+}
+
+// limitedBody wraps the request body with the configured size and depth limits
+func limitedBody(c *gin.Context, limits BodyLimits) io.Reader {
+	var body io.Reader = c.Request.Body
+	if limits.MaxBytes > 0 {
+		body = http.MaxBytesReader(c.Writer, c.Request.Body, limits.MaxBytes)
+	}
+	if limits.MaxDepth > 0 {
+		body = &depthLimitReader{r: body, maxDepth: limits.MaxDepth}
+	}
+	return body
+}
+
+// decodeBodyStream decodes the body into v without buffering it first
+func decodeBodyStream(c *gin.Context, v interface{}) error {
+	return json.NewDecoder(limitedBody(c, globalBodyLimits)).Decode(v)
+}
+
+// depthLimitReader fails once the JSON passing through it nests deeper than maxDepth
+type depthLimitReader struct {
+	r        io.Reader
+	maxDepth int
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+func (d *depthLimitReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	for _, b := range p[:n] {
+		switch {
+		case d.escaped:
+			d.escaped = false
+		case d.inString:
+			switch b {
+			case '\\':
+				d.escaped = true
+			case '"':
+				d.inString = false
+			}
+		case b == '"':
+			d.inString = true
+		case b == '{' || b == '[':
+			d.depth++
+			if d.depth > d.maxDepth {
+				return 0, ErrBodyTooDeep
+			}
+		case b == '}' || b == ']':
+			d.depth--
+		}
+	}
+	return n, err
+}
+
+// streamBinder is implemented by *StreamBody to take over the request body
+type streamBinder interface {
+	bindStream(body io.Reader, maxItems int)
+}
+
+// streamElement is implemented by StreamBody to expose its element type
+type streamElement interface {
+	streamElementType() reflect.Type
+}
+
+// StreamBody is used as the Body section of a schema to receive a JSON array
+// one element at a time instead of decoding it all up front. Each element is
+// sanitized, decrypted and validated as it is read.
+//
+//	type ImportSchema struct {
+//		Body schema.StreamBody[Record]
+//	}
+type StreamBody[T any] struct {
+	state *streamState
+}
+
+type streamState struct {
+	decoder  *json.Decoder
+	maxItems int
+	count    int
+	started  bool
+	done     bool
+	err      error
+}
+
+func (s *StreamBody[T]) bindStream(body io.Reader, maxItems int) {
+	s.state = &streamState{
+		decoder:  json.NewDecoder(body),
+		maxItems: maxItems,
+	}
+}
+
+func (s StreamBody[T]) streamElementType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Next decodes the next element. It returns false at the end of the array or
+// on error, check Err afterwards.
+func (s StreamBody[T]) Next() (T, bool) {
+	var item T
+	state := s.state
+	if state == nil || state.done {
+		return item, false
+	}
+
+	if !state.started {
+		state.started = true
+		if err := expectDelim(state.decoder, '['); err != nil {
+			return item, state.fail(err)
+		}
+	}
+
+	if !state.decoder.More() {
+		state.done = true
+		if err := expectDelim(state.decoder, ']'); err != nil {
+			return item, state.fail(err)
+		}
+		return item, false
+	}
+
+	if state.maxItems > 0 && state.count >= state.maxItems {
+		return item, state.fail(ErrBodyTooManyItems)
+	}
+
+	index := state.count
+	state.count++
+	if err := state.decoder.Decode(&item); err != nil {
+		return item, state.fail(fmt.Errorf("item %d: %w", index, err))
+	}
+
+	if err := prepareStreamItem(reflect.ValueOf(&item).Elem()); err != nil {
+		return item, state.fail(fmt.Errorf("item %d: %w", index, err))
+	}
+
+	return item, true
+}
+
+// Err returns the error that stopped iteration, if any
+func (s StreamBody[T]) Err() error {
+	if s.state == nil {
+		return nil
+	}
+	return s.state.err
+}
+
+// Count returns the number of elements read so far
+func (s StreamBody[T]) Count() int {
+	if s.state == nil {
+		return 0
+	}
+	return s.state.count
+}
+
+// All returns an iterator over the remaining elements. Iteration stops after
+// the first error, which is yielded with a zero element.
+func (s StreamBody[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			item, ok := s.Next()
+			if !ok {
+				if err := s.Err(); err != nil {
+					var zero T
+					yield(zero, err)
+				}
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (state *streamState) fail(err error) bool {
+	state.done = true
+	state.err = fmt.Errorf("body validation failed: %w", err)
+	return false
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected '%s' in request body", delim)
+	}
+	return nil
+}
+
+// prepareStreamItem applies the same processing to an element as parseSchema does to a body
+func prepareStreamItem(v reflect.Value) error {
+	if err := decryptFields(v); err != nil {
+		return err
+	}
+	if err := sanitizeFields(v); err != nil {
+		return err
+	}
+
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		return validate.Struct(v.Interface())
+	}
+	return nil
+}
+
+// isStreamBody reports whether t is a StreamBody, returning its element type
+func isStreamBody(t reflect.Type) (reflect.Type, bool) {
+	if element, ok := reflect.Zero(t).Interface().(streamElement); ok {
+		return element.streamElementType(), true
+	}
+	return nil, false
+}
+
+func isStreamBodyType(t reflect.Type) bool {
+	_, ok := isStreamBody(t)
+	return ok
+}
@@ -0,0 +1,361 @@
+package schema
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TabularFormat is a set of export formats, combine them with |
+type TabularFormat int
+
+const (
+	CSV TabularFormat = 1 << iota
+	XLSX
+)
+
+const (
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// TabularResult is a handler response streamed as a CSV or XLSX file
+type TabularResult[T any] struct {
+	Rows       []T
+	Formats    TabularFormat
+	Filename   string
+	RawStrings bool // Write strings that spreadsheets read as formulas unescaped, see WithRawStrings
+}
+
+// Tabular exports rows as a file in one of formats, picked from the Accept
+// header (CSV when the client has no preference). Columns follow the struct
+// field order and are named by the csv tag, then the json tag, then the
+// field name; fields tagged csv:"-" are skipped.
+//
+// String cells starting with =, +, -, @, a tab or a carriage return are
+// prefixed with a single quote, so a spreadsheet shows them as text rather
+// than running them as formulas. NaN and infinite numbers are left empty.
+func Tabular[T any](rows []T, formats TabularFormat) *TabularResult[T] {
+	if formats&(CSV|XLSX) == 0 {
+		formats = CSV
+	}
+	return &TabularResult[T]{
+		Rows:     rows,
+		Formats:  formats,
+		Filename: "export",
+	}
+}
+
+// WithFilename sets the download filename, the extension is added per format
+func (r *TabularResult[T]) WithFilename(name string) *TabularResult[T] {
+	r.Filename = name
+	return r
+}
+
+// WithRawStrings writes string cells as they are, without escaping the ones
+// spreadsheets would read as formulas. Only use it for trusted data.
+func (r *TabularResult[T]) WithRawStrings() *TabularResult[T] {
+	r.RawStrings = true
+	return r
+}
+
+func (r TabularResult[T]) tabularRowType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// writeResponse negotiates the format and streams the rows
+func (r TabularResult[T]) writeResponse(c *gin.Context) error {
+	offered := []string{}
+	if r.Formats&CSV != 0 {
+		offered = append(offered, csvContentType)
+	}
+	if r.Formats&XLSX != 0 {
+		offered = append(offered, xlsxContentType)
+	}
+
+	contentType := c.NegotiateFormat(offered...)
+	if contentType == "" {
+		contentType = offered[0]
+	}
+
+	columns := tabularColumns(r.tabularRowType())
+	records := make([][]interface{}, len(r.Rows))
+	for i, row := range r.Rows {
+		records[i] = tabularRecord(reflect.ValueOf(row), columns)
+	}
+
+	extension := ".csv"
+	if contentType == xlsxContentType {
+		extension = ".xlsx"
+	}
+	filename := r.Filename
+	if filename == "" {
+		filename = "export"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+extension))
+	c.Status(200)
+
+	if !r.RawStrings {
+		for _, record := range records {
+			escapeFormulas(record)
+		}
+	}
+
+	if contentType == xlsxContentType {
+		return writeXLSX(c.Writer, columns, records)
+	}
+	return writeCSV(c.Writer, columns, records)
+}
+
+type tabularResponder interface {
+	tabularRowType() reflect.Type
+	writeResponse(c *gin.Context) error
+}
+
+var tabularResponderType = reflect.TypeOf((*tabularResponder)(nil)).Elem()
+
+// isTabularType reports whether a handler response type is a TabularResult
+// and returns its row type
+func isTabularType(t reflect.Type) (reflect.Type, bool) {
+	if t == nil || !t.Implements(tabularResponderType) {
+		return nil, false
+	}
+	return reflect.Zero(t).Interface().(tabularResponder).tabularRowType(), true
+}
+
+type tabularColumn struct {
+	name  string
+	index []int
+}
+
+// tabularColumns lists the exported columns of a row type
+func tabularColumns(rowType reflect.Type) []tabularColumn {
+	for rowType.Kind() == reflect.Ptr {
+		rowType = rowType.Elem()
+	}
+	if rowType.Kind() != reflect.Struct {
+		return []tabularColumn{{name: "value"}}
+	}
+
+	var columns []tabularColumn
+	for _, field := range reflect.VisibleFields(rowType) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name := field.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = getJSONFieldName(field)
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, tabularColumn{name: name, index: field.Index})
+	}
+	return columns
+}
+
+// tabularRecord extracts the cell values of one row
+func tabularRecord(row reflect.Value, columns []tabularColumn) []interface{} {
+	for row.Kind() == reflect.Ptr || row.Kind() == reflect.Interface {
+		if row.IsNil() {
+			return make([]interface{}, len(columns))
+		}
+		row = row.Elem()
+	}
+
+	record := make([]interface{}, len(columns))
+	for i, column := range columns {
+		if column.index == nil {
+			record[i] = tabularCell(row)
+			continue
+		}
+		field, err := row.FieldByIndexErr(column.index)
+		if err != nil {
+			continue
+		}
+		record[i] = tabularCell(field)
+	}
+	return record
+}
+
+// tabularCell converts a field to a string or number cell, nil for empty cells
+func tabularCell(value reflect.Value) interface{} {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint()
+	case reflect.Float32, reflect.Float64:
+		// Spreadsheets have no NaN or infinity
+		if math.IsNaN(value.Float()) || math.IsInf(value.Float(), 0) {
+			return nil
+		}
+		return value.Float()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.String:
+		return value.String()
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, value.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprint(tabularCell(value.Index(i)))
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+func formatTabularCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(cell)
+}
+
+// escapeFormulas prefixes string cells a spreadsheet would evaluate as a
+// formula with a single quote, guarding against CSV and formula injection
+func escapeFormulas(record []interface{}) {
+	for i, cell := range record {
+		if s, ok := cell.(string); ok && s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+			record[i] = "'" + s
+		}
+	}
+}
+
+func writeCSV(w io.Writer, columns []tabularColumn, records [][]interface{}) error {
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	line := make([]string, len(columns))
+	for _, record := range records {
+		for i, cell := range record {
+			line[i] = formatTabularCell(cell)
+		}
+		if err := writer.Write(line); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// xlsxParts are the fixed parts of a single sheet workbook
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// writeXLSX streams a minimal workbook with one sheet of inline string and number cells
+func writeXLSX(w io.Writer, columns []tabularColumn, records [][]interface{}) error {
+	archive := zip.NewWriter(w)
+
+	for _, part := range xlsxParts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	writeXLSXRow(&b, header)
+	if _, err := io.WriteString(sheet, b.String()); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		b.Reset()
+		writeXLSXRow(&b, record)
+		if _, err := io.WriteString(sheet, b.String()); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func writeXLSXRow(b *strings.Builder, record []interface{}) {
+	b.WriteString("<row>")
+	for _, cell := range record {
+		switch v := cell.(type) {
+		case nil:
+			b.WriteString("<c/>")
+		case int64, uint64, float64:
+			b.WriteString(`<c t="n"><v>` + formatTabularCell(v) + `</v></c>`)
+		default:
+			b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(b, []byte(formatTabularCell(v)))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString("</row>")
+}
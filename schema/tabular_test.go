@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"archive/zip"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type tabularTestRow struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+var tabularTestRows = []tabularTestRow{
+	{Name: "=HYPERLINK(\"http://evil\")", Score: math.NaN()},
+	{Name: "-2+3", Score: math.Inf(1)},
+	{Name: "plain", Score: -1.5},
+}
+
+func serveTabular(t *testing.T, accept string, result *TabularResult[tabularTestRow]) []byte {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/export", nil)
+	c.Request.Header.Set("Accept", accept)
+	if err := result.writeResponse(c); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	return w.Body.Bytes()
+}
+
+func TestTabularFormulaEscaping(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		body := string(serveTabular(t, csvContentType, Tabular(tabularTestRows, CSV)))
+		expected := "name,score\n\"'=HYPERLINK(\"\"http://evil\"\")\",\n'-2+3,\nplain,-1.5\n"
+		if body != expected {
+			t.Errorf("unexpected csv:\n%s", body)
+		}
+	})
+
+	t.Run("raw strings", func(t *testing.T) {
+		body := string(serveTabular(t, csvContentType, Tabular(tabularTestRows, CSV).WithRawStrings()))
+		if !strings.Contains(body, "\n-2+3,\n") {
+			t.Errorf("expected the raw string, got:\n%s", body)
+		}
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		body := serveTabular(t, xlsxContentType, Tabular(tabularTestRows, XLSX))
+		archive, err := zip.NewReader(strings.NewReader(string(body)), int64(len(body)))
+		if err != nil {
+			t.Fatalf("invalid xlsx: %v", err)
+		}
+		sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+		if err != nil {
+			t.Fatalf("missing sheet: %v", err)
+		}
+		data, _ := io.ReadAll(sheet)
+
+		if !strings.Contains(string(data), "<t xml:space=\"preserve\">&#39;=HYPERLINK(") {
+			t.Errorf("expected the formula to be escaped, got %s", data)
+		}
+		if strings.Contains(string(data), "NaN") || strings.Contains(string(data), "Inf") {
+			t.Errorf("expected NaN and infinity to be empty cells, got %s", data)
+		}
+	})
+}
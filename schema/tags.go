@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tag groups operations in the spec and documentation UIs
+type Tag struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Tag names the operations of the group's routes and describes the tag,
+// replacing the tag inferred from the group's prefix. Call it before
+// registering routes:
+//
+//	users := router.Group("/users")
+//	users.Tag("Users", "User management")
+func (rg *RouterGroup) Tag(name, description string) *RouterGroup {
+	rg.tag = name
+	if description != "" {
+		rg.helper.Registry().RegisterTagDescription(name, description)
+	}
+	return rg
+}
+
+// inferTag derives a tag from a group prefix: its last segment that is not a
+// path parameter, so /users and /api/v1/users both give "users"
+func inferTag(basePath string) string {
+	segments := strings.Split(strings.Trim(basePath, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if segment != "" && !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			return segment
+		}
+	}
+	return ""
+}
+
+// RegisterRouteTags records the tags of a route's operation
+func (r *Registry) RegisterRouteTags(method, path string, tags ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tags[routeKey(method, path)] = tags
+}
+
+// GetRouteTags retrieves the tags of a route's operation
+func (r *Registry) GetRouteTags(method, path string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tags[routeKey(method, path)]
+}
+
+// RegisterTagDescription records the description of a tag
+func (r *Registry) RegisterTagDescription(name, description string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tagDescriptions[name] = description
+}
+
+// tagDescription returns the description of a tag, if any
+func (r *Registry) tagDescription(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tagDescriptions[name]
+}
+
+// RegisterRouteTags records the tags of a route's operation in the global registry
+func RegisterRouteTags(method, path string, tags ...string) {
+	defaultRegistry.RegisterRouteTags(method, path, tags...)
+}
+
+// GetRouteTags retrieves the tags of a route's operation from the global registry
+func GetRouteTags(method, path string) []string {
+	return defaultRegistry.GetRouteTags(method, path)
+}
+
+// specTags lists the tags used by the spec's operations with their
+// descriptions from registry, sorted by name
+func (o *OpenAPISpec) specTags(registry *Registry) []Tag {
+	used := make(map[string]bool)
+	for _, entry := range o.operations() {
+		for _, name := range entry.Operation.Tags {
+			used[name] = true
+		}
+	}
+
+	var tags []Tag
+	for name := range used {
+		tags = append(tags, Tag{Name: name, Description: registry.tagDescription(name)})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrNoTransactor is returned when a transactional handler has no Transactor
+var ErrNoTransactor = errors.New("no transactor configured")
+
+// Transaction is a unit of work around one handler call
+type Transaction interface {
+	Commit() error
+	Rollback() error
+}
+
+// Transactor begins a transaction for a request
+type Transactor interface {
+	Begin(c *gin.Context) (Transaction, error)
+}
+
+// TransactorFunc adapts a function to Transactor
+type TransactorFunc func(c *gin.Context) (Transaction, error)
+
+func (f TransactorFunc) Begin(c *gin.Context) (Transaction, error) {
+	return f(c)
+}
+
+// Global transactor used by WithTransaction(nil)
+var globalTransactor Transactor
+
+// SetTransactor sets the transactor of handlers marked with WithTransaction(nil)
+func SetTransactor(transactor Transactor) {
+	globalTransactor = transactor
+}
+
+// GetTransactor returns the transactor set with SetTransactor
+func GetTransactor() Transactor {
+	return globalTransactor
+}
+
+// WithTransaction returns a copy of the handler that runs inside a transaction
+// from transactor, or from the global one when transactor is nil. The
+// transaction is committed when the handler succeeds and rolled back when it
+// returns an error or panics; a failed commit answers 500 instead of the result.
+func (t TypedHandlerFunc) WithTransaction(transactor Transactor) TypedHandlerFunc {
+	handler := t.handler
+	t.handler = func(c *gin.Context) {
+		if transactor == nil {
+			c.Set("transactor", globalTransactor)
+		} else {
+			c.Set("transactor", transactor)
+		}
+		handler(c)
+	}
+	return t
+}
+
+// GetTransaction returns the transaction of the current request, for handlers
+// that need the underlying database transaction
+func GetTransaction[T Transaction](c *gin.Context) (T, bool) {
+	var zero T
+	value, exists := c.Get("transaction")
+	if !exists {
+		return zero, false
+	}
+	tx, ok := value.(T)
+	return tx, ok
+}
+
+// beginTransaction starts the transaction of a WithTransaction handler, it
+// returns nil for other handlers
+func beginTransaction(c *gin.Context) (Transaction, error) {
+	value, exists := c.Get("transactor")
+	if !exists {
+		return nil, nil
+	}
+
+	transactor, _ := value.(Transactor)
+	if transactor == nil {
+		return nil, ErrNoTransactor
+	}
+
+	tx, err := transactor.Begin(c)
+	if err != nil {
+		return nil, err
+	}
+	c.Set("transaction", tx)
+	return tx, nil
+}
@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Global status of requests breaking `validate` rules
+var validationStatus = http.StatusBadRequest
+
+// SetValidationStatus sets the status of responses to requests that are
+// well-formed but break `validate` rules (code ERR_VALIDATION_FAILED).
+// Malformed requests, such as invalid JSON or a query value of the wrong
+// type, keep 400. Pass http.StatusUnprocessableEntity to tell the two apart;
+// operations then document both responses.
+func SetValidationStatus(status int) {
+	validationStatus = status
+}
+
+// GetValidationStatus returns the status of requests breaking `validate` rules
+func GetValidationStatus() int {
+	return validationStatus
+}
+
+// parseErrorStatus returns the response status for a request parsing error code
+func parseErrorStatus(code string) int {
+	if code == "ERR_VALIDATION_FAILED" {
+		return validationStatus
+	}
+	return http.StatusBadRequest
+}
+
+// ValidateStruct applies the `mod` sanitizers of v, a pointer to a struct,
+// and checks its `validate` rules with the validator requests are checked
+// with, for values that don't come from a request such as configuration
+func ValidateStruct(v any) error {
+	if err := sanitizeFields(reflect.ValueOf(v)); err != nil {
+		return fmt.Errorf("sanitization failed: %w", err)
+	}
+	return validate.Struct(v)
+}
+
+// BindAndValidate parses and validates the request into T for plain gin
+// handlers. Errors are returned as an ErrorResult with the same codes
+// ValidateAndHandle uses.
+func BindAndValidate[T Schema](c *gin.Context) (T, error) {
+	var schema T
+	if err := parseSchema(c, &schema); err != nil {
+		return schema, convertToErrorResult(err)
+	}
+	return schema, nil
+}
+
+// Validator returns middleware that parses and validates the request into T,
+// answering invalid requests with a wrapped 400. The handlers after it read
+// the schema with GetValidated. Routes registered through RouterHelper
+// document T in the OpenAPI spec.
+func Validator[T Schema]() gin.HandlerFunc {
+	var zero T
+	schemaType := reflect.TypeOf(zero)
+
+	handler := func(c *gin.Context) {
+		schema, err := BindAndValidate[T](c)
+		if err != nil {
+			errorResult := err.(ErrorResult)
+			respondError(c, parseErrorStatus(errorResult.ErrorInfo.Code), errorResult.ErrorInfo.Code, errorResult.ErrorInfo.Message)
+			c.Abort()
+			return
+		}
+
+		c.Set(validatedKey(schemaType), schema)
+		c.Next()
+	}
+
+	registerValidator(handler, schemaType)
+	return handler
+}
+
+// GetValidated returns the schema stored by Validator[T]
+func GetValidated[T Schema](c *gin.Context) (T, bool) {
+	var zero T
+	value, exists := c.Get(validatedKey(reflect.TypeOf(zero)))
+	if !exists {
+		return zero, false
+	}
+	schema, ok := value.(T)
+	return schema, ok
+}
+
+func validatedKey(t reflect.Type) string {
+	return "validated_schema:" + t.String()
+}
+
+type registeredValidator struct {
+	handler    gin.HandlerFunc // Keeps the closure alive so its address stays unique
+	schemaType reflect.Type
+}
+
+// Registry of Validator middlewares by closure address. Every Validator[T]
+// instantiation shares its code pointer, so the closure itself is the key.
+var (
+	validatorRegistryMu sync.RWMutex
+	validatorRegistry   = make(map[uintptr]registeredValidator)
+)
+
+func closureAddress(handler gin.HandlerFunc) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&handler))
+}
+
+func registerValidator(handler gin.HandlerFunc, schemaType reflect.Type) {
+	validatorRegistryMu.Lock()
+	defer validatorRegistryMu.Unlock()
+	validatorRegistry[closureAddress(handler)] = registeredValidator{handler: handler, schemaType: schemaType}
+}
+
+// IsValidatorMiddleware reports whether handler was created by Validator, returning its schema type
+func IsValidatorMiddleware(handler gin.HandlerFunc) (reflect.Type, bool) {
+	if handler == nil {
+		return nil, false
+	}
+
+	validatorRegistryMu.RLock()
+	defer validatorRegistryMu.RUnlock()
+	registered, exists := validatorRegistry[closureAddress(handler)]
+	return registered.schemaType, exists
+}
@@ -0,0 +1,233 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldView selects a subset of the JSON fields of T
+type FieldView[T any] struct {
+	name   string
+	fields []string
+}
+
+// View returns a view of T that keeps only the given JSON field names. Attach
+// it to a handler with WithView to document and return the reduced object
+// without defining a second struct. Unknown field names are ignored.
+func View[T any](fields ...string) *FieldView[T] {
+	return &FieldView[T]{fields: append([]string{}, fields...)}
+}
+
+// Named sets the component schema name of the view, by default the type name
+// followed by the selected fields (e.g. UserIdName)
+func (v *FieldView[T]) Named(name string) *FieldView[T] {
+	v.name = name
+	return v
+}
+
+// Fields returns the selected JSON field names
+func (v *FieldView[T]) Fields() []string {
+	return v.fields
+}
+
+// Apply returns the selected fields of value keyed by their JSON names
+func (v *FieldView[T]) Apply(value T) map[string]interface{} {
+	return v.projectStruct(reflect.ValueOf(value), globalNamingStrategy)
+}
+
+// ResponseView is implemented by FieldView for any T
+type ResponseView interface {
+	viewType() reflect.Type
+	viewSchema(schemas map[string]*JSONSchema, naming NamingStrategy) *JSONSchema
+	project(data interface{}, naming NamingStrategy) interface{}
+}
+
+func (v *FieldView[T]) viewType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (v *FieldView[T]) schemaName() string {
+	if v.name != "" {
+		return v.name
+	}
+
+	name := v.viewType().Name()
+	for _, field := range v.fields {
+		for _, part := range strings.FieldsFunc(field, func(r rune) bool { return r == '_' || r == '-' }) {
+			name += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return name
+}
+
+// viewSchema registers the reduced component schema under naming and returns
+// a reference to it
+func (v *FieldView[T]) viewSchema(schemas map[string]*JSONSchema, naming NamingStrategy) *JSONSchema {
+	name := v.schemaName() + namingVariant(naming)
+	ref := &JSONSchema{Ref: componentSchemaPrefix + name}
+	if _, exists := schemas[name]; exists {
+		return ref
+	}
+
+	full := generateJSONSchemaWithNaming(v.viewType(), schemas, naming)
+	if full.Ref != "" {
+		full = schemas[strings.TrimPrefix(full.Ref, componentSchemaPrefix)]
+	}
+	if full == nil {
+		return generateJSONSchemaWithNaming(v.viewType(), schemas, naming)
+	}
+
+	properties := make(map[string]*JSONSchema)
+	var required []string
+	for _, field := range v.fields {
+		if naming != nil {
+			field = naming(field)
+		}
+		property, ok := full.Properties[field]
+		if !ok {
+			continue
+		}
+		properties[field] = property
+		for _, name := range full.Required {
+			if name == field {
+				required = append(required, field)
+			}
+		}
+	}
+
+	schema := newJSONSchema("object", properties)
+	schema.Required = required
+	schema.propertyOrder = full.propertyOrder
+	schemas[name] = schema
+	return ref
+}
+
+// project applies the view to T, *T and slices of either, leaving other data
+// unchanged. The selected fields are keyed by their names under naming.
+func (v *FieldView[T]) project(data interface{}, naming NamingStrategy) interface{} {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return data
+		}
+		value = value.Elem()
+	}
+
+	if value.Type() == v.viewType() {
+		return v.projectStruct(value, naming)
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		element := value.Type().Elem()
+		if element.Kind() == reflect.Ptr {
+			element = element.Elem()
+		}
+		if element != v.viewType() {
+			return data
+		}
+
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = v.project(value.Index(i).Interface(), naming)
+		}
+		return items
+	}
+
+	return data
+}
+
+func (v *FieldView[T]) projectStruct(value reflect.Value, naming NamingStrategy) map[string]interface{} {
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	selected := make(map[string]bool, len(v.fields))
+	for _, field := range v.fields {
+		selected[field] = true
+		if naming != nil {
+			selected[naming(field)] = true
+		}
+	}
+
+	result := make(map[string]interface{}, len(v.fields))
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := namedFieldName(field, naming)
+		if selected[getJSONFieldName(field)] || selected[name] {
+			result[name] = value.Field(i).Interface()
+		}
+	}
+	return result
+}
+
+// WithView returns a copy of the handler whose T values in the response, or
+// slices of them, are reduced to the fields of view and documented as such
+func (t TypedHandlerFunc) WithView(view ResponseView) TypedHandlerFunc {
+	handler := t.handler
+	t.view = view
+	t.handler = func(c *gin.Context) {
+		c.Set("schema_view", view)
+		handler(c)
+	}
+	return t
+}
+
+// GetView returns the view registered with WithView, if any
+func (t TypedHandlerFunc) GetView() ResponseView {
+	return t.view
+}
+
+// applyView reduces response data to the view attached to the request
+func applyView(c *gin.Context, data interface{}) interface{} {
+	if view, ok := c.Get("schema_view"); ok {
+		if view, ok := view.(ResponseView); ok {
+			return view.project(data, contextNamingStrategy(c))
+		}
+	}
+	return data
+}
+
+// applyViewSchema documents the data of a success response through a view,
+// under the route's naming strategy when set
+func applyViewSchema(response Response, responseType reflect.Type, view ResponseView, naming NamingStrategy, schemas map[string]*JSONSchema) {
+	mediaType, ok := response.Content["application/json"]
+	if !ok || mediaType.Schema == nil || responseType == nil {
+		return
+	}
+
+	for responseType.Kind() == reflect.Ptr {
+		responseType = responseType.Elem()
+	}
+	if naming == nil {
+		naming = globalNamingStrategy
+	}
+
+	var data *JSONSchema
+	switch {
+	case responseType == view.viewType():
+		data = view.viewSchema(schemas, naming)
+	case responseType.Kind() == reflect.Slice || responseType.Kind() == reflect.Array:
+		element := responseType.Elem()
+		if element.Kind() == reflect.Ptr {
+			element = element.Elem()
+		}
+		if element != view.viewType() {
+			return
+		}
+		data = newJSONSchema("array", nil)
+		data.Items = view.viewSchema(schemas, naming)
+	default:
+		return
+	}
+
+	mediaType.Schema.Properties["data"] = data
+}